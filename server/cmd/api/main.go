@@ -1,9 +1,19 @@
 package main
 
 import (
+	"cards/internal/admin"
+	"cards/internal/apperrors"
+	"cards/internal/attachments"
 	"cards/internal/auth"
+	"cards/internal/boards"
 	"cards/internal/cards"
+	"cards/internal/comments"
 	"cards/internal/database"
+	"cards/internal/mail"
+	"cards/internal/storage"
+	"cards/internal/tags"
+	"cards/internal/workspaces"
+	"context"
 	"log"
 	"os"
 
@@ -26,6 +36,23 @@ func main() {
 	}
 	db := database.GetDB()
 
+	// Attachment storage and the cards service are shared by the background
+	// jobs and every package that works with cards
+	store, err := storage.NewBlobStoreFromEnv()
+	if err != nil {
+		log.Fatalf("Attachment storage setup failed: %v", err)
+	}
+	cardsService := cards.NewCardsServiceFromDB(db, store)
+
+	mailer, err := mail.NewMailerFromEnv()
+	if err != nil {
+		log.Fatalf("Mailer setup failed: %v", err)
+	}
+
+	// Background jobs: purge expired trash and archive stale done cards
+	go cards.NewTrashPurger(cardsService, cards.TrashRetention()).Run(context.Background())
+	go cards.NewDoneArchiver(cardsService, cards.ArchiveDoneAfter()).Run(context.Background())
+
 	// Initialize Gin
 	app := gin.Default()
 
@@ -34,9 +61,18 @@ func main() {
 	config.AllowAllOrigins = true // Update this for production!
 	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
 	app.Use(cors.New(config))
+	app.Use(apperrors.Middleware())
 	appGroupV1 := app.Group("/api/v1")
-	cards.RegisterCardsRoutes(appGroupV1, db)
-	auth.RegisterAuthRoutes(appGroupV1, db)
+	// Every route group authenticates through the same middleware
+	authMiddleware := auth.NewMiddleware(auth.NewAuthRepository(db))
+	cards.RegisterCardsRoutes(appGroupV1, cardsService, authMiddleware)
+	boards.RegisterBoardsRoutes(appGroupV1, db, authMiddleware)
+	tags.RegisterTagsRoutes(appGroupV1, db, authMiddleware)
+	comments.RegisterCommentsRoutes(appGroupV1, db, cardsService, authMiddleware)
+	attachments.RegisterAttachmentsRoutes(appGroupV1, db, cardsService, store, authMiddleware)
+	workspaces.RegisterWorkspacesRoutes(appGroupV1, db, mailer, authMiddleware)
+	auth.RegisterAuthRoutes(appGroupV1, db, mailer, authMiddleware)
+	admin.RegisterAdminRoutes(appGroupV1, db, mailer, authMiddleware)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"cards/internal/apperrors"
+)
+
+var errInvalidCursor = apperrors.Validation("invalid cursor")
+
+// usersCursor pages through users by offset. Admin listings are small and
+// filtered by search, so keyset paging is not worth its complexity here.
+type usersCursor struct {
+	Offset int `json:"o"`
+}
+
+func (c usersCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUsersCursor(value string) (*usersCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var cursor usersCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Offset < 0 {
+		return nil, errInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"time"
+
+	"cards/internal/models"
+)
+
+const (
+	defaultUsersPageSize = 50
+	maxUsersPageSize     = 200
+)
+
+type userStatus string
+
+const (
+	UserStatusActive   userStatus = "active"
+	UserStatusDisabled userStatus = "disabled"
+)
+
+type ListUsersQuery struct {
+	Q      string          `form:"q"`
+	Role   models.UserRole `form:"role" binding:"omitempty,oneof=user admin"`
+	Status userStatus      `form:"status" binding:"omitempty,oneof=active disabled"`
+	Cursor string          `form:"cursor"`
+	Limit  int             `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+type UpdateUserRoleDTO struct {
+	Role models.UserRole `json:"role" binding:"required,oneof=user admin"`
+}
+
+// UserDTO is a user as admins see them: account state, never credentials.
+type UserDTO struct {
+	ID                    string          `json:"id"`
+	Name                  string          `json:"name"`
+	Email                 string          `json:"email"`
+	Role                  models.UserRole `json:"role"`
+	EmailVerified         bool            `json:"email_verified"`
+	Disabled              bool            `json:"disabled"`
+	DisabledAt            *time.Time      `json:"disabled_at"`
+	PasswordResetRequired bool            `json:"password_reset_required"`
+	CreatedAt             time.Time       `json:"created_at"`
+}
+
+func newUserDTO(user models.User) UserDTO {
+	return UserDTO{
+		ID:                    user.ID.String(),
+		Name:                  user.Name,
+		Email:                 user.Email,
+		Role:                  user.Role,
+		EmailVerified:         user.EmailVerified(),
+		Disabled:              user.Disabled(),
+		DisabledAt:            user.DisabledAt,
+		PasswordResetRequired: user.PasswordResetRequired,
+		CreatedAt:             user.CreatedAt,
+	}
+}
+
+type UsersPage struct {
+	Users      []UserDTO
+	NextCursor *string
+	Total      int64
+}
+
+// CardUsageDTO counts the cards a user created, wherever they are now.
+type CardUsageDTO struct {
+	Active   int64 `json:"active"`
+	Archived int64 `json:"archived"`
+	Trashed  int64 `json:"trashed"`
+}
+
+// LLMUsageDTO counts a user's card generation requests.
+type LLMUsageDTO struct {
+	Requests       int64      `json:"requests"`
+	Failed         int64      `json:"failed"`
+	CardsGenerated int64      `json:"cards_generated"`
+	LastRequestAt  *time.Time `json:"last_request_at"`
+}
+
+type UserUsageDTO struct {
+	UserID string       `json:"user_id"`
+	Cards  CardUsageDTO `json:"cards"`
+	LLM    LLMUsageDTO  `json:"llm"`
+}
@@ -0,0 +1,155 @@
+package admin
+
+import (
+	"cards/internal/apperrors"
+	"cards/internal/params"
+	"cards/internal/types"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler interface {
+	ListUsers(c *gin.Context)
+	GetUser(c *gin.Context)
+	GetUsage(c *gin.Context)
+	DisableUser(c *gin.Context)
+	EnableUser(c *gin.Context)
+	ForcePasswordReset(c *gin.Context)
+	UpdateRole(c *gin.Context)
+}
+
+type adminHandler struct {
+	Service AdminService
+}
+
+func NewAdminHandler(service AdminService) AdminHandler {
+	return &adminHandler{Service: service}
+}
+
+func (h *adminHandler) ListUsers(c *gin.Context) {
+	var query ListUsersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apperrors.Respond(c, "Invalid query parameters", apperrors.Binding(err))
+		return
+	}
+
+	page, err := h.Service.ListUsers(query)
+	if err != nil {
+		apperrors.Respond(c, "Failed to list users", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewPaginatedApiResponse(
+		http.StatusOK,
+		"Users listed successfully",
+		page.Users,
+		types.PageMeta{NextCursor: page.NextCursor, Total: page.Total},
+	))
+}
+
+func (h *adminHandler) GetUser(c *gin.Context) {
+	userID, err := params.UUID(c, "userID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid user ID", err)
+		return
+	}
+
+	user, err := h.Service.GetUser(userID)
+	if err != nil {
+		apperrors.Respond(c, "User not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "User found", user, nil))
+}
+
+func (h *adminHandler) GetUsage(c *gin.Context) {
+	userID, err := params.UUID(c, "userID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid user ID", err)
+		return
+	}
+
+	usage, err := h.Service.Usage(userID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to retrieve usage", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Usage retrieved successfully", usage, nil))
+}
+
+func (h *adminHandler) DisableUser(c *gin.Context) {
+	h.setDisabled(c, true, "User disabled successfully")
+}
+
+func (h *adminHandler) EnableUser(c *gin.Context) {
+	h.setDisabled(c, false, "User enabled successfully")
+}
+
+func (h *adminHandler) setDisabled(c *gin.Context, disabled bool, message string) {
+	adminID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	userID, err := params.UUID(c, "userID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid user ID", err)
+		return
+	}
+
+	user, err := h.Service.SetDisabled(adminID, userID, disabled)
+	if err != nil {
+		apperrors.Respond(c, "Failed to update user", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, message, user, nil))
+}
+
+func (h *adminHandler) ForcePasswordReset(c *gin.Context) {
+	userID, err := params.UUID(c, "userID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid user ID", err)
+		return
+	}
+
+	user, err := h.Service.ForcePasswordReset(userID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to force password reset", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Password reset email sent; the user has been signed out", user, nil))
+}
+
+func (h *adminHandler) UpdateRole(c *gin.Context) {
+	adminID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	userID, err := params.UUID(c, "userID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid user ID", err)
+		return
+	}
+
+	var payload UpdateUserRoleDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	user, err := h.Service.SetRole(adminID, userID, payload.Role)
+	if err != nil {
+		apperrors.Respond(c, "Failed to update role", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Role updated successfully", user, nil))
+}
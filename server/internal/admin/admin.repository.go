@@ -0,0 +1,114 @@
+package admin
+
+import (
+	"strings"
+
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AdminRepository interface {
+	ListUsers(query UsersQuery) ([]models.User, int64, error)
+	FindUserByID(id uuid.UUID) (*models.User, error)
+	UpdateUserRole(id uuid.UUID, role models.UserRole) error
+	CountCards(userID uuid.UUID) (CardUsageDTO, error)
+	CountLLMUsage(userID uuid.UUID) (LLMUsageDTO, error)
+	PromoteAdmins(emails []string) (int64, error)
+}
+
+type UsersQuery struct {
+	Text   string
+	Role   models.UserRole
+	Status userStatus
+	Offset int
+	Limit  int
+}
+
+type adminRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminRepository(db *gorm.DB) AdminRepository {
+	return &adminRepository{db: db}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (r *adminRepository) ListUsers(query UsersQuery) ([]models.User, int64, error) {
+	db := r.db.Model(&models.User{})
+	if query.Text != "" {
+		pattern := "%" + likeEscaper.Replace(query.Text) + "%"
+		db = db.Where("(name ILIKE ? OR email ILIKE ?)", pattern, pattern)
+	}
+	if query.Role != "" {
+		db = db.Where("role = ?", query.Role)
+	}
+	switch query.Status {
+	case UserStatusActive:
+		db = db.Where("disabled_at IS NULL")
+	case UserStatusDisabled:
+		db = db.Where("disabled_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	if err := db.Order("created_at DESC, id").Offset(query.Offset).Limit(query.Limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *adminRepository) FindUserByID(id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUserRole uses UpdateColumn, since the User update hook would hash the
+// stored password hash again.
+func (r *adminRepository) UpdateUserRole(id uuid.UUID, role models.UserRole) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).UpdateColumn("role", role).Error
+}
+
+func (r *adminRepository) CountCards(userID uuid.UUID) (CardUsageDTO, error) {
+	var usage CardUsageDTO
+	err := r.db.Unscoped().Model(&models.Card{}).
+		Select(`COUNT(*) FILTER (WHERE deleted_at IS NULL AND archived_at IS NULL) AS active,
+			COUNT(*) FILTER (WHERE deleted_at IS NULL AND archived_at IS NOT NULL) AS archived,
+			COUNT(*) FILTER (WHERE deleted_at IS NOT NULL) AS trashed`).
+		Where("user_id = ?", userID).
+		Scan(&usage).Error
+	return usage, err
+}
+
+func (r *adminRepository) CountLLMUsage(userID uuid.UUID) (LLMUsageDTO, error) {
+	var usage LLMUsageDTO
+	err := r.db.Model(&models.LLMUsage{}).
+		Select(`COUNT(*) AS requests,
+			COUNT(*) FILTER (WHERE NOT succeeded) AS failed,
+			COALESCE(SUM(cards_generated), 0) AS cards_generated,
+			MAX(created_at) AS last_request_at`).
+		Where("user_id = ?", userID).
+		Scan(&usage).Error
+	return usage, err
+}
+
+// PromoteAdmins makes the users with the given emails admins, so a fresh
+// deployment has someone who can use the admin API.
+func (r *adminRepository) PromoteAdmins(emails []string) (int64, error) {
+	if len(emails) == 0 {
+		return 0, nil
+	}
+	result := r.db.Model(&models.User{}).
+		Where("email IN ? AND role <> ?", emails, models.UserRoleAdmin).
+		UpdateColumn("role", models.UserRoleAdmin)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"cards/internal/auth"
+	"cards/internal/mail"
+	"cards/internal/models"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func RegisterAdminRoutes(appGroup *gin.RouterGroup, db *gorm.DB, mailer mail.Mailer, authMiddleware auth.Middleware) {
+	repository := NewAdminRepository(db)
+	promoteAdminsFromEnv(repository)
+
+	authService := auth.NewAuthService(auth.NewAuthRepository(db), mailer)
+	registerAdminRoutes(appGroup, NewAdminHandler(NewAdminService(repository, authService)), authMiddleware)
+}
+
+// promoteAdminsFromEnv makes the users listed in ADMIN_EMAILS admins. It only
+// ever promotes; demoting is done through the API.
+func promoteAdminsFromEnv(repository AdminRepository) {
+	var emails []string
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			emails = append(emails, email)
+		}
+	}
+
+	promoted, err := repository.PromoteAdmins(emails)
+	if err != nil {
+		log.Printf("Promoting ADMIN_EMAILS failed: %v", err)
+	} else if promoted > 0 {
+		log.Printf("Promoted %d users from ADMIN_EMAILS to admin", promoted)
+	}
+}
+
+func registerAdminRoutes(appGroup *gin.RouterGroup, handler AdminHandler, authMiddleware auth.Middleware) {
+	adminGroup := appGroup.Group("/admin")
+	adminGroup.Use(authMiddleware.Authenticate(), authMiddleware.RequireRole(models.UserRoleAdmin))
+	adminGroup.GET("/users", handler.ListUsers)
+	adminGroup.GET("/users/:userID", handler.GetUser)
+	adminGroup.GET("/users/:userID/usage", handler.GetUsage)
+	adminGroup.POST("/users/:userID/disable", handler.DisableUser)
+	adminGroup.POST("/users/:userID/enable", handler.EnableUser)
+	adminGroup.POST("/users/:userID/force_password_reset", handler.ForcePasswordReset)
+	adminGroup.PATCH("/users/:userID/role", handler.UpdateRole)
+}
@@ -0,0 +1,142 @@
+package admin
+
+import (
+	"cards/internal/apperrors"
+	"cards/internal/auth"
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var ErrChangeOwnAccount = apperrors.Conflict("admins cannot disable or demote themselves")
+
+type AdminService interface {
+	ListUsers(query ListUsersQuery) (*UsersPage, error)
+	GetUser(userID uuid.UUID) (*UserDTO, error)
+	SetDisabled(adminID uuid.UUID, userID uuid.UUID, disabled bool) (*UserDTO, error)
+	ForcePasswordReset(userID uuid.UUID) (*UserDTO, error)
+	SetRole(adminID uuid.UUID, userID uuid.UUID, role models.UserRole) (*UserDTO, error)
+	Usage(userID uuid.UUID) (*UserUsageDTO, error)
+}
+
+// adminService reads users directly, but leaves anything that touches
+// sessions or credentials to the auth service.
+type adminService struct {
+	Repository AdminRepository
+	Auth       auth.AuthService
+}
+
+func NewAdminService(repository AdminRepository, authService auth.AuthService) AdminService {
+	return &adminService{Repository: repository, Auth: authService}
+}
+
+func (s *adminService) ListUsers(query ListUsersQuery) (*UsersPage, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultUsersPageSize
+	}
+	if limit > maxUsersPageSize {
+		limit = maxUsersPageSize
+	}
+
+	offset := 0
+	if query.Cursor != "" {
+		cursor, err := decodeUsersCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		offset = cursor.Offset
+	}
+
+	users, total, err := s.Repository.ListUsers(UsersQuery{
+		Text:   query.Q,
+		Role:   query.Role,
+		Status: query.Status,
+		Offset: offset,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &UsersPage{Users: make([]UserDTO, len(users)), Total: total}
+	for i, user := range users {
+		page.Users[i] = newUserDTO(user)
+	}
+	if next := offset + len(users); len(users) == limit && int64(next) < total {
+		cursor := usersCursor{Offset: next}.encode()
+		page.NextCursor = &cursor
+	}
+	return page, nil
+}
+
+func (s *adminService) GetUser(userID uuid.UUID) (*UserDTO, error) {
+	user, err := s.Repository.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := newUserDTO(*user)
+	return &dto, nil
+}
+
+// SetDisabled disables or re-enables a user. Admins cannot disable themselves,
+// so there is always someone left to undo it.
+func (s *adminService) SetDisabled(adminID uuid.UUID, userID uuid.UUID, disabled bool) (*UserDTO, error) {
+	if disabled && adminID == userID {
+		return nil, ErrChangeOwnAccount
+	}
+	if _, err := s.Repository.FindUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.Auth.SetUserDisabled(userID, disabled); err != nil {
+		return nil, err
+	}
+	return s.GetUser(userID)
+}
+
+func (s *adminService) ForcePasswordReset(userID uuid.UUID) (*UserDTO, error) {
+	if _, err := s.Repository.FindUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.Auth.ForcePasswordReset(userID); err != nil {
+		return nil, err
+	}
+	return s.GetUser(userID)
+}
+
+func (s *adminService) SetRole(adminID uuid.UUID, userID uuid.UUID, role models.UserRole) (*UserDTO, error) {
+	if !role.Valid() {
+		return nil, apperrors.Validation("invalid role")
+	}
+	if adminID == userID && role != models.UserRoleAdmin {
+		return nil, ErrChangeOwnAccount
+	}
+	if _, err := s.Repository.FindUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repository.UpdateUserRole(userID, role); err != nil {
+		return nil, err
+	}
+	return s.GetUser(userID)
+}
+
+func (s *adminService) Usage(userID uuid.UUID) (*UserUsageDTO, error) {
+	if _, err := s.Repository.FindUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	cards, err := s.Repository.CountCards(userID)
+	if err != nil {
+		return nil, err
+	}
+	llm, err := s.Repository.CountLLMUsage(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserUsageDTO{UserID: userID.String(), Cards: cards, LLM: llm}, nil
+}
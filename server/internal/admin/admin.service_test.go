@@ -0,0 +1,289 @@
+package admin
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"cards/internal/apperrors"
+	"cards/internal/auth"
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeAdminRepository struct {
+	users    map[uuid.UUID]*models.User
+	cards    CardUsageDTO
+	llm      LLMUsageDTO
+	promoted []string
+}
+
+func newFakeAdminRepository(users ...*models.User) *fakeAdminRepository {
+	repo := &fakeAdminRepository{users: map[uuid.UUID]*models.User{}}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func (r *fakeAdminRepository) ListUsers(query UsersQuery) ([]models.User, int64, error) {
+	var matched []models.User
+	for _, user := range r.users {
+		if query.Text != "" && !strings.Contains(user.Name+" "+user.Email, query.Text) {
+			continue
+		}
+		if query.Role != "" && user.Role != query.Role {
+			continue
+		}
+		if query.Status == UserStatusDisabled && !user.Disabled() || query.Status == UserStatusActive && user.Disabled() {
+			continue
+		}
+		matched = append(matched, *user)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Email < matched[j].Email })
+
+	total := int64(len(matched))
+	if query.Offset > len(matched) {
+		query.Offset = len(matched)
+	}
+	matched = matched[query.Offset:]
+	if len(matched) > query.Limit {
+		matched = matched[:query.Limit]
+	}
+	return matched, total, nil
+}
+
+func (r *fakeAdminRepository) FindUserByID(id uuid.UUID) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (r *fakeAdminRepository) UpdateUserRole(id uuid.UUID, role models.UserRole) error {
+	r.users[id].Role = role
+	return nil
+}
+
+func (r *fakeAdminRepository) CountCards(userID uuid.UUID) (CardUsageDTO, error) {
+	return r.cards, nil
+}
+
+func (r *fakeAdminRepository) CountLLMUsage(userID uuid.UUID) (LLMUsageDTO, error) {
+	return r.llm, nil
+}
+
+func (r *fakeAdminRepository) PromoteAdmins(emails []string) (int64, error) {
+	r.promoted = append(r.promoted, emails...)
+	return int64(len(emails)), nil
+}
+
+// fakeAuthService applies account changes to the fake repository's users, as
+// the real one does to the database.
+type fakeAuthService struct {
+	auth.AuthService
+	repo   *fakeAdminRepository
+	resets []uuid.UUID
+}
+
+func (s *fakeAuthService) SetUserDisabled(userID uuid.UUID, disabled bool) error {
+	var disabledAt *time.Time
+	if disabled {
+		now := time.Now()
+		disabledAt = &now
+	}
+	s.repo.users[userID].DisabledAt = disabledAt
+	return nil
+}
+
+func (s *fakeAuthService) ForcePasswordReset(userID uuid.UUID) error {
+	s.resets = append(s.resets, userID)
+	s.repo.users[userID].PasswordResetRequired = true
+	return nil
+}
+
+func newUser(email string, role models.UserRole) *models.User {
+	name, _, _ := strings.Cut(email, "@")
+	return &models.User{Base: models.Base{ID: uuid.New()}, Name: name, Email: email, Role: role}
+}
+
+func TestAdminService_ListUsers(t *testing.T) {
+	disabledAt := time.Now()
+	ana := newUser("ana@example.com", models.UserRoleAdmin)
+	bia := newUser("bia@example.com", models.UserRoleUser)
+	caio := newUser("caio@example.com", models.UserRoleUser)
+	caio.DisabledAt = &disabledAt
+	svc := NewAdminService(newFakeAdminRepository(ana, bia, caio), &fakeAuthService{})
+
+	t.Run("pages through every user", func(t *testing.T) {
+		first, err := svc.ListUsers(ListUsersQuery{Limit: 2})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(first.Users) != 2 || first.Total != 3 || first.NextCursor == nil {
+			t.Fatalf("expected a first page of 2 of 3 users, got %+v", first)
+		}
+
+		second, err := svc.ListUsers(ListUsersQuery{Limit: 2, Cursor: *first.NextCursor})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(second.Users) != 1 || second.Users[0].Email != caio.Email || second.NextCursor != nil {
+			t.Fatalf("expected the last user and no further page, got %+v", second)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			query ListUsersQuery
+			want  []string
+		}{
+			{"by text", ListUsersQuery{Q: "bia"}, []string{bia.Email}},
+			{"by role", ListUsersQuery{Role: models.UserRoleAdmin}, []string{ana.Email}},
+			{"disabled", ListUsersQuery{Status: UserStatusDisabled}, []string{caio.Email}},
+			{"active", ListUsersQuery{Status: UserStatusActive}, []string{ana.Email, bia.Email}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page, err := svc.ListUsers(tt.query)
+				if err != nil {
+					t.Fatalf("expected nil error, got %v", err)
+				}
+				var got []string
+				for _, user := range page.Users {
+					got = append(got, user.Email)
+				}
+				if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			})
+		}
+	})
+
+	t.Run("rejects a bad cursor", func(t *testing.T) {
+		if _, err := svc.ListUsers(ListUsersQuery{Cursor: "not a cursor"}); !errors.Is(err, apperrors.ErrValidation) {
+			t.Fatalf("expected a validation error, got %v", err)
+		}
+	})
+}
+
+func TestAdminService_AccountActions(t *testing.T) {
+	newService := func() (*models.User, *models.User, *fakeAuthService, AdminService) {
+		admin := newUser("admin@example.com", models.UserRoleAdmin)
+		user := newUser("user@example.com", models.UserRoleUser)
+		repo := newFakeAdminRepository(admin, user)
+		authService := &fakeAuthService{repo: repo}
+		return admin, user, authService, NewAdminService(repo, authService)
+	}
+
+	t.Run("disables and enables a user", func(t *testing.T) {
+		admin, user, _, svc := newService()
+
+		disabled, err := svc.SetDisabled(admin.ID, user.ID, true)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !disabled.Disabled || disabled.DisabledAt == nil {
+			t.Fatalf("expected the user disabled, got %+v", disabled)
+		}
+
+		enabled, err := svc.SetDisabled(admin.ID, user.ID, false)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if enabled.Disabled {
+			t.Fatalf("expected the user enabled, got %+v", enabled)
+		}
+	})
+
+	t.Run("admins cannot lock themselves out", func(t *testing.T) {
+		admin, _, _, svc := newService()
+
+		if _, err := svc.SetDisabled(admin.ID, admin.ID, true); !errors.Is(err, ErrChangeOwnAccount) {
+			t.Fatalf("expected %v disabling themselves, got %v", ErrChangeOwnAccount, err)
+		}
+		if _, err := svc.SetRole(admin.ID, admin.ID, models.UserRoleUser); !errors.Is(err, ErrChangeOwnAccount) {
+			t.Fatalf("expected %v demoting themselves, got %v", ErrChangeOwnAccount, err)
+		}
+	})
+
+	t.Run("changes roles", func(t *testing.T) {
+		admin, user, _, svc := newService()
+
+		promoted, err := svc.SetRole(admin.ID, user.ID, models.UserRoleAdmin)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if promoted.Role != models.UserRoleAdmin {
+			t.Fatalf("expected the user promoted, got %+v", promoted)
+		}
+		if _, err := svc.SetRole(admin.ID, user.ID, "owner"); !errors.Is(err, apperrors.ErrValidation) {
+			t.Fatalf("expected a validation error for an unknown role, got %v", err)
+		}
+	})
+
+	t.Run("forces a password reset", func(t *testing.T) {
+		_, user, authService, svc := newService()
+
+		res, err := svc.ForcePasswordReset(user.ID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !res.PasswordResetRequired || len(authService.resets) != 1 || authService.resets[0] != user.ID {
+			t.Fatalf("expected a reset forced for the user, got %+v", res)
+		}
+	})
+
+	t.Run("unknown users are not found", func(t *testing.T) {
+		admin, _, authService, svc := newService()
+		unknown := uuid.New()
+
+		if _, err := svc.SetDisabled(admin.ID, unknown, true); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expected not found disabling, got %v", err)
+		}
+		if _, err := svc.ForcePasswordReset(unknown); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expected not found forcing a reset, got %v", err)
+		}
+		if len(authService.resets) != 0 {
+			t.Fatalf("expected no reset forced")
+		}
+	})
+}
+
+func TestAdminService_Usage(t *testing.T) {
+	user := newUser("user@example.com", models.UserRoleUser)
+	lastRequestAt := time.Now()
+	repo := newFakeAdminRepository(user)
+	repo.cards = CardUsageDTO{Active: 4, Archived: 2, Trashed: 1}
+	repo.llm = LLMUsageDTO{Requests: 3, Failed: 1, CardsGenerated: 12, LastRequestAt: &lastRequestAt}
+	svc := NewAdminService(repo, &fakeAuthService{repo: repo})
+
+	usage, err := svc.Usage(user.ID)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if usage.UserID != user.ID.String() || usage.Cards != repo.cards || usage.LLM != repo.llm {
+		t.Fatalf("expected the user's counts, got %+v", usage)
+	}
+
+	if _, err := svc.Usage(uuid.New()); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected not found for an unknown user, got %v", err)
+	}
+}
+
+func TestPromoteAdminsFromEnv(t *testing.T) {
+	t.Setenv("ADMIN_EMAILS", " ana@example.com, ,bia@example.com ")
+	repo := newFakeAdminRepository()
+
+	promoteAdminsFromEnv(repo)
+
+	if strings.Join(repo.promoted, ",") != "ana@example.com,bia@example.com" {
+		t.Fatalf("expected the listed emails promoted, got %v", repo.promoted)
+	}
+}
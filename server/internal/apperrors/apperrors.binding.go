@@ -0,0 +1,166 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is a problem with one field of a request, named the way the
+// client sent it (e.g. "title" or "[1].board_id") so forms can map it onto
+// their inputs.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Validation errors name fields by their json or form tag instead of the Go
+// struct field.
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(requestFieldName)
+	}
+}
+
+func requestFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Binding wraps an error from gin's ShouldBind* as a validation error,
+// translating validator and JSON type failures into field errors.
+func Binding(err error) error {
+	return &Error{Kind: ErrValidation, Err: err, Fields: fieldErrors(err, "")}
+}
+
+// BindJSONList binds a JSON array body like ShouldBindJSON, but validates the
+// items one at a time: gin validates top-level arrays itself and loses the
+// index of the item at fault.
+func BindJSONList[T any](c *gin.Context, items *[]T) error {
+	if c.Request.Body == nil {
+		return Binding(errors.New("invalid request"))
+	}
+	if err := json.NewDecoder(c.Request.Body).Decode(items); err != nil {
+		return Binding(err)
+	}
+
+	var errs []error
+	var fields []FieldError
+	for i := range *items {
+		if err := binding.Validator.ValidateStruct(&(*items)[i]); err != nil {
+			errs = append(errs, fmt.Errorf("[%d]: %w", i, err))
+			fields = append(fields, fieldErrors(err, "["+strconv.Itoa(i)+"].")...)
+		}
+	}
+	if len(errs) > 0 {
+		return &Error{Kind: ErrValidation, Err: errors.Join(errs...), Fields: fields}
+	}
+	return nil
+}
+
+func fieldErrors(err error, prefix string) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields = append(fields, FieldError{
+				Field:   prefix + fieldPath(fieldErr.Namespace()),
+				Code:    fieldErr.Tag(),
+				Message: ruleMessage(fieldErr),
+			})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   prefix + typeErr.Field,
+			Code:    "type",
+			Message: "must be of type " + jsonTypeName(typeErr.Type),
+		}}
+	}
+
+	return nil
+}
+
+// fieldPath drops the struct name the validator puts in front of every
+// namespace ("CreateCardDTO.checklist[0]" becomes "checklist[0]").
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+func ruleMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	unit := ""
+	if fieldErr.Kind() == reflect.String {
+		unit = " characters"
+	} else if kind := fieldErr.Kind(); kind == reflect.Slice || kind == reflect.Map {
+		unit = " items"
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "min":
+		return "must be at least " + param + unit
+	case "max":
+		return "must be at most " + param + unit
+	case "len":
+		return "must be exactly " + param + unit
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be at least " + param
+	case "lt":
+		return "must be less than " + param
+	case "lte":
+		return "must be at most " + param
+	default:
+		return fmt.Sprintf("failed the %q rule", fieldErr.Tag())
+	}
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
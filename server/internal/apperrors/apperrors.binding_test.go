@@ -0,0 +1,86 @@
+package apperrors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type bindingTestItem struct {
+	Text string `json:"text" binding:"required,max=5"`
+}
+
+type bindingTestDTO struct {
+	Title       string            `json:"title" binding:"required"`
+	WorkspaceID string            `json:"workspace_id" binding:"omitempty,uuid"`
+	Status      string            `json:"status" binding:"omitempty,oneof=done undone"`
+	Checklist   []bindingTestItem `json:"checklist" binding:"dive"`
+}
+
+func TestBinding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(body string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c
+	}
+	bind := func(body string, dto any) error {
+		return Binding(newContext(body).ShouldBindJSON(dto))
+	}
+	fieldsOf := func(err error) []FieldError {
+		return err.(*Error).Fields
+	}
+
+	t.Run("names fields as the client sent them", func(t *testing.T) {
+		err := bind(`{"workspace_id":"nope","status":"later","checklist":[{"text":"ok"},{"text":"too long"}]}`, &bindingTestDTO{})
+
+		want := []FieldError{
+			{Field: "title", Code: "required", Message: "is required"},
+			{Field: "workspace_id", Code: "uuid", Message: "must be a valid UUID"},
+			{Field: "status", Code: "oneof", Message: "must be one of: done, undone"},
+			{Field: "checklist[1].text", Code: "max", Message: "must be at most 5 characters"},
+		}
+		if got := fieldsOf(err); !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %+v, got %+v", want, got)
+		}
+		if Status(err) != http.StatusBadRequest {
+			t.Fatalf("expected status %d, got %d", http.StatusBadRequest, Status(err))
+		}
+	})
+
+	t.Run("indexes items of a list payload", func(t *testing.T) {
+		var items []bindingTestDTO
+		err := BindJSONList(newContext(`[{"title":"first"},{"title":""}]`), &items)
+
+		want := []FieldError{{Field: "[1].title", Code: "required", Message: "is required"}}
+		if got := fieldsOf(err); !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("reports fields of the wrong type", func(t *testing.T) {
+		err := bind(`{"title":42}`, &bindingTestDTO{})
+
+		want := []FieldError{{Field: "title", Code: "type", Message: "must be of type string"}}
+		if got := fieldsOf(err); !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("keeps malformed JSON without fields", func(t *testing.T) {
+		err := bind(`{"title":`, &bindingTestDTO{})
+
+		if fields := fieldsOf(err); len(fields) != 0 {
+			t.Fatalf("expected no field errors, got %+v", fields)
+		}
+		if Status(err) != http.StatusBadRequest {
+			t.Fatalf("expected status %d, got %d", http.StatusBadRequest, Status(err))
+		}
+	})
+}
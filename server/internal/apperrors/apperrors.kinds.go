@@ -0,0 +1,108 @@
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of failure a service can report. Handlers do not pick status codes
+// for them; Middleware maps the kind to one.
+var (
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("too many requests")
+	ErrUpstream     = errors.New("upstream service failed")
+	ErrUnavailable  = errors.New("service unavailable")
+)
+
+// Error is a failure of a known kind, with a message meant for the client and
+// optionally the error that caused it and the request fields at fault.
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+	Fields  []FieldError
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+func Validation(message string) error {
+	return &Error{Kind: ErrValidation, Message: message}
+}
+
+func Unauthorized(message string) error {
+	return &Error{Kind: ErrUnauthorized, Message: message}
+}
+
+func Forbidden(message string) error {
+	return &Error{Kind: ErrForbidden, Message: message}
+}
+
+func NotFound(message string) error {
+	return &Error{Kind: ErrNotFound, Message: message}
+}
+
+func Conflict(message string) error {
+	return &Error{Kind: ErrConflict, Message: message}
+}
+
+func RateLimited(message string) error {
+	return &Error{Kind: ErrRateLimited, Message: message}
+}
+
+// Upstream reports a service we depend on answering with an error or with
+// something we cannot use.
+func Upstream(message string, err error) error {
+	return &Error{Kind: ErrUpstream, Message: message, Err: err}
+}
+
+// Unavailable reports a service we depend on being unreachable, overloaded
+// or not configured.
+func Unavailable(message string, err error) error {
+	return &Error{Kind: ErrUnavailable, Message: message, Err: err}
+}
+
+// Status returns the HTTP status for err. Missing database records count as
+// not found and duplicate keys as conflicts; anything unknown is a 500.
+func Status(err error) int {
+	switch {
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict), errors.Is(err, gorm.ErrDuplicatedKey):
+		return http.StatusConflict
+	case errors.Is(err, ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrUpstream):
+		return http.StatusBadGateway
+	case errors.Is(err, ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
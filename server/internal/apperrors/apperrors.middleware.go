@@ -0,0 +1,54 @@
+package apperrors
+
+import (
+	"net/http"
+
+	"cards/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware renders the last error a handler recorded with Respond, with
+// the status matching the error's kind.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Written() || len(c.Errors) == 0 {
+			return
+		}
+
+		last := c.Errors.Last()
+		message, _ := last.Meta.(string)
+		render(c, Status(last.Err), message, last.Err)
+	}
+}
+
+// Respond records a failed request for Middleware to render. The message
+// says what failed, the error why.
+func Respond(c *gin.Context, message string, err error) {
+	c.Error(err).SetMeta(message)
+}
+
+// Abort stops the chain and renders the error right away, at the given
+// status. It is meant for middleware that may run without Middleware
+// mounted, and for statuses no error kind maps to.
+func Abort(c *gin.Context, status int, message string, err error) {
+	c.Abort()
+	render(c, status, message, err)
+}
+
+// render writes the error as a Problem if the client asked for one and as an
+// ApiResponse otherwise.
+func render(c *gin.Context, status int, message string, err error) {
+	if wantsProblem(c) {
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(status, NewProblem(status, message, err, c.Request.URL.Path))
+		return
+	}
+
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	c.JSON(status, types.NewApiResponse(status, message, nil, err.Error()))
+}
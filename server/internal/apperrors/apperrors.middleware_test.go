@@ -0,0 +1,126 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"validation", Validation("title is required"), http.StatusBadRequest},
+		{"unauthorized", Unauthorized("invalid credentials"), http.StatusUnauthorized},
+		{"forbidden sentinel", ErrForbidden, http.StatusForbidden},
+		{"not found", NotFound("invalid invitation"), http.StatusNotFound},
+		{"missing record", gorm.ErrRecordNotFound, http.StatusNotFound},
+		{"wrapped missing record", fmt.Errorf("load card: %w", gorm.ErrRecordNotFound), http.StatusNotFound},
+		{"conflict", Conflict("tag already exists"), http.StatusConflict},
+		{"duplicate key", gorm.ErrDuplicatedKey, http.StatusConflict},
+		{"rate limited", RateLimited("too many reset requests"), http.StatusTooManyRequests},
+		{"upstream", Upstream("openrouter error: 500", nil), http.StatusBadGateway},
+		{"unavailable", Unavailable("openrouter is unreachable", errors.New("dial tcp")), http.StatusServiceUnavailable},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Status(tt.err); got != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestError_KeepsCause(t *testing.T) {
+	cause := errors.New("dial tcp")
+	err := Unavailable("openrouter is unreachable", cause)
+
+	if !errors.Is(err, ErrUnavailable) || !errors.Is(err, cause) {
+		t.Fatalf("expected error to match both its kind and its cause")
+	}
+	if err.Error() != "openrouter is unreachable: dial tcp" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/failed", func(c *gin.Context) {
+		Respond(c, "Failed to create tag", Conflict("tag already exists"))
+	})
+	router.GET("/written", func(c *gin.Context) {
+		c.Error(errors.New("logged only"))
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	t.Run("renders the recorded error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/failed", nil))
+
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected status %d, got %d", http.StatusConflict, w.Code)
+		}
+		var body struct {
+			Message string `json:"message"`
+			Error   string `json:"error"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if body.Message != "Failed to create tag" || body.Error != "tag already exists" {
+			t.Fatalf("unexpected response %s", w.Body.String())
+		}
+	})
+
+	t.Run("renders a problem when asked for one", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/failed", nil)
+		req.Header.Set("Accept", ProblemContentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected status %d, got %d", http.StatusConflict, w.Code)
+		}
+		if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, ProblemContentType) {
+			t.Fatalf("expected content type %q, got %q", ProblemContentType, got)
+		}
+		var problem Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		want := Problem{
+			Type:     "/problems/conflict",
+			Title:    "Conflict",
+			Status:   http.StatusConflict,
+			Detail:   "Failed to create tag: tag already exists",
+			Instance: "/failed",
+		}
+		if !reflect.DeepEqual(problem, want) {
+			t.Fatalf("expected %+v, got %+v", want, problem)
+		}
+	})
+
+	t.Run("leaves written responses alone", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/written", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+}
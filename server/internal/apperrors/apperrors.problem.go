@@ -0,0 +1,71 @@
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the RFC 7807 media type. Clients that list it in
+// their Accept header get errors as a Problem instead of an ApiResponse.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+var problemTypes = map[int]string{
+	http.StatusBadRequest:            "/problems/validation",
+	http.StatusUnauthorized:          "/problems/unauthorized",
+	http.StatusForbidden:             "/problems/forbidden",
+	http.StatusNotFound:              "/problems/not-found",
+	http.StatusConflict:              "/problems/conflict",
+	http.StatusTooManyRequests:       "/problems/rate-limited",
+	http.StatusBadGateway:            "/problems/upstream",
+	http.StatusServiceUnavailable:    "/problems/unavailable",
+	http.StatusRequestEntityTooLarge: "/problems/too-large",
+	http.StatusUnsupportedMediaType:  "/problems/unsupported-media-type",
+}
+
+// NewProblem describes err as a problem at the given status. The message
+// says what failed and leads the detail, the error says why.
+func NewProblem(status int, message string, err error, instance string) Problem {
+	problemType, ok := problemTypes[status]
+	if !ok {
+		problemType = "about:blank"
+	}
+
+	detail := message
+	if err != nil {
+		if detail != "" {
+			detail += ": "
+		}
+		detail += err.Error()
+	}
+
+	problem := Problem{
+		Type:     problemType,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: instance,
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		problem.Errors = appErr.Fields
+	}
+	return problem
+}
+
+// wantsProblem reports whether the client opted into problem responses.
+func wantsProblem(c *gin.Context) bool {
+	return c.NegotiateFormat(gin.MIMEJSON, ProblemContentType) == ProblemContentType
+}
@@ -0,0 +1,9 @@
+package attachments
+
+import "io"
+
+type UploadAttachmentDTO struct {
+	FileName string
+	Size     int64
+	File     io.Reader
+}
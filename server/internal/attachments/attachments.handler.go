@@ -0,0 +1,165 @@
+package attachments
+
+import (
+	"cards/internal/apperrors"
+	"cards/internal/params"
+	"cards/internal/types"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead leaves room for boundaries and part headers on top of the
+// file itself when capping the request body.
+const multipartOverhead = 1 << 20
+
+type AttachmentsHandler interface {
+	List(c *gin.Context)
+	Upload(c *gin.Context)
+	Download(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type attachmentsHandler struct {
+	Service AttachmentsService
+}
+
+func NewAttachmentsHandler(service AttachmentsService) AttachmentsHandler {
+	return &attachmentsHandler{Service: service}
+}
+
+func (h *attachmentsHandler) List(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	attachments, err := h.Service.List(userID, cardID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to list attachments", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Attachments listed successfully", attachments, nil))
+}
+
+func (h *attachmentsHandler) Upload(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Service.MaxSize()+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			apperrors.Abort(c, http.StatusRequestEntityTooLarge, "Attachment is too large", err)
+			return
+		}
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.Service.Upload(userID, cardID, UploadAttachmentDTO{
+		FileName: header.Filename,
+		Size:     header.Size,
+		File:     file,
+	})
+	switch {
+	case errors.Is(err, ErrAttachmentTooLarge):
+		apperrors.Abort(c, http.StatusRequestEntityTooLarge, "Attachment is too large", err)
+		return
+	case errors.Is(err, ErrUnsupportedFileType):
+		apperrors.Abort(c, http.StatusUnsupportedMediaType, "Unsupported file type", err)
+		return
+	case err != nil:
+		apperrors.Respond(c, "Failed to upload attachment", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewApiResponse(http.StatusCreated, "Attachment uploaded successfully", attachment, nil))
+}
+
+func (h *attachmentsHandler) Download(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	attachmentID, err := params.UUID(c, "attachmentID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid attachment ID", err)
+		return
+	}
+
+	attachment, body, err := h.Service.Download(userID, cardID, attachmentID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to download attachment", err)
+		return
+	}
+	defer body.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (h *attachmentsHandler) Delete(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	attachmentID, err := params.UUID(c, "attachmentID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid attachment ID", err)
+		return
+	}
+
+	attachment, err := h.Service.Delete(userID, cardID, attachmentID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to delete attachment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Attachment deleted successfully", attachment, nil))
+}
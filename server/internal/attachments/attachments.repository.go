@@ -0,0 +1,47 @@
+package attachments
+
+import (
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AttachmentsRepository interface {
+	FindByID(uuid.UUID) (models.Attachment, error)
+	ListByCardID(uuid.UUID) ([]models.Attachment, error)
+	Create(*models.Attachment) error
+	Delete(uuid.UUID) error
+}
+
+type attachmentsRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentsRepository(db *gorm.DB) AttachmentsRepository {
+	return &attachmentsRepository{db: db}
+}
+
+func (r *attachmentsRepository) FindByID(id uuid.UUID) (models.Attachment, error) {
+	var attachment models.Attachment
+	if err := r.db.Where("id = ?", id).First(&attachment).Error; err != nil {
+		return models.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+func (r *attachmentsRepository) ListByCardID(cardID uuid.UUID) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	if err := r.db.Where("card_id = ?", cardID).Order("created_at, id").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *attachmentsRepository) Create(attachment *models.Attachment) error {
+	return r.db.Create(attachment).Error
+}
+
+func (r *attachmentsRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Attachment{Base: models.Base{ID: id}}).Error
+}
@@ -0,0 +1,23 @@
+package attachments
+
+import (
+	"cards/internal/auth"
+	"cards/internal/cards"
+	"cards/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func RegisterAttachmentsRoutes(appGroup *gin.RouterGroup, db *gorm.DB, cardsService cards.CardsService, store storage.BlobStore, authMiddleware auth.Middleware) {
+	repository := NewAttachmentsRepository(db)
+	service := NewAttachmentsService(repository, cardsService, store, MaxAttachmentSize())
+	handler := NewAttachmentsHandler(service)
+
+	attachmentsGroup := appGroup.Group("/cards/:cardID/attachments")
+	attachmentsGroup.Use(authMiddleware.Authenticate())
+	attachmentsGroup.GET("", handler.List)
+	attachmentsGroup.POST("", handler.Upload)
+	attachmentsGroup.GET("/:attachmentID", handler.Download)
+	attachmentsGroup.DELETE("/:attachmentID", handler.Delete)
+}
@@ -0,0 +1,195 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"cards/internal/authz"
+	"cards/internal/cards"
+	"cards/internal/models"
+	"cards/internal/storage"
+)
+
+const (
+	defaultMaxAttachmentSize = 10 << 20
+	maxFileNameLength        = 255
+	sniffLength              = 512
+)
+
+var (
+	ErrAttachmentTooLarge  = errors.New("attachment is too large")
+	ErrUnsupportedFileType = errors.New("unsupported file type")
+)
+
+// allowedContentTypes lists what http.DetectContentType may report for the
+// screenshots, PDFs and notes people attach to cards.
+var allowedContentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
+
+type AttachmentsService interface {
+	List(userID uuid.UUID, cardID uuid.UUID) ([]models.Attachment, error)
+	Upload(userID uuid.UUID, cardID uuid.UUID, dto UploadAttachmentDTO) (*models.Attachment, error)
+	Download(userID uuid.UUID, cardID uuid.UUID, attachmentID uuid.UUID) (*models.Attachment, io.ReadCloser, error)
+	Delete(userID uuid.UUID, cardID uuid.UUID, attachmentID uuid.UUID) (*models.Attachment, error)
+	MaxSize() int64
+}
+
+type attachmentsService struct {
+	Repository AttachmentsRepository
+	Cards      cards.CardsService
+	Store      storage.BlobStore
+	maxSize    int64
+}
+
+func NewAttachmentsService(repository AttachmentsRepository, cardsService cards.CardsService, store storage.BlobStore, maxSize int64) AttachmentsService {
+	return &attachmentsService{Repository: repository, Cards: cardsService, Store: store, maxSize: maxSize}
+}
+
+// MaxAttachmentSize reads ATTACHMENT_MAX_BYTES, falling back to 10 MiB.
+func MaxAttachmentSize() int64 {
+	size, err := strconv.ParseInt(os.Getenv("ATTACHMENT_MAX_BYTES"), 10, 64)
+	if err != nil || size <= 0 {
+		return defaultMaxAttachmentSize
+	}
+	return size
+}
+
+func (s *attachmentsService) MaxSize() int64 {
+	return s.maxSize
+}
+
+func (s *attachmentsService) List(userID uuid.UUID, cardID uuid.UUID) ([]models.Attachment, error) {
+	if _, err := s.Cards.GetAuthorized(userID, cardID, authz.Read); err != nil {
+		return nil, err
+	}
+
+	return s.Repository.ListByCardID(cardID)
+}
+
+func (s *attachmentsService) Upload(userID uuid.UUID, cardID uuid.UUID, dto UploadAttachmentDTO) (*models.Attachment, error) {
+	if _, err := s.Cards.GetAuthorized(userID, cardID, authz.Write); err != nil {
+		return nil, err
+	}
+
+	if dto.Size > s.maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(dto.File, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !allowedContentTypes[mediaType] {
+		return nil, ErrUnsupportedFileType
+	}
+
+	attachment := models.Attachment{
+		Base:        models.Base{ID: uuid.New()},
+		FileName:    cleanFileName(dto.FileName),
+		ContentType: contentType,
+		Size:        dto.Size,
+		CardID:      cardID,
+		UserID:      userID,
+	}
+	attachment.StorageKey = fmt.Sprintf("cards/%s/%s", cardID, attachment.ID)
+
+	ctx := context.Background()
+	body := io.MultiReader(bytes.NewReader(head), dto.File)
+	if err := s.Store.Put(ctx, attachment.StorageKey, body, attachment.Size, attachment.ContentType); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repository.Create(&attachment); err != nil {
+		if deleteErr := s.Store.Delete(ctx, attachment.StorageKey); deleteErr != nil {
+			log.Printf("failed to remove orphaned attachment %s: %v", attachment.StorageKey, deleteErr)
+		}
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+func (s *attachmentsService) Download(userID uuid.UUID, cardID uuid.UUID, attachmentID uuid.UUID) (*models.Attachment, io.ReadCloser, error) {
+	attachment, err := s.getForCard(userID, cardID, attachmentID, authz.Read)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := s.Store.Get(context.Background(), attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, body, nil
+}
+
+func (s *attachmentsService) Delete(userID uuid.UUID, cardID uuid.UUID, attachmentID uuid.UUID) (*models.Attachment, error) {
+	attachment, err := s.getForCard(userID, cardID, attachmentID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Repository.Delete(attachment.ID); err != nil {
+		return nil, err
+	}
+
+	// The row is already gone, so a blob that fails to delete is only wasted
+	// space and should not fail the request.
+	if err := s.Store.Delete(context.Background(), attachment.StorageKey); err != nil {
+		log.Printf("failed to remove attachment blob %s: %v", attachment.StorageKey, err)
+	}
+
+	return attachment, nil
+}
+
+func (s *attachmentsService) getForCard(userID uuid.UUID, cardID uuid.UUID, attachmentID uuid.UUID, action authz.Action) (*models.Attachment, error) {
+	if _, err := s.Cards.GetAuthorized(userID, cardID, action); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.Repository.FindByID(attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attachment.CardID != cardID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &attachment, nil
+}
+
+func cleanFileName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > maxFileNameLength {
+		name = strings.ToValidUTF8(name[:maxFileNameLength], "")
+	}
+	return name
+}
@@ -0,0 +1,239 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"cards/internal/apperrors"
+	"cards/internal/authz"
+	"cards/internal/cards"
+	"cards/internal/models"
+	"cards/internal/storage"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+type fakeAttachmentsRepository struct {
+	attachments map[uuid.UUID]models.Attachment
+	createErr   error
+	deletedID   uuid.UUID
+}
+
+func newFakeAttachmentsRepository(attachments ...models.Attachment) *fakeAttachmentsRepository {
+	r := &fakeAttachmentsRepository{attachments: map[uuid.UUID]models.Attachment{}}
+	for _, attachment := range attachments {
+		r.attachments[attachment.ID] = attachment
+	}
+	return r
+}
+
+func (r *fakeAttachmentsRepository) FindByID(id uuid.UUID) (models.Attachment, error) {
+	attachment, ok := r.attachments[id]
+	if !ok {
+		return models.Attachment{}, gorm.ErrRecordNotFound
+	}
+	return attachment, nil
+}
+
+func (r *fakeAttachmentsRepository) ListByCardID(cardID uuid.UUID) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	for _, attachment := range r.attachments {
+		if attachment.CardID == cardID {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments, nil
+}
+
+func (r *fakeAttachmentsRepository) Create(attachment *models.Attachment) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	r.attachments[attachment.ID] = *attachment
+	return nil
+}
+
+func (r *fakeAttachmentsRepository) Delete(id uuid.UUID) error {
+	r.deletedID = id
+	delete(r.attachments, id)
+	return nil
+}
+
+type memoryBlobStore struct {
+	blobs map[string][]byte
+}
+
+func newMemoryBlobStore() *memoryBlobStore {
+	return &memoryBlobStore{blobs: map[string][]byte{}}
+}
+
+func (s *memoryBlobStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.blobs[key] = data
+	return nil
+}
+
+func (s *memoryBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, storage.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memoryBlobStore) Delete(ctx context.Context, key string) error {
+	delete(s.blobs, key)
+	return nil
+}
+
+type fakeCardsService struct {
+	cards.CardsService
+	card  models.Card
+	roles map[uuid.UUID]models.Role
+}
+
+func (f *fakeCardsService) GetAuthorized(userID uuid.UUID, cardID uuid.UUID, action authz.Action) (*models.Card, error) {
+	if cardID != f.card.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	role := f.roles[userID]
+	if userID == f.card.UserID {
+		role = models.RoleOwner
+	}
+	subject := authz.NewSubject(userID, []models.Membership{{WorkspaceID: *f.card.WorkspaceID, UserID: userID, Role: role}})
+	if err := authz.Can(subject, action, authz.Card(f.card)); err != nil {
+		return nil, err
+	}
+	card := f.card
+	return &card, nil
+}
+
+func newFakeCardsService(userID uuid.UUID) *fakeCardsService {
+	workspaceID := uuid.New()
+	return &fakeCardsService{card: models.Card{Base: models.Base{ID: uuid.New()}, UserID: userID, WorkspaceID: &workspaceID}}
+}
+
+func upload(content []byte, name string) UploadAttachmentDTO {
+	return UploadAttachmentDTO{FileName: name, Size: int64(len(content)), File: bytes.NewReader(content)}
+}
+
+func TestAttachmentsService_Upload(t *testing.T) {
+	userID := uuid.New()
+	cardsService := newFakeCardsService(userID)
+	cardID := cardsService.card.ID
+
+	t.Run("stores the file with the sniffed content type", func(t *testing.T) {
+		repo := newFakeAttachmentsRepository()
+		store := newMemoryBlobStore()
+		svc := NewAttachmentsService(repo, cardsService, store, 1024)
+
+		content := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{1}, 600)...)
+		attachment, err := svc.Upload(userID, cardID, upload(content, `C:\shots\screen.pdf`))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if attachment.ContentType != "image/png" || attachment.FileName != "screen.pdf" || attachment.Size != int64(len(content)) {
+			t.Fatalf("unexpected attachment: %+v", attachment)
+		}
+		if !bytes.Equal(store.blobs[attachment.StorageKey], content) {
+			t.Fatalf("expected the full file to be stored")
+		}
+		if !strings.HasPrefix(attachment.StorageKey, "cards/"+cardID.String()+"/") {
+			t.Fatalf("unexpected storage key %q", attachment.StorageKey)
+		}
+	})
+
+	t.Run("enforces the size limit", func(t *testing.T) {
+		store := newMemoryBlobStore()
+		svc := NewAttachmentsService(newFakeAttachmentsRepository(), cardsService, store, 8)
+
+		if _, err := svc.Upload(userID, cardID, upload(pngHeader, "a.png")); !errors.Is(err, ErrAttachmentTooLarge) {
+			t.Fatalf("expected too large error, got %v", err)
+		}
+		if len(store.blobs) != 0 {
+			t.Fatalf("did not expect a stored blob")
+		}
+	})
+
+	t.Run("rejects unsupported types", func(t *testing.T) {
+		svc := NewAttachmentsService(newFakeAttachmentsRepository(), cardsService, newMemoryBlobStore(), 1024)
+
+		if _, err := svc.Upload(userID, cardID, upload([]byte("MZ\x90\x00\x03\x00\x00\x00"), "notes.txt")); !errors.Is(err, ErrUnsupportedFileType) {
+			t.Fatalf("expected unsupported type error, got %v", err)
+		}
+	})
+
+	t.Run("rejects cards of other users", func(t *testing.T) {
+		store := newMemoryBlobStore()
+		svc := NewAttachmentsService(newFakeAttachmentsRepository(), cardsService, store, 1024)
+
+		_, err := svc.Upload(uuid.New(), cardID, upload(pngHeader, "a.png"))
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+		if len(store.blobs) != 0 {
+			t.Fatalf("did not expect a stored blob")
+		}
+	})
+
+	t.Run("removes the blob when the row cannot be saved", func(t *testing.T) {
+		repo := newFakeAttachmentsRepository()
+		repo.createErr = errors.New("db down")
+		store := newMemoryBlobStore()
+		svc := NewAttachmentsService(repo, cardsService, store, 1024)
+
+		if _, err := svc.Upload(userID, cardID, upload(pngHeader, "a.png")); err == nil {
+			t.Fatalf("expected error")
+		}
+		if len(store.blobs) != 0 {
+			t.Fatalf("expected orphaned blob to be removed")
+		}
+	})
+}
+
+func TestAttachmentsService_DownloadAndDelete(t *testing.T) {
+	userID := uuid.New()
+	cardsService := newFakeCardsService(userID)
+	cardID := cardsService.card.ID
+
+	attachment := models.Attachment{Base: models.Base{ID: uuid.New()}, CardID: cardID, UserID: userID, StorageKey: "cards/x/y", Size: 5}
+	other := models.Attachment{Base: models.Base{ID: uuid.New()}, CardID: uuid.New(), UserID: userID, StorageKey: "cards/z/w"}
+	repo := newFakeAttachmentsRepository(attachment, other)
+	store := newMemoryBlobStore()
+	store.blobs[attachment.StorageKey] = []byte("hello")
+	svc := NewAttachmentsService(repo, cardsService, store, 1024)
+
+	got, body, err := svc.Download(userID, cardID, attachment.ID)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if got.ID != attachment.ID || string(data) != "hello" {
+		t.Fatalf("unexpected download: %+v %q", got, data)
+	}
+
+	if _, _, err := svc.Download(userID, cardID, other.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected attachments of other cards to be hidden, got %v", err)
+	}
+	if _, err := svc.Delete(uuid.New(), cardID, attachment.ID); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	if _, err := svc.Delete(userID, cardID, attachment.ID); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.deletedID != attachment.ID || len(store.blobs) != 0 {
+		t.Fatalf("expected row and blob to be removed")
+	}
+}
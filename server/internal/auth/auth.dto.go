@@ -1,9 +1,17 @@
 package auth
 
+import (
+	"time"
+
+	"cards/internal/models"
+)
+
+// RegisterRequestDTO and ResetPasswordRequestDTO share one password rule, so
+// every password a user signs up with could also be set by a reset.
 type RegisterRequestDTO struct {
 	Name     string `json:"name" binding:"required"`
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8"`
 }
 
 type LoginRequestDTO struct {
@@ -12,17 +20,104 @@ type LoginRequestDTO struct {
 }
 
 type UserResponseDTO struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Email     string `json:"email"`
-	CreatedAt string `json:"created_at"`
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Email         string          `json:"email"`
+	Role          models.UserRole `json:"role"`
+	EmailVerified bool            `json:"email_verified"`
+	MFAEnabled    bool            `json:"mfa_enabled"`
+	CreatedAt     string          `json:"created_at"`
+}
+
+// newUserResponse is the only way users leave the auth package, so the
+// password hash and relations on models.User are never sent.
+func newUserResponse(user *models.User, mfaEnabled bool) UserResponseDTO {
+	return UserResponseDTO{
+		ID:            user.ID.String(),
+		Name:          user.Name,
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified(),
+		MFAEnabled:    mfaEnabled,
+		CreatedAt:     user.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+type RefreshRequestDTO struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenPairDTO is a short-lived access token, sent as a Bearer token, and the
+// refresh token that replaces it once ExpiresAt has passed.
+type TokenPairDTO struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresAt    string `json:"expires_at"`
 }
 
+// LoginResponseDTO carries either the tokens and user of a new session or,
+// for users with two-factor authentication, MFARequired and the challenge
+// token to complete the login with at /auth/login/mfa.
 type LoginResponseDTO struct {
-	Token string           `json:"token"`
-	User  *UserResponseDTO `json:"user"`
+	*TokenPairDTO
+	User        *UserResponseDTO `json:"user,omitempty"`
+	MFARequired bool             `json:"mfa_required,omitempty"`
+	MFAToken    string           `json:"mfa_token,omitempty"`
 }
 
 type ForgotPasswordRequestDTO struct {
 	Email string `json:"email" binding:"required"`
 }
+
+type ResetPasswordRequestDTO struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+type VerifyEmailRequestDTO struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type MFALoginRequestDTO struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MFACodeRequestDTO takes a code from the authenticator app or, where the
+// factor is already on, a recovery code.
+type MFACodeRequestDTO struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TOTPEnrollmentDTO is what authenticator apps need: the provisioning URI to
+// show as a QR code, or the secret to type in.
+type TOTPEnrollmentDTO struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesDTO is shown once; only hashes of the codes are kept.
+type RecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type CreatePersonalAccessTokenRequestDTO struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=cards:read cards:write llm:generate"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+type PersonalAccessTokenResponseDTO struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  string   `json:"created_at"`
+	ExpiresAt  string   `json:"expires_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+}
+
+// PersonalAccessTokenCreatedDTO is the only time the token itself is shown.
+type PersonalAccessTokenCreatedDTO struct {
+	PersonalAccessTokenResponseDTO
+	Token string `json:"token"`
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestPasswordRule binds the same passwords into every DTO that sets one, so
+// signing up cannot accept a password a reset would refuse.
+func TestPasswordRule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	bind := func(body map[string]string, dto any) error {
+		raw, _ := json.Marshal(body)
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("POST", "/", strings.NewReader(string(raw)))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c.ShouldBindJSON(dto)
+	}
+
+	tests := []struct {
+		password string
+		valid    bool
+	}{
+		{"", false},
+		{"a", false},
+		{"1234567", false},
+		{"12345678", true},
+		{"a much longer passphrase", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			register := bind(map[string]string{"name": "Ana", "email": "ana@example.com", "password": tt.password}, &RegisterRequestDTO{})
+			reset := bind(map[string]string{"token": "token", "password": tt.password}, &ResetPasswordRequestDTO{})
+
+			for name, err := range map[string]error{"register": register, "reset": reset} {
+				if (err == nil) != tt.valid {
+					t.Fatalf("%s: expected valid=%v, got %v", name, tt.valid, err)
+				}
+			}
+		})
+	}
+}
@@ -1,31 +1,59 @@
 package auth
 
 import (
+	"cards/internal/apperrors"
+	"cards/internal/params"
 	"cards/internal/types"
-	"fmt"
+	"errors"
+	"log"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type AuthHandler interface {
 	Register(c *gin.Context)
 	Login(c *gin.Context)
+	CompleteMFALogin(c *gin.Context)
+	OAuthProviders(c *gin.Context)
+	OAuthStart(c *gin.Context)
+	OAuthCallback(c *gin.Context)
+	Refresh(c *gin.Context)
+	Logout(c *gin.Context)
+	LogoutAll(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerification(c *gin.Context)
+	EnrollTOTP(c *gin.Context)
+	ConfirmTOTP(c *gin.Context)
+	DisableTOTP(c *gin.Context)
+	CreatePersonalAccessToken(c *gin.Context)
+	ListPersonalAccessTokens(c *gin.Context)
+	RevokePersonalAccessToken(c *gin.Context)
 	Me(c *gin.Context)
 }
 
 type authHandler struct {
 	Service AuthService
+	OAuth   *oauthClient
 }
 
-func NewAuthHandler(service AuthService) AuthHandler {
-	return &authHandler{Service: service}
+func NewAuthHandler(service AuthService, oauth *oauthClient) AuthHandler {
+	return &authHandler{Service: service, OAuth: oauth}
 }
 
+// oauthFlowCookie holds the signed oauthFlow between the start and callback
+// endpoints.
+const oauthFlowCookie = "oauth_flow"
+
 func (h *authHandler) Register(c *gin.Context) {
 	var payload RegisterRequestDTO
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(http.StatusBadRequest, "Invalid request payload", nil, err.Error()))
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
 		return
 	}
 
@@ -35,41 +63,372 @@ func (h *authHandler) Register(c *gin.Context) {
 		Password: payload.Password,
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(http.StatusBadRequest, "Registration failed", nil, err.Error()))
+		apperrors.Respond(c, "Registration failed", err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, types.NewApiResponse(http.StatusCreated, "User registered successfully", user, nil))
+	c.JSON(http.StatusCreated, types.NewApiResponse(http.StatusCreated, "User registered successfully", newUserResponse(user, false), nil))
 }
 
 func (h *authHandler) Login(c *gin.Context) {
 	var payload LoginRequestDTO
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(http.StatusBadRequest, "Invalid request payload", nil, err.Error()))
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
 		return
 	}
 	res, err := h.Service.Login(payload.Email, payload.Password)
 	if err != nil {
-		status := http.StatusBadRequest
-		if err.Error() == "invalid credentials" {
-			status = http.StatusUnauthorized
-		}
-		c.JSON(status, types.NewApiResponse(status, "Login failed", nil, err.Error()))
+		apperrors.Respond(c, "Login failed", err)
+		return
+	}
+	if res.MFARequired {
+		c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Two-factor authentication required", res, nil))
+		return
+	}
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Login successful", res, nil))
+}
+
+func (h *authHandler) CompleteMFALogin(c *gin.Context) {
+	var payload MFALoginRequestDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	res, err := h.Service.CompleteMFALogin(payload.MFAToken, payload.Code)
+	if err != nil {
+		apperrors.Respond(c, "Login failed", err)
 		return
 	}
+
 	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Login successful", res, nil))
 }
 
+func (h *authHandler) OAuthProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Sign-in providers found", gin.H{"providers": h.OAuth.Names()}, nil))
+}
+
+// OAuthStart sends the browser to the provider's consent screen.
+func (h *authHandler) OAuthStart(c *gin.Context) {
+	provider, ok := h.OAuth.provider(c.Param("provider"))
+	if !ok {
+		apperrors.Respond(c, "Sign-in failed", apperrors.NotFound("unknown sign-in provider"))
+		return
+	}
+
+	redirectURL, flow, err := h.OAuth.Start(c.Request.Context(), provider)
+	if err != nil {
+		apperrors.Respond(c, "Sign-in failed", apperrors.Upstream("sign-in provider is not available", err))
+		return
+	}
+
+	setOAuthFlowCookie(c, flow, int(oauthFlowTTL.Seconds()))
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+// OAuthCallback is where the provider sends the browser back. It finishes the
+// login like Login and hands the result to the web app, failures included,
+// since there is no page here to show them on.
+func (h *authHandler) OAuthCallback(c *gin.Context) {
+	provider, ok := h.OAuth.provider(c.Param("provider"))
+	if !ok {
+		apperrors.Respond(c, "Sign-in failed", apperrors.NotFound("unknown sign-in provider"))
+		return
+	}
+	fail := func(message string) {
+		c.Redirect(http.StatusFound, h.OAuth.SuccessRedirect(url.Values{"error": {message}}))
+	}
+
+	flow, err := c.Cookie(oauthFlowCookie)
+	setOAuthFlowCookie(c, "", -1)
+	if err != nil {
+		fail("Sign-in expired, please try again")
+		return
+	}
+	if denied := c.Query("error"); denied != "" {
+		fail("Sign-in was cancelled")
+		return
+	}
+
+	identity, err := h.OAuth.Finish(c.Request.Context(), provider, flow, c.Query("state"), c.Query("code"))
+	if err != nil {
+		log.Printf("%s sign-in failed: %v", provider.Name, err)
+		fail("Sign-in failed, please try again")
+		return
+	}
+
+	res, err := h.Service.OAuthLogin(*identity)
+	if err != nil {
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) && appErr.Message != "" {
+			fail(appErr.Message)
+			return
+		}
+		log.Printf("%s sign-in failed: %v", provider.Name, err)
+		fail("Sign-in failed, please try again")
+		return
+	}
+
+	result := url.Values{}
+	if res.MFARequired {
+		result.Set("mfa_token", res.MFAToken)
+	} else {
+		result.Set("token", res.Token)
+		result.Set("refresh_token", res.RefreshToken)
+		result.Set("expires_at", res.ExpiresAt)
+	}
+	c.Redirect(http.StatusFound, h.OAuth.SuccessRedirect(result))
+}
+
+// setOAuthFlowCookie scopes the cookie to the provider's endpoints; a
+// negative maxAge deletes it.
+func setOAuthFlowCookie(c *gin.Context, value string, maxAge int) {
+	path := c.Request.URL.Path
+	path = path[:strings.LastIndex(path, "/")]
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthFlowCookie, value, maxAge, path, "", secure, true)
+}
+
+func (h *authHandler) Refresh(c *gin.Context) {
+	var payload RefreshRequestDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	tokens, err := h.Service.Refresh(payload.RefreshToken)
+	if err != nil {
+		apperrors.Respond(c, "Refresh failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Tokens refreshed successfully", tokens, nil))
+}
+
+func (h *authHandler) Logout(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	// Tokens issued before sessions existed have no session ID; uuid.Nil
+	// tells the service there is nothing to revoke.
+	sessionID, _ := uuid.Parse(c.GetString("sessionID"))
+	if err := h.Service.Logout(userID, sessionID); err != nil {
+		apperrors.Respond(c, "Logout failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Logged out successfully", nil, nil))
+}
+
+func (h *authHandler) LogoutAll(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	if err := h.Service.LogoutAll(userID); err != nil {
+		apperrors.Respond(c, "Logout failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Logged out of all sessions successfully", nil, nil))
+}
+
+// ForgotPassword answers the same whether or not the email is registered.
+func (h *authHandler) ForgotPassword(c *gin.Context) {
+	var payload ForgotPasswordRequestDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	if err := h.Service.ForgotPassword(payload.Email); err != nil {
+		apperrors.Respond(c, "Password reset request failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "If the email is registered, a reset link has been sent", nil, nil))
+}
+
+func (h *authHandler) ResetPassword(c *gin.Context) {
+	var payload ResetPasswordRequestDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	if err := h.Service.ResetPassword(payload.Token, payload.Password); err != nil {
+		apperrors.Respond(c, "Password reset failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Password reset successfully", nil, nil))
+}
+
+func (h *authHandler) VerifyEmail(c *gin.Context) {
+	var payload VerifyEmailRequestDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	if err := h.Service.VerifyEmail(payload.Token); err != nil {
+		apperrors.Respond(c, "Email verification failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Email verified successfully", nil, nil))
+}
+
+func (h *authHandler) ResendVerification(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	if err := h.Service.ResendVerification(userID); err != nil {
+		apperrors.Respond(c, "Verification email failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Verification email sent", nil, nil))
+}
+
+func (h *authHandler) EnrollTOTP(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	enrollment, err := h.Service.EnrollTOTP(userID)
+	if err != nil {
+		apperrors.Respond(c, "Two-factor enrollment failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Scan the code with your authenticator app, then confirm with a code from it", enrollment, nil))
+}
+
+func (h *authHandler) ConfirmTOTP(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var payload MFACodeRequestDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	codes, err := h.Service.ConfirmTOTP(userID, payload.Code)
+	if err != nil {
+		apperrors.Respond(c, "Two-factor confirmation failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Two-factor authentication enabled", codes, nil))
+}
+
+func (h *authHandler) DisableTOTP(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var payload MFACodeRequestDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	if err := h.Service.DisableTOTP(userID, payload.Code); err != nil {
+		apperrors.Respond(c, "Disabling two-factor authentication failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Two-factor authentication disabled", nil, nil))
+}
+
+func (h *authHandler) CreatePersonalAccessToken(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var payload CreatePersonalAccessTokenRequestDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	token, err := h.Service.CreatePersonalAccessToken(userID, payload)
+	if err != nil {
+		apperrors.Respond(c, "Creating access token failed", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewApiResponse(http.StatusCreated, "Access token created; copy it now, it will not be shown again", token, nil))
+}
+
+func (h *authHandler) ListPersonalAccessTokens(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	tokens, err := h.Service.ListPersonalAccessTokens(userID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to retrieve access tokens", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Access tokens retrieved successfully", tokens, nil))
+}
+
+func (h *authHandler) RevokePersonalAccessToken(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	tokenID, err := params.UUID(c, "tokenID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid access token ID", err)
+		return
+	}
+
+	if err := h.Service.RevokePersonalAccessToken(userID, tokenID); err != nil {
+		apperrors.Respond(c, "Revoking access token failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Access token revoked successfully", nil, nil))
+}
+
 func (h *authHandler) Me(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, types.NewApiResponse(http.StatusUnauthorized, "Unauthorized access", nil, "Unauthorized"))
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
 		return
 	}
 
-	user, err := h.Service.GetUser(fmt.Sprint(userID))
+	user, err := h.Service.GetUser(userID.String())
 	if err != nil {
-		c.JSON(http.StatusNotFound, types.NewApiResponse(http.StatusNotFound, "User not found", nil, "User not found"))
+		apperrors.Respond(c, "User not found", err)
 		return
 	}
 
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// attemptLimiter allows a number of attempts per key, such as an email
+// address, within a sliding window. Email addresses are counted whether or
+// not they belong to a user, so being limited says nothing about an account.
+// Counts live in memory and are per API instance.
+type attemptLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	attempts  map[string][]time.Time
+	lastSweep time.Time
+}
+
+func newAttemptLimiter(limit int, window time.Duration) *attemptLimiter {
+	return &attemptLimiter{limit: limit, window: window, attempts: map[string][]time.Time{}}
+}
+
+// Allow records an attempt for key and reports whether it is within the
+// limit. Keys are case-insensitive. Refused attempts are not recorded.
+func (l *attemptLimiter) Allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= l.window {
+		for key, times := range l.attempts {
+			if len(l.recent(times, now)) == 0 {
+				delete(l.attempts, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	key = strings.ToLower(strings.TrimSpace(key))
+	times := l.recent(l.attempts[key], now)
+	if len(times) >= l.limit {
+		l.attempts[key] = times
+		return false
+	}
+	l.attempts[key] = append(times, now)
+	return true
+}
+
+func (l *attemptLimiter) recent(times []time.Time, now time.Time) []time.Time {
+	for len(times) > 0 && now.Sub(times[0]) >= l.window {
+		times = times[1:]
+	}
+	return times
+}
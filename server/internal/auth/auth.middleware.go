@@ -1,53 +1,115 @@
 package auth
 
 import (
-	"cards/internal/types"
+	"cards/internal/apperrors"
+	"cards/internal/models"
+	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// Scopes a personal access token can be granted.
+const (
+	ScopeCardsRead   = "cards:read"
+	ScopeCardsWrite  = "cards:write"
+	ScopeLLMGenerate = "llm:generate"
+)
+
+// MiddlewareRepository is where the middleware looks up the session of a
+// token, the signed-in user and personal access tokens. AuthRepository
+// satisfies it.
+type MiddlewareRepository interface {
+	FindSessionByID(id uuid.UUID) (*models.Session, error)
+	FindUserByID(id string) (*models.User, error)
+	FindPersonalAccessTokenByHash(tokenHash string) (*models.PersonalAccessToken, error)
+	TouchPersonalAccessToken(id uuid.UUID, at time.Time) error
+}
+
+// Middleware authenticates requests and checks the signed-in user. main
+// builds one and hands it to every package that registers routes.
+type Middleware interface {
+	Authenticate(scopes ...string) gin.HandlerFunc
+	RequireVerifiedEmail() gin.HandlerFunc
+	RequireRole(roles ...models.UserRole) gin.HandlerFunc
+}
+
+type middleware struct {
+	repository MiddlewareRepository
+}
+
+func NewMiddleware(repository MiddlewareRepository) Middleware {
+	return &middleware{repository: repository}
+}
+
+// lastUsedResolution bounds how often a token's last use is written, so a
+// busy script does not cost a write per request.
+const lastUsedResolution = time.Minute
+
+// Authenticate accepts a signed-in user's JWT, or a personal access token
+// granted every one of scopes. Routes that list no scopes are for the web app
+// only and refuse personal access tokens.
+func (m *middleware) Authenticate(scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, types.NewApiResponse(http.StatusUnauthorized, "", nil, "Authorization header required"))
+			apperrors.Abort(c, http.StatusUnauthorized, "", apperrors.Unauthorized("Authorization header required"))
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, types.NewApiResponse(http.StatusUnauthorized, "", nil, "Invalid authorization header format"))
+			apperrors.Abort(c, http.StatusUnauthorized, "", apperrors.Unauthorized("Invalid authorization header format"))
 			return
 		}
 
 		tokenString := parts[1]
+		if strings.HasPrefix(tokenString, personalAccessTokenPrefix) {
+			m.authenticatePersonalAccessToken(c, tokenString, scopes)
+			return
+		}
+
 		token, err := ValidateJWTToken(tokenString)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, types.NewApiResponse(http.StatusUnauthorized, "Invalid or expired token", nil, err))
+			apperrors.Abort(c, http.StatusUnauthorized, "Invalid or expired token", apperrors.Unauthorized(err.Error()))
 			return
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, types.NewApiResponse(http.StatusUnauthorized, "", nil, "Invalid token claims"))
+			apperrors.Abort(c, http.StatusUnauthorized, "", apperrors.Unauthorized("Invalid token claims"))
 			return
 		}
 
 		sub, ok := claims["sub"]
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, types.NewApiResponse(http.StatusUnauthorized, "", nil, "Missing subject claim"))
+			apperrors.Abort(c, http.StatusUnauthorized, "", apperrors.Unauthorized("Missing subject claim"))
 			return
 		}
 
 		userID, ok := sub.(string)
 		if !ok || userID == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, types.NewApiResponse(http.StatusUnauthorized, "", nil, "Invalid subject claim"))
+			apperrors.Abort(c, http.StatusUnauthorized, "", apperrors.Unauthorized("Invalid subject claim"))
 			return
 		}
 
+		// Tokens without a session ID predate sessions; they cannot be revoked
+		// and are only accepted until they expire.
+		if sid, ok := claims["sid"]; ok {
+			sessionID, err := m.sessionOf(sid, userID)
+			if err != nil {
+				apperrors.Abort(c, http.StatusUnauthorized, "Invalid or expired token", err)
+				return
+			}
+			c.Set("sessionID", sessionID.String())
+		}
+
 		c.Set("userID", userID)
 		c.Set("email", claims["email"])
 		c.Set("role", claims["role"])
@@ -55,3 +117,108 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func (m *middleware) authenticatePersonalAccessToken(c *gin.Context, raw string, scopes []string) {
+	if len(scopes) == 0 {
+		apperrors.Abort(c, http.StatusForbidden, "", apperrors.Forbidden("Personal access tokens cannot be used here"))
+		return
+	}
+
+	now := time.Now()
+	token, err := m.repository.FindPersonalAccessTokenByHash(hashSecretToken(raw))
+	if err != nil || !token.Active(now) {
+		apperrors.Abort(c, http.StatusUnauthorized, "Invalid or expired token", apperrors.Unauthorized("Personal access token is invalid, expired or revoked"))
+		return
+	}
+	for _, scope := range scopes {
+		if !token.HasScope(scope) {
+			apperrors.Abort(c, http.StatusForbidden, "", apperrors.Forbidden("Personal access token lacks the "+scope+" scope"))
+			return
+		}
+	}
+	// Disabling an account revokes its sessions but leaves its tokens, so
+	// they work again if it is re-enabled.
+	user, err := m.repository.FindUserByID(token.UserID.String())
+	if err != nil || user.Disabled() {
+		apperrors.Abort(c, http.StatusUnauthorized, "Invalid or expired token", apperrors.Unauthorized("account is disabled"))
+		return
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		if err := m.repository.TouchPersonalAccessToken(token.ID, now); err != nil {
+			log.Printf("Recording use of access token %s failed: %v", token.ID, err)
+		}
+	}
+
+	c.Set("userID", token.UserID.String())
+	c.Set("personalAccessTokenID", token.ID.String())
+
+	c.Next()
+}
+
+func (m *middleware) sessionOf(sid any, userID string) (uuid.UUID, error) {
+	rawID, _ := sid.(string)
+	sessionID, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.Nil, apperrors.Unauthorized("Invalid session claim")
+	}
+
+	session, err := m.repository.FindSessionByID(sessionID)
+	if err != nil || session.UserID.String() != userID || !session.Active(time.Now()) {
+		return uuid.Nil, apperrors.Unauthorized("Session has been revoked")
+	}
+	return sessionID, nil
+}
+
+// VerifiedEmailRequired reports whether REQUIRE_VERIFIED_EMAIL is on. It is
+// off by default, so accounts created before verification existed keep
+// working until it is turned on.
+func VerifiedEmailRequired() bool {
+	required, _ := strconv.ParseBool(os.Getenv("REQUIRE_VERIFIED_EMAIL"))
+	return required
+}
+
+// RequireVerifiedEmail refuses users who have not verified their email, when
+// VerifiedEmailRequired. It goes after Authenticate on routes that reach
+// beyond the user's own data: LLM generation and sharing.
+func (m *middleware) RequireVerifiedEmail() gin.HandlerFunc {
+	if !VerifiedEmailRequired() {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		user, err := m.repository.FindUserByID(c.GetString("userID"))
+		if err != nil {
+			apperrors.Abort(c, http.StatusUnauthorized, "", apperrors.Unauthorized("user not found"))
+			return
+		}
+		if !user.EmailVerified() {
+			apperrors.Abort(c, http.StatusForbidden, "", apperrors.Forbidden("Verify your email address to use this feature"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole refuses users who hold none of roles. It goes after
+// Authenticate and reads the role from the database rather than the token,
+// so a demotion applies before the user's access token runs out.
+func (m *middleware) RequireRole(roles ...models.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := m.repository.FindUserByID(c.GetString("userID"))
+		if err != nil {
+			apperrors.Abort(c, http.StatusUnauthorized, "", apperrors.Unauthorized("user not found"))
+			return
+		}
+		for _, role := range roles {
+			if user.Role == role {
+				c.Set("role", string(user.Role))
+				c.Next()
+				return
+			}
+		}
+
+		apperrors.Abort(c, http.StatusForbidden, "", apperrors.Forbidden("You do not have permission to do this"))
+	}
+}
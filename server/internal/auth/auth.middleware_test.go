@@ -2,23 +2,28 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"cards/internal/models"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-func TestAuthMiddleware(t *testing.T) {
+func TestMiddleware_Authenticate(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	t.Run("rejects missing authorization header", func(t *testing.T) {
 		t.Setenv("JWT_SECRET", "secret")
 
 		r := gin.New()
-		r.GET("/me", AuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+		r.GET("/me", NewMiddleware(&fakeAuthRepository{}).Authenticate(), func(c *gin.Context) { c.Status(http.StatusOK) })
 
 		w := httptest.NewRecorder()
 		req := httptest.NewRequest(http.MethodGet, "/me", nil)
@@ -33,7 +38,7 @@ func TestAuthMiddleware(t *testing.T) {
 		t.Setenv("JWT_SECRET", "secret")
 
 		r := gin.New()
-		r.GET("/me", AuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+		r.GET("/me", NewMiddleware(&fakeAuthRepository{}).Authenticate(), func(c *gin.Context) { c.Status(http.StatusOK) })
 
 		w := httptest.NewRecorder()
 		req := httptest.NewRequest(http.MethodGet, "/me", nil)
@@ -49,7 +54,7 @@ func TestAuthMiddleware(t *testing.T) {
 		t.Setenv("JWT_SECRET", "secret")
 
 		r := gin.New()
-		r.GET("/me", AuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+		r.GET("/me", NewMiddleware(&fakeAuthRepository{}).Authenticate(), func(c *gin.Context) { c.Status(http.StatusOK) })
 
 		w := httptest.NewRecorder()
 		req := httptest.NewRequest(http.MethodGet, "/me", nil)
@@ -74,7 +79,7 @@ func TestAuthMiddleware(t *testing.T) {
 		}
 
 		r := gin.New()
-		r.GET("/me", AuthMiddleware(), func(c *gin.Context) {
+		r.GET("/me", NewMiddleware(&fakeAuthRepository{}).Authenticate(), func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"userID": c.GetString("userID")})
 		})
 
@@ -99,3 +104,235 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 }
 
+func TestMiddleware_Sessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "secret")
+
+	userID := uuid.New()
+	revokedAt := time.Now()
+	active := models.Session{Base: models.Base{ID: uuid.New()}, UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}
+	revoked := models.Session{Base: models.Base{ID: uuid.New()}, UserID: userID, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+	expired := models.Session{Base: models.Base{ID: uuid.New()}, UserID: userID, ExpiresAt: time.Now().Add(-time.Minute)}
+	stranger := models.Session{Base: models.Base{ID: uuid.New()}, UserID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)}
+
+	repo := &fakeAuthRepository{}
+	for _, session := range []models.Session{active, revoked, expired, stranger} {
+		repo.CreateSession(&session)
+	}
+
+	r := gin.New()
+	r.GET("/me", NewMiddleware(repo).Authenticate(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"sessionID": c.GetString("sessionID")})
+	})
+
+	tests := []struct {
+		name string
+		sid  any
+		want int
+	}{
+		{"active session", active.ID.String(), http.StatusOK},
+		{"revoked session", revoked.ID.String(), http.StatusUnauthorized},
+		{"expired session", expired.ID.String(), http.StatusUnauthorized},
+		{"another user's session", stranger.ID.String(), http.StatusUnauthorized},
+		{"unknown session", uuid.NewString(), http.StatusUnauthorized},
+		{"malformed session", "nope", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"sub": userID.String(),
+				"sid": tt.sid,
+				"exp": time.Now().Add(time.Hour).Unix(),
+			}).SignedString([]byte("secret"))
+			if err != nil {
+				t.Fatalf("failed to sign token: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+tokenString)
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMiddleware_PersonalAccessTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "secret")
+
+	userID, disabledID := uuid.New(), uuid.New()
+	revokedAt := time.Now()
+	repo := &fakeAuthRepository{findByID: func(id string) (*models.User, error) {
+		user := &models.User{Base: models.Base{ID: uuid.MustParse(id)}}
+		if user.ID == disabledID {
+			user.DisabledAt = &revokedAt
+		}
+		return user, nil
+	}}
+	issue := func(userID uuid.UUID, scopes string, expiresAt time.Time, revokedAt *time.Time) string {
+		raw := personalAccessTokenPrefix + uuid.NewString()
+		repo.CreatePersonalAccessToken(&models.PersonalAccessToken{
+			UserID: userID, TokenHash: hashSecretToken(raw), Scopes: scopes, ExpiresAt: expiresAt, RevokedAt: revokedAt,
+		})
+		return raw
+	}
+	reader := issue(userID, ScopeCardsRead, time.Now().Add(time.Hour), nil)
+	readWriter := issue(userID, ScopeCardsRead+" "+ScopeCardsWrite, time.Now().Add(time.Hour), nil)
+	expired := issue(userID, ScopeCardsRead, time.Now().Add(-time.Minute), nil)
+	revoked := issue(userID, ScopeCardsRead, time.Now().Add(time.Hour), &revokedAt)
+	disabled := issue(disabledID, ScopeCardsRead, time.Now().Add(time.Hour), nil)
+
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"userID": c.GetString("userID")})
+	}
+	authMiddleware := NewMiddleware(repo)
+	r := gin.New()
+	r.GET("/cards", authMiddleware.Authenticate(ScopeCardsRead), ok)
+	r.POST("/cards", authMiddleware.Authenticate(ScopeCardsWrite), ok)
+	r.GET("/me", authMiddleware.Authenticate(), ok)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"read with read scope", http.MethodGet, "/cards", reader, http.StatusOK},
+		{"write without write scope", http.MethodPost, "/cards", reader, http.StatusForbidden},
+		{"write with write scope", http.MethodPost, "/cards", readWriter, http.StatusOK},
+		{"session-only route", http.MethodGet, "/me", readWriter, http.StatusForbidden},
+		{"expired token", http.MethodGet, "/cards", expired, http.StatusUnauthorized},
+		{"revoked token", http.MethodGet, "/cards", revoked, http.StatusUnauthorized},
+		{"disabled account", http.MethodGet, "/cards", disabled, http.StatusUnauthorized},
+		{"unknown token", http.MethodGet, "/cards", personalAccessTokenPrefix + "unknown", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+			if tt.want == http.StatusOK && !strings.Contains(w.Body.String(), userID.String()) {
+				t.Fatalf("expected the token's user, got %s", w.Body.String())
+			}
+		})
+	}
+
+	t.Run("records last use at most once a minute", func(t *testing.T) {
+		repo.tokenTouches = 0
+		for i := 0; i < 3; i++ {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/cards", nil)
+			req.Header.Set("Authorization", "Bearer "+reader)
+			r.ServeHTTP(w, req)
+		}
+		if repo.tokenTouches != 0 {
+			t.Fatalf("expected a recently used token not to be touched again, got %d writes", repo.tokenTouches)
+		}
+
+		token, _ := repo.FindPersonalAccessTokenByHash(hashSecretToken(reader))
+		if token.LastUsedAt == nil {
+			t.Fatalf("expected last use recorded")
+		}
+	})
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	verifiedAt := time.Now()
+	verified := &models.User{Base: models.Base{ID: uuid.New()}, EmailVerifiedAt: &verifiedAt}
+	unverified := &models.User{Base: models.Base{ID: uuid.New()}}
+	authMiddleware := NewMiddleware(&fakeAuthRepository{findByID: func(id string) (*models.User, error) {
+		for _, user := range []*models.User{verified, unverified} {
+			if user.ID.String() == id {
+				return user, nil
+			}
+		}
+		return nil, errors.New("not found")
+	}})
+
+	newRouter := func() *gin.Engine {
+		r := gin.New()
+		r.POST("/share/:userID", func(c *gin.Context) {
+			c.Set("userID", c.Param("userID"))
+		}, authMiddleware.RequireVerifiedEmail(), func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		required string
+		user     string
+		want     int
+	}{
+		{"off lets unverified users through", "", unverified.ID.String(), http.StatusNoContent},
+		{"on lets verified users through", "true", verified.ID.String(), http.StatusNoContent},
+		{"on refuses unverified users", "true", unverified.ID.String(), http.StatusForbidden},
+		{"on refuses unknown users", "true", uuid.NewString(), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("REQUIRE_VERIFIED_EMAIL", tt.required)
+			w := httptest.NewRecorder()
+			newRouter().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/share/"+tt.user, nil))
+
+			if w.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	admin := &models.User{Base: models.Base{ID: uuid.New()}, Role: models.UserRoleAdmin}
+	user := &models.User{Base: models.Base{ID: uuid.New()}, Role: models.UserRoleUser}
+	authMiddleware := NewMiddleware(&fakeAuthRepository{findByID: func(id string) (*models.User, error) {
+		for _, candidate := range []*models.User{admin, user} {
+			if candidate.ID.String() == id {
+				return candidate, nil
+			}
+		}
+		return nil, errors.New("not found")
+	}})
+
+	r := gin.New()
+	r.GET("/admin/:userID", func(c *gin.Context) {
+		c.Set("userID", c.Param("userID"))
+	}, authMiddleware.RequireRole(models.UserRoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name string
+		user string
+		want int
+	}{
+		{"admin", admin.ID.String(), http.StatusNoContent},
+		{"user", user.ID.String(), http.StatusForbidden},
+		{"unknown user", uuid.NewString(), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/"+tt.user, nil))
+
+			if w.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,487 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultOAuthCallbackBaseURL = "http://localhost:8000/api/v1/auth/oauth"
+	defaultOAuthSuccessURL      = "http://localhost:5173/oauth/callback"
+
+	oauthFlowTTL     = 10 * time.Minute
+	oauthHTTPTimeout = 10 * time.Second
+)
+
+// ExternalIdentity is who a sign-in provider says the user is.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// oauthProvider is an OAuth 2.0 authorization server. With an Issuer it is an
+// OpenID Connect provider: endpoints not given are discovered, and the user
+// comes from the verified ID token. Without one, userInfo fetches the user
+// with the access token, as GitHub needs.
+type oauthProvider struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	Issuer       string
+	AuthURL      string
+	TokenURL     string
+	JWKSURL      string
+	Scopes       []string
+
+	userInfo func(ctx context.Context, client *http.Client, accessToken string) (*ExternalIdentity, error)
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+// oauthFlow is what the callback needs to finish a login the start endpoint
+// began. It travels in a signed cookie, so it stays with the browser that
+// started the login and never reaches the provider.
+type oauthFlow struct {
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+type oauthClient struct {
+	providers       map[string]*oauthProvider
+	httpClient      *http.Client
+	callbackBaseURL string
+	successURL      string
+	flowKey         []byte
+	now             func() time.Time
+}
+
+// newOAuthClientFromEnv configures the providers named in OAUTH_PROVIDERS,
+// e.g. "google,github,acme", each from OAUTH_<NAME>_CLIENT_ID,
+// OAUTH_<NAME>_CLIENT_SECRET and, for OpenID Connect providers other than
+// Google, OAUTH_<NAME>_ISSUER.
+func newOAuthClientFromEnv() (*oauthClient, error) {
+	var providers []*oauthProvider
+	for _, name := range strings.Split(os.Getenv("OAUTH_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+		provider := &oauthProvider{
+			Name:         name,
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			Scopes:       []string{"openid", "email", "profile"},
+		}
+		switch name {
+		case "google":
+			if provider.Issuer == "" {
+				provider.Issuer = "https://accounts.google.com"
+			}
+		case "github":
+			provider.AuthURL = "https://github.com/login/oauth/authorize"
+			provider.TokenURL = "https://github.com/login/oauth/access_token"
+			provider.Scopes = []string{"read:user", "user:email"}
+			provider.userInfo = githubUserInfo("https://api.github.com")
+		}
+		if provider.ClientID == "" {
+			return nil, fmt.Errorf("%sCLIENT_ID is not set", prefix)
+		}
+		if provider.Issuer == "" && provider.userInfo == nil {
+			return nil, fmt.Errorf("%sISSUER is not set", prefix)
+		}
+		providers = append(providers, provider)
+	}
+
+	return newOAuthClient(
+		providers,
+		envString("OAUTH_CALLBACK_BASE_URL", defaultOAuthCallbackBaseURL),
+		envString("OAUTH_SUCCESS_URL", defaultOAuthSuccessURL),
+		[]byte(os.Getenv("JWT_SECRET")),
+	), nil
+}
+
+func newOAuthClient(providers []*oauthProvider, callbackBaseURL string, successURL string, jwtSecret []byte) *oauthClient {
+	byName := map[string]*oauthProvider{}
+	for _, provider := range providers {
+		byName[provider.Name] = provider
+	}
+
+	// Like MFA challenges, flow cookies are signed with a key derived from
+	// the JWT secret so they can never pass for access tokens.
+	mac := hmac.New(sha256.New, jwtSecret)
+	mac.Write([]byte("oauth-flow"))
+
+	return &oauthClient{
+		providers:       byName,
+		httpClient:      &http.Client{Timeout: oauthHTTPTimeout},
+		callbackBaseURL: strings.TrimSuffix(callbackBaseURL, "/"),
+		successURL:      successURL,
+		flowKey:         mac.Sum(nil),
+		now:             time.Now,
+	}
+}
+
+// Names lists the configured providers, for the login page.
+func (o *oauthClient) Names() []string {
+	names := make([]string, 0, len(o.providers))
+	for name := range o.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (o *oauthClient) provider(name string) (*oauthProvider, bool) {
+	provider, ok := o.providers[name]
+	return provider, ok
+}
+
+func (o *oauthClient) callbackURL(provider *oauthProvider) string {
+	return o.callbackBaseURL + "/" + provider.Name + "/callback"
+}
+
+// Start begins an authorization code flow with PKCE. It returns the URL to
+// send the browser to and the flow to keep in a cookie until the callback.
+func (o *oauthClient) Start(ctx context.Context, provider *oauthProvider) (string, string, error) {
+	if err := o.discover(ctx, provider); err != nil {
+		return "", "", err
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		secret, err := newSecretToken()
+		if err != nil {
+			return "", "", err
+		}
+		secrets[i] = secret
+	}
+	flow := oauthFlow{
+		Provider: provider.Name,
+		State:    secrets[0],
+		Verifier: secrets[1],
+		Nonce:    secrets[2],
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(o.now().Add(oauthFlowTTL)),
+		},
+	}
+	cookie, err := jwt.NewWithClaims(jwt.SigningMethodHS256, flow).SignedString(o.flowKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	challenge := sha256.Sum256([]byte(flow.Verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {provider.ClientID},
+		"redirect_uri":          {o.callbackURL(provider)},
+		"scope":                 {strings.Join(provider.Scopes, " ")},
+		"state":                 {flow.State},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if provider.Issuer != "" {
+		query.Set("nonce", flow.Nonce)
+	}
+	return provider.AuthURL + "?" + query.Encode(), cookie, nil
+}
+
+// Finish checks the callback against the flow cookie, trades the code for
+// tokens and returns the identity they prove.
+func (o *oauthClient) Finish(ctx context.Context, provider *oauthProvider, cookie string, state string, code string) (*ExternalIdentity, error) {
+	var flow oauthFlow
+	_, err := jwt.ParseWithClaims(cookie, &flow, func(token *jwt.Token) (interface{}, error) {
+		return o.flowKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(o.now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sign-in flow: %w", err)
+	}
+	if flow.Provider != provider.Name || state == "" || !hmac.Equal([]byte(flow.State), []byte(state)) {
+		return nil, errors.New("sign-in state does not match")
+	}
+	if err := o.discover(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	tokens, err := o.exchange(ctx, provider, code, flow.Verifier)
+	if err != nil {
+		return nil, err
+	}
+	if provider.Issuer == "" {
+		return provider.userInfo(ctx, o.httpClient, tokens.AccessToken)
+	}
+	return o.verifyIDToken(ctx, provider, tokens.IDToken, flow.Nonce)
+}
+
+type oauthTokens struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (o *oauthClient) exchange(ctx context.Context, provider *oauthProvider, code string, verifier string) (*oauthTokens, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {o.callbackURL(provider)},
+		"client_id":     {provider.ClientID},
+		"client_secret": {provider.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokens oauthTokens
+	if err := doJSON(o.httpClient, req, &tokens); err != nil && tokens.Error == "" {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+	if tokens.Error != "" {
+		return nil, fmt.Errorf("token exchange failed: %s %s", tokens.Error, tokens.ErrorDescription)
+	}
+	return &tokens, nil
+}
+
+// verifyIDToken checks the ID token's signature against the issuer's keys,
+// that it was issued to us for this flow, and reads the user from it.
+func (o *oauthClient) verifyIDToken(ctx context.Context, provider *oauthProvider, idToken string, nonce string) (*ExternalIdentity, error) {
+	if idToken == "" {
+		return nil, errors.New("provider returned no ID token")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return o.signingKey(ctx, provider, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(provider.Issuer),
+		jwt.WithAudience(provider.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+		jwt.WithTimeFunc(o.now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if got, _ := claims["nonce"].(string); !hmac.Equal([]byte(got), []byte(nonce)) {
+		return nil, errors.New("invalid ID token: nonce does not match")
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, errors.New("invalid ID token: no subject")
+	}
+	email, _ := claims["email"].(string)
+	name, _ := claims["name"].(string)
+	return &ExternalIdentity{
+		Provider:      provider.Name,
+		Subject:       subject,
+		Email:         email,
+		EmailVerified: claimTrue(claims["email_verified"]),
+		Name:          name,
+	}, nil
+}
+
+// claimTrue reads a boolean claim, which some providers send as a string.
+func claimTrue(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
+
+// discover fills in the endpoints of an OpenID Connect provider from its
+// discovery document, once it is first used rather than at startup, so a
+// provider being down does not keep the API from starting.
+func (o *oauthClient) discover(ctx context.Context, provider *oauthProvider) error {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if provider.Issuer == "" || (provider.AuthURL != "" && provider.TokenURL != "" && provider.JWKSURL != "") {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(provider.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return err
+	}
+	var document struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+	if err := doJSON(o.httpClient, req, &document); err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+	if document.Issuer != provider.Issuer {
+		return fmt.Errorf("discovery failed: issuer is %q, expected %q", document.Issuer, provider.Issuer)
+	}
+
+	if provider.AuthURL == "" {
+		provider.AuthURL = document.AuthorizationEndpoint
+	}
+	if provider.TokenURL == "" {
+		provider.TokenURL = document.TokenEndpoint
+	}
+	if provider.JWKSURL == "" {
+		provider.JWKSURL = document.JWKSURI
+	}
+	return nil
+}
+
+// signingKey returns the issuer's key with the given ID, fetching the key set
+// again when it is unknown since providers rotate their keys.
+func (o *oauthClient) signingKey(ctx context.Context, provider *oauthProvider, kid string) (*rsa.PublicKey, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if key, ok := provider.keys[kid]; ok {
+		return key, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := doJSON(o.httpClient, req, &set); err != nil {
+		return nil, fmt.Errorf("fetching signing keys failed: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil || len(e) > 4 {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	provider.keys = keys
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// doJSON sends req and decodes the JSON response into out, even on an error
+// status, since OAuth errors come as JSON bodies.
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	decodeErr := json.Unmarshal(body, out)
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", req.URL.Host, res.Status)
+	}
+	return decodeErr
+}
+
+// githubUserInfo reads the user from GitHub's API, which has no ID tokens.
+// Only the primary address counts, and only if GitHub verified it.
+func githubUserInfo(apiURL string) func(ctx context.Context, client *http.Client, accessToken string) (*ExternalIdentity, error) {
+	return func(ctx context.Context, client *http.Client, accessToken string) (*ExternalIdentity, error) {
+		get := func(path string, out any) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+path, nil)
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Header.Set("Accept", "application/vnd.github+json")
+			return doJSON(client, req, out)
+		}
+
+		var user struct {
+			ID    int64  `json:"id"`
+			Login string `json:"login"`
+			Name  string `json:"name"`
+		}
+		if err := get("/user", &user); err != nil {
+			return nil, fmt.Errorf("fetching GitHub user failed: %w", err)
+		}
+		var emails []struct {
+			Email    string `json:"email"`
+			Primary  bool   `json:"primary"`
+			Verified bool   `json:"verified"`
+		}
+		if err := get("/user/emails", &emails); err != nil {
+			return nil, fmt.Errorf("fetching GitHub emails failed: %w", err)
+		}
+
+		identity := &ExternalIdentity{Provider: "github", Subject: strconv.FormatInt(user.ID, 10), Name: user.Name}
+		if identity.Name == "" {
+			identity.Name = user.Login
+		}
+		for _, email := range emails {
+			if email.Primary {
+				identity.Email = email.Email
+				identity.EmailVerified = email.Verified
+			}
+		}
+		return identity, nil
+	}
+}
+
+// SuccessRedirect is where the browser goes after the callback. The result
+// travels in the URL fragment, which browsers do not send to servers.
+func (o *oauthClient) SuccessRedirect(result url.Values) string {
+	return o.successURL + "#" + result.Encode()
+}
@@ -0,0 +1,331 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"cards/internal/apperrors"
+	"cards/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// mockOIDCProvider is a local OpenID Connect provider that signs in a fixed
+// user without asking: it implements discovery, the authorization endpoint,
+// the token endpoint with PKCE, and a key set for its ID tokens.
+type mockOIDCProvider struct {
+	server       *httptest.Server
+	key          *rsa.PrivateKey
+	clientID     string
+	clientSecret string
+	identity     ExternalIdentity
+
+	mu     sync.Mutex
+	grants map[string]mockOIDCGrant
+}
+
+type mockOIDCGrant struct {
+	challenge   string
+	nonce       string
+	redirectURI string
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	p := &mockOIDCProvider{
+		key:          key,
+		clientID:     "cards-test",
+		clientSecret: "client-secret",
+		identity:     ExternalIdentity{Subject: "mock-user-1", Email: "ana@example.com", EmailVerified: true, Name: "Ana"},
+		grants:       map[string]mockOIDCGrant{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *mockOIDCProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != p.clientID || query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "bad authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := uuid.NewString()
+	p.mu.Lock()
+	p.grants[code] = mockOIDCGrant{
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+		redirectURI: query.Get("redirect_uri"),
+	}
+	p.mu.Unlock()
+
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	redirect.RawQuery = url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	fail := func(code string) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": code})
+	}
+	if r.PostFormValue("client_id") != p.clientID || r.PostFormValue("client_secret") != p.clientSecret {
+		fail("invalid_client")
+		return
+	}
+
+	p.mu.Lock()
+	grant, ok := p.grants[r.PostFormValue("code")]
+	delete(p.grants, r.PostFormValue("code"))
+	p.mu.Unlock()
+	verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != grant.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != grant.challenge {
+		fail("invalid_grant")
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            p.server.URL,
+		"aud":            p.clientID,
+		"sub":            p.identity.Subject,
+		"email":          p.identity.Email,
+		"email_verified": p.identity.EmailVerified,
+		"name":           p.identity.Name,
+		"nonce":          grant.nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = "test"
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		fail("server_error")
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "id_token": idToken, "token_type": "Bearer"})
+}
+
+// TestOAuthLogin_MockProvider runs the whole sign-in flow through a browser
+// with a cookie jar, the API and the mock provider.
+func TestOAuthLogin_MockProvider(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "secret")
+	provider := newMockOIDCProvider(t)
+
+	repo := &fakeAuthRepository{
+		saveUser: func(user *models.User) error { user.ID = uuid.New(); return nil },
+	}
+	repo.findByEmail = func(email string) (*models.User, error) { return nil, gorm.ErrRecordNotFound }
+	repo.findByID = func(id string) (*models.User, error) { return repo.savedUser, nil }
+
+	router := gin.New()
+	router.Use(apperrors.Middleware())
+	api := httptest.NewServer(router)
+	t.Cleanup(api.Close)
+	oauth := newOAuthClient(
+		[]*oauthProvider{{Name: "mock", ClientID: provider.clientID, ClientSecret: provider.clientSecret, Issuer: provider.server.URL, Scopes: []string{"openid", "email"}}},
+		api.URL+"/api/v1/auth/oauth",
+		"http://app.test/oauth/callback",
+		[]byte("secret"),
+	)
+	registerAuthRoutes(router.Group("/api/v1"), NewAuthHandler(NewAuthService(repo, &fakeMailer{}), oauth), NewMiddleware(repo))
+
+	// newBrowser follows redirects until it is sent to the web app.
+	newBrowser := func(t *testing.T) *http.Client {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			t.Fatalf("failed to create cookie jar: %v", err)
+		}
+		return &http.Client{Jar: jar, CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Host == "app.test" {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		}}
+	}
+	// landing returns the result the API handed the web app.
+	landing := func(t *testing.T, res *http.Response) url.Values {
+		t.Helper()
+		location, err := url.Parse(res.Header.Get("Location"))
+		if err != nil || location.Host != "app.test" {
+			t.Fatalf("expected a redirect to the web app, got %d %q", res.StatusCode, res.Header.Get("Location"))
+		}
+		result, err := url.ParseQuery(location.Fragment)
+		if err != nil {
+			t.Fatalf("failed to parse result: %v", err)
+		}
+		return result
+	}
+	start := api.URL + "/api/v1/auth/oauth/mock/start"
+
+	t.Run("signs in", func(t *testing.T) {
+		res, err := newBrowser(t).Get(start)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		res.Body.Close()
+
+		result := landing(t, res)
+		if result.Get("error") != "" {
+			t.Fatalf("expected sign-in to succeed, got %q", result.Get("error"))
+		}
+		if _, err := ValidateJWTToken(result.Get("token")); err != nil {
+			t.Fatalf("expected a valid access token, got %v", err)
+		}
+		if result.Get("refresh_token") == "" {
+			t.Fatalf("expected a refresh token")
+		}
+		if len(repo.identities) != 1 || repo.identities[0].Provider != "mock" || repo.identities[0].Subject != provider.identity.Subject {
+			t.Fatalf("expected the mock identity linked, got %+v", repo.identities)
+		}
+	})
+
+	t.Run("refuses a flow another browser started", func(t *testing.T) {
+		// The victim's browser is sent to the callback the attacker started.
+		attacker := newBrowser(t)
+		attacker.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
+		res, err := attacker.Get(start)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		res.Body.Close()
+
+		res, err = newBrowser(t).Get(res.Header.Get("Location"))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		res.Body.Close()
+		if result := landing(t, res); result.Get("error") == "" || result.Get("token") != "" {
+			t.Fatalf("expected sign-in to fail, got %v", result)
+		}
+	})
+
+	t.Run("refuses a mismatched state", func(t *testing.T) {
+		browser := newBrowser(t)
+		browser.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if req.URL.Path == "/api/v1/auth/oauth/mock/callback" {
+				query := req.URL.Query()
+				query.Set("state", "forged")
+				req.URL.RawQuery = query.Encode()
+			}
+			if req.URL.Host == "app.test" {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		}
+		res, err := browser.Get(start)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		res.Body.Close()
+		if result := landing(t, res); result.Get("error") == "" {
+			t.Fatalf("expected sign-in to fail, got %v", result)
+		}
+	})
+
+	t.Run("unknown providers are not found", func(t *testing.T) {
+		res, err := newBrowser(t).Get(api.URL + "/api/v1/auth/oauth/nope/start")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", res.StatusCode)
+		}
+	})
+}
+
+func TestOAuthClient_VerifyIDToken(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	mock := &oauthProvider{Name: "mock", ClientID: provider.clientID, Issuer: provider.server.URL}
+	client := newOAuthClient([]*oauthProvider{mock}, "http://api.test", "http://app.test", []byte("secret"))
+	if err := client.discover(t.Context(), mock); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	sign := func(claims jwt.MapClaims, key *rsa.PrivateKey) string {
+		base := jwt.MapClaims{
+			"iss":   provider.server.URL,
+			"aud":   provider.clientID,
+			"sub":   "mock-user-1",
+			"nonce": "nonce",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}
+		for name, value := range claims {
+			base[name] = value
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, base)
+		token.Header["kid"] = "test"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return signed
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	if _, err := client.verifyIDToken(t.Context(), mock, sign(nil, provider.key), "nonce"); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"wrong signature", sign(nil, otherKey)},
+		{"wrong issuer", sign(jwt.MapClaims{"iss": "https://evil.test"}, provider.key)},
+		{"wrong audience", sign(jwt.MapClaims{"aud": "someone-else"}, provider.key)},
+		{"wrong nonce", sign(jwt.MapClaims{"nonce": "replayed"}, provider.key)},
+		{"expired", sign(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}, provider.key)},
+		{"no subject", sign(jwt.MapClaims{"sub": ""}, provider.key)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.verifyIDToken(t.Context(), mock, tt.token, "nonce"); err == nil {
+				t.Fatalf("expected the ID token to be refused")
+			}
+		})
+	}
+}
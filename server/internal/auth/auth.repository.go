@@ -1,8 +1,11 @@
 package auth
 
 import (
+	"time"
+
 	"cards/internal/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -10,6 +13,33 @@ type AuthRepository interface {
 	FindUserByEmail(email string) (*models.User, error)
 	SaveUser(user *models.User) error
 	FindUserByID(id string) (*models.User, error)
+	CreateSession(session *models.Session) error
+	FindSessionByID(id uuid.UUID) (*models.Session, error)
+	RotateSession(id uuid.UUID, fromHash string, toHash string) (bool, error)
+	RevokeSession(userID uuid.UUID, id uuid.UUID, at time.Time) error
+	RevokeUserSessions(userID uuid.UUID, at time.Time) error
+	CreatePasswordReset(reset *models.PasswordReset) error
+	FindPasswordResetByTokenHash(tokenHash string) (*models.PasswordReset, error)
+	ResetPassword(reset models.PasswordReset, passwordHash string, at time.Time) (bool, error)
+	CreateEmailVerification(verification *models.EmailVerification) error
+	FindEmailVerificationByTokenHash(tokenHash string) (*models.EmailVerification, error)
+	VerifyEmail(verification models.EmailVerification, at time.Time) (bool, error)
+	FindTOTPFactor(userID uuid.UUID) (*models.TOTPFactor, error)
+	ReplaceTOTPFactor(factor *models.TOTPFactor) error
+	ConfirmTOTPFactor(userID uuid.UUID, step int64, at time.Time, recoveryCodeHashes []string) (bool, error)
+	UseTOTPStep(userID uuid.UUID, step int64) (bool, error)
+	UseRecoveryCode(userID uuid.UUID, codeHash string, at time.Time) (bool, error)
+	DeleteTOTPFactor(userID uuid.UUID) error
+	FindUserIdentity(provider string, subject string) (*models.UserIdentity, error)
+	CreateUserIdentity(identity *models.UserIdentity) error
+	CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error
+	CreatePersonalAccessToken(token *models.PersonalAccessToken) error
+	ListPersonalAccessTokens(userID uuid.UUID, now time.Time) ([]models.PersonalAccessToken, error)
+	RevokePersonalAccessToken(userID uuid.UUID, id uuid.UUID, at time.Time) (bool, error)
+	FindPersonalAccessTokenByHash(tokenHash string) (*models.PersonalAccessToken, error)
+	TouchPersonalAccessToken(id uuid.UUID, at time.Time) error
+	SetUserDisabled(userID uuid.UUID, disabledAt *time.Time) error
+	RequirePasswordReset(userID uuid.UUID, at time.Time) error
 }
 
 type authRepository struct {
@@ -20,9 +50,11 @@ func NewAuthRepository(db *gorm.DB) AuthRepository {
 	return &authRepository{db: db}
 }
 
+// FindUserByEmail ignores case, since addresses are stored as the user typed
+// them.
 func (r *authRepository) FindUserByEmail(email string) (*models.User, error) {
 	var user models.User
-	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.db.Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
 		return nil, err
 	}
 
@@ -41,3 +73,317 @@ func (r *authRepository) FindUserByID(id string) (*models.User, error) {
 func (r *authRepository) SaveUser(user *models.User) error {
 	return r.db.Save(user).Error
 }
+
+func (r *authRepository) CreateSession(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *authRepository) FindSessionByID(id uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// RotateSession swaps the refresh token hash of an active session, but only if
+// it still is fromHash. It reports false when another refresh got there first.
+func (r *authRepository) RotateSession(id uuid.UUID, fromHash string, toHash string) (bool, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", id, fromHash).
+		Update("refresh_token_hash", toHash)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *authRepository) RevokeSession(userID uuid.UUID, id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at).Error
+}
+
+func (r *authRepository) RevokeUserSessions(userID uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
+
+func (r *authRepository) CreatePasswordReset(reset *models.PasswordReset) error {
+	return r.db.Create(reset).Error
+}
+
+func (r *authRepository) FindPasswordResetByTokenHash(tokenHash string) (*models.PasswordReset, error) {
+	var reset models.PasswordReset
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&reset).Error; err != nil {
+		return nil, err
+	}
+
+	return &reset, nil
+}
+
+// ResetPassword redeems the reset and sets the new password in one
+// transaction. It also voids the user's other resets, signs them out
+// everywhere and revokes their personal access tokens, so that a reset after
+// a compromise locks the attacker out. It reports false if the reset was
+// redeemed concurrently.
+func (r *authRepository) ResetPassword(reset models.PasswordReset, passwordHash string, at time.Time) (bool, error) {
+	redeemed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PasswordReset{}).
+			Where("id = ? AND used_at IS NULL", reset.ID).
+			Update("used_at", at)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		// UpdateColumns skips the User hooks, which would hash the hash again.
+		if err := tx.Model(&models.User{}).Where("id = ?", reset.UserID).UpdateColumns(map[string]any{
+			"password":                passwordHash,
+			"password_reset_required": false,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PasswordReset{}).
+			Where("user_id = ? AND used_at IS NULL", reset.UserID).
+			Update("used_at", at).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", reset.UserID).
+			Update("revoked_at", at).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PersonalAccessToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", reset.UserID).
+			Update("revoked_at", at).Error; err != nil {
+			return err
+		}
+
+		redeemed = true
+		return nil
+	})
+	return redeemed, err
+}
+
+func (r *authRepository) CreateEmailVerification(verification *models.EmailVerification) error {
+	return r.db.Create(verification).Error
+}
+
+func (r *authRepository) FindEmailVerificationByTokenHash(tokenHash string) (*models.EmailVerification, error) {
+	var verification models.EmailVerification
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&verification).Error; err != nil {
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+// VerifyEmail redeems the verification and marks the user's email verified
+// in one transaction, voiding the user's other verifications. It reports
+// false if the verification was redeemed concurrently.
+func (r *authRepository) VerifyEmail(verification models.EmailVerification, at time.Time) (bool, error) {
+	redeemed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.EmailVerification{}).
+			Where("id = ? AND used_at IS NULL", verification.ID).
+			Update("used_at", at)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		// UpdateColumn skips the User hooks, which would hash the password again.
+		if err := tx.Model(&models.User{}).
+			Where("id = ? AND email_verified_at IS NULL", verification.UserID).
+			UpdateColumn("email_verified_at", at).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.EmailVerification{}).
+			Where("user_id = ? AND used_at IS NULL", verification.UserID).
+			Update("used_at", at).Error; err != nil {
+			return err
+		}
+
+		redeemed = true
+		return nil
+	})
+	return redeemed, err
+}
+
+func (r *authRepository) FindTOTPFactor(userID uuid.UUID) (*models.TOTPFactor, error) {
+	var factor models.TOTPFactor
+	if err := r.db.Where("user_id = ?", userID).First(&factor).Error; err != nil {
+		return nil, err
+	}
+
+	return &factor, nil
+}
+
+// ReplaceTOTPFactor swaps any unconfirmed factor of the user for factor. A
+// confirmed one is left alone, and the insert then fails on the unique index.
+func (r *authRepository) ReplaceTOTPFactor(factor *models.TOTPFactor) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND confirmed_at IS NULL", factor.UserID).
+			Delete(&models.TOTPFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(factor).Error
+	})
+}
+
+// ConfirmTOTPFactor turns two-factor authentication on with the first code's
+// step and a fresh set of recovery codes. It reports false if the factor was
+// confirmed or replaced concurrently.
+func (r *authRepository) ConfirmTOTPFactor(userID uuid.UUID, step int64, at time.Time, recoveryCodeHashes []string) (bool, error) {
+	confirmed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.TOTPFactor{}).
+			Where("user_id = ? AND confirmed_at IS NULL", userID).
+			Updates(map[string]any{"confirmed_at": at, "last_used_step": step})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, len(recoveryCodeHashes))
+		for i, hash := range recoveryCodeHashes {
+			codes[i] = models.RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		if err := tx.Create(&codes).Error; err != nil {
+			return err
+		}
+
+		confirmed = true
+		return nil
+	})
+	return confirmed, err
+}
+
+// UseTOTPStep records that a code of step was accepted. It reports false if
+// a code of that step or a later one already was, so codes cannot be replayed.
+func (r *authRepository) UseTOTPStep(userID uuid.UUID, step int64) (bool, error) {
+	result := r.db.Model(&models.TOTPFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// UseRecoveryCode redeems one of the user's recovery codes. It reports false
+// if there is no such unused code.
+func (r *authRepository) UseRecoveryCode(userID uuid.UUID, codeHash string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *authRepository) DeleteTOTPFactor(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.TOTPFactor{}).Error
+	})
+}
+
+func (r *authRepository) FindUserIdentity(provider string, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+func (r *authRepository) CreateUserIdentity(identity *models.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// CreateUserWithIdentity signs up a user who arrived through a sign-in
+// provider, so there is never a user without the identity that created it.
+func (r *authRepository) CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+func (r *authRepository) CreatePersonalAccessToken(token *models.PersonalAccessToken) error {
+	return r.db.Create(token).Error
+}
+
+// ListPersonalAccessTokens returns the user's tokens that can still be used,
+// newest first.
+func (r *authRepository) ListPersonalAccessTokens(userID uuid.UUID, now time.Time) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokePersonalAccessToken reports false if the user has no such token that
+// is not revoked already.
+func (r *authRepository) RevokePersonalAccessToken(userID uuid.UUID, id uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *authRepository) FindPersonalAccessTokenByHash(tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *authRepository) TouchPersonalAccessToken(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.PersonalAccessToken{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
+
+func (r *authRepository) SetUserDisabled(userID uuid.UUID, disabledAt *time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).UpdateColumn("disabled_at", disabledAt).Error
+}
+
+// RequirePasswordReset locks the user's password, signs them out and revokes
+// their personal access tokens, so only a reset link gets them back in.
+func (r *authRepository) RequirePasswordReset(userID uuid.UUID, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).UpdateColumn("password_reset_required", true).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PersonalAccessToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", at).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", at).Error
+	})
+}
@@ -1,17 +1,46 @@
 package auth
 
 import (
+	"cards/internal/mail"
+	"log"
+
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func RegisterAuthRoutes(appGroup *gin.RouterGroup, db *gorm.DB) {
+func RegisterAuthRoutes(appGroup *gin.RouterGroup, db *gorm.DB, mailer mail.Mailer, authMiddleware Middleware) {
+	oauth, err := newOAuthClientFromEnv()
+	if err != nil {
+		log.Fatalf("Sign-in provider setup failed: %v", err)
+	}
+
 	repository := NewAuthRepository(db)
-	service := NewAuthService(repository)
-	handler := NewAuthHandler(service)
+	service := NewAuthService(repository, mailer)
+	handler := NewAuthHandler(service, oauth)
+
+	registerAuthRoutes(appGroup, handler, authMiddleware)
+}
 
+func registerAuthRoutes(appGroup *gin.RouterGroup, handler AuthHandler, authMiddleware Middleware) {
 	authGroup := appGroup.Group("/auth")
 	authGroup.POST("/register", handler.Register)
 	authGroup.POST("/login", handler.Login)
-	authGroup.GET("/me", AuthMiddleware(), handler.Me)
+	authGroup.POST("/login/mfa", handler.CompleteMFALogin)
+	authGroup.GET("/oauth/providers", handler.OAuthProviders)
+	authGroup.GET("/oauth/:provider/start", handler.OAuthStart)
+	authGroup.GET("/oauth/:provider/callback", handler.OAuthCallback)
+	authGroup.POST("/refresh", handler.Refresh)
+	authGroup.POST("/forgot_password", handler.ForgotPassword)
+	authGroup.POST("/reset_password", handler.ResetPassword)
+	authGroup.POST("/verify_email", handler.VerifyEmail)
+	authGroup.POST("/resend_verification", authMiddleware.Authenticate(), handler.ResendVerification)
+	authGroup.POST("/logout", authMiddleware.Authenticate(), handler.Logout)
+	authGroup.POST("/logout_all", authMiddleware.Authenticate(), handler.LogoutAll)
+	authGroup.GET("/me", authMiddleware.Authenticate(), handler.Me)
+	authGroup.POST("/mfa/enroll", authMiddleware.Authenticate(), handler.EnrollTOTP)
+	authGroup.POST("/mfa/confirm", authMiddleware.Authenticate(), handler.ConfirmTOTP)
+	authGroup.POST("/mfa/disable", authMiddleware.Authenticate(), handler.DisableTOTP)
+	authGroup.POST("/tokens", authMiddleware.Authenticate(), handler.CreatePersonalAccessToken)
+	authGroup.GET("/tokens", authMiddleware.Authenticate(), handler.ListPersonalAccessTokens)
+	authGroup.DELETE("/tokens/:tokenID", authMiddleware.Authenticate(), handler.RevokePersonalAccessToken)
 }
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cards/internal/apperrors"
+	"cards/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// TestAuthRoutes_BadIDs sends malformed IDs to every auth route. None of
+// them may reach the repository with an ID it cannot parse.
+func TestAuthRoutes_BadIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "secret")
+
+	userID := uuid.New()
+	newRouter := func(t *testing.T) *gin.Engine {
+		repo := &fakeAuthRepository{
+			findByID: func(id string) (*models.User, error) {
+				if _, err := uuid.Parse(id); err != nil {
+					t.Errorf("repository got malformed user ID %q", id)
+				}
+				return &models.User{Base: models.Base{ID: userID}}, nil
+			},
+		}
+		router := gin.New()
+		router.Use(apperrors.Middleware())
+		registerAuthRoutes(router.Group("/api/v1"), NewAuthHandler(NewAuthService(repo, &fakeMailer{}), newOAuthClient(nil, "http://api.test/api/v1/auth/oauth", "http://app.test/oauth/callback", []byte("secret"))), NewMiddleware(repo))
+		return router
+	}
+
+	tests := []struct {
+		method string
+		route  string
+		body   string
+		sub    string
+		want   int
+	}{
+		// Registering and logging in take no IDs; an incomplete payload is
+		// still rejected before reaching the repository.
+		{method: "POST", route: "/auth/register", body: `{}`, want: http.StatusBadRequest},
+		{method: "POST", route: "/auth/login", body: `{}`, want: http.StatusBadRequest},
+		{method: "POST", route: "/auth/login/mfa", body: `{"mfa_token":"not-a-token","code":"123456"}`, want: http.StatusUnauthorized},
+		// Sign-in providers are named, not identified by UUIDs.
+		{method: "GET", route: "/auth/oauth/providers", want: http.StatusOK},
+		{method: "GET", route: "/auth/oauth/:provider/start", want: http.StatusNotFound},
+		{method: "GET", route: "/auth/oauth/:provider/callback", want: http.StatusNotFound},
+		{method: "POST", route: "/auth/refresh", body: `{"refresh_token":"not-a-uuid.secret"}`, want: http.StatusUnauthorized},
+		{method: "POST", route: "/auth/forgot_password", body: `{}`, want: http.StatusBadRequest},
+		{method: "POST", route: "/auth/reset_password", body: `{"token":"not-a-token","password":"long-enough"}`, want: http.StatusBadRequest},
+		{method: "POST", route: "/auth/verify_email", body: `{"token":"not-a-token"}`, want: http.StatusBadRequest},
+		{method: "POST", route: "/auth/resend_verification", sub: "not-a-uuid", want: http.StatusUnauthorized},
+		{method: "POST", route: "/auth/logout", sub: "not-a-uuid", want: http.StatusUnauthorized},
+		{method: "POST", route: "/auth/logout_all", sub: "not-a-uuid", want: http.StatusUnauthorized},
+		{method: "POST", route: "/auth/mfa/enroll", sub: "not-a-uuid", want: http.StatusUnauthorized},
+		{method: "POST", route: "/auth/mfa/confirm", sub: "not-a-uuid", want: http.StatusUnauthorized},
+		{method: "POST", route: "/auth/mfa/disable", sub: "not-a-uuid", want: http.StatusUnauthorized},
+		{method: "POST", route: "/auth/tokens", sub: "not-a-uuid", want: http.StatusUnauthorized},
+		{method: "GET", route: "/auth/tokens", sub: "not-a-uuid", want: http.StatusUnauthorized},
+		{method: "DELETE", route: "/auth/tokens/:tokenID", sub: "not-a-uuid", want: http.StatusUnauthorized},
+		{method: "DELETE", route: "/auth/tokens/:tokenID", sub: userID.String(), want: http.StatusBadRequest},
+		{method: "GET", route: "/auth/me", sub: "not-a-uuid", want: http.StatusUnauthorized},
+		{method: "GET", route: "/auth/me", sub: userID.String(), want: http.StatusOK},
+	}
+
+	t.Run("covers every route", func(t *testing.T) {
+		covered := map[string]bool{}
+		for _, tt := range tests {
+			covered[tt.method+" /api/v1"+tt.route] = true
+		}
+		for _, route := range newRouter(t).Routes() {
+			if !covered[route.Method+" "+route.Path] {
+				t.Errorf("route %s %s has no bad ID test", route.Method, route.Path)
+			}
+		}
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.route+" "+tt.sub, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1"+tt.route, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.sub != "" {
+				token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+					"sub": tt.sub,
+					"exp": time.Now().Add(time.Hour).Unix(),
+				}).SignedString([]byte("secret"))
+				if err != nil {
+					t.Fatalf("failed to sign token: %v", err)
+				}
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			w := httptest.NewRecorder()
+			newRouter(t).ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestAuthRoutes_NoPasswordMaterial registers, logs in and fetches the user
+// through the handlers. No response may carry a password or its hash.
+func TestAuthRoutes_NoPasswordMaterial(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "secret")
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("login password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	user := &models.User{Base: models.Base{ID: uuid.New()}, Name: "Ana", Email: "ana@example.com", Password: string(hash)}
+	repo := &fakeAuthRepository{
+		findByEmail: func(email string) (*models.User, error) {
+			if email == user.Email {
+				return user, nil
+			}
+			return nil, gorm.ErrRecordNotFound
+		},
+		findByID: func(id string) (*models.User, error) { return user, nil },
+		saveUser: func(saved *models.User) error {
+			saved.ID = uuid.New()
+			return nil
+		},
+	}
+	router := gin.New()
+	router.Use(apperrors.Middleware())
+	registerAuthRoutes(router.Group("/api/v1"), NewAuthHandler(NewAuthService(repo, &fakeMailer{}), newOAuthClient(nil, "http://api.test/api/v1/auth/oauth", "http://app.test/oauth/callback", []byte("secret"))), NewMiddleware(repo))
+
+	serve := func(t *testing.T, method, route, body, token string, want int) string {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/v1"+route, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Fatalf("%s %s: expected status %d, got %d: %s", method, route, want, w.Code, w.Body.String())
+		}
+		res := w.Body.String()
+		for _, secret := range []string{`"password"`, string(hash), "login password", "registered password"} {
+			if strings.Contains(res, secret) {
+				t.Fatalf("%s %s: response contains password material %q: %s", method, route, secret, res)
+			}
+		}
+		return res
+	}
+
+	serve(t, "POST", "/auth/register", `{"name":"Bia","email":"bia@example.com","password":"registered password"}`, "", http.StatusCreated)
+
+	login := serve(t, "POST", "/auth/login", `{"email":"ana@example.com","password":"login password"}`, "", http.StatusOK)
+	var res struct {
+		Data LoginResponseDTO `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(login), &res); err != nil || res.Data.TokenPairDTO == nil {
+		t.Fatalf("expected tokens from login, got %s", login)
+	}
+
+	serve(t, "GET", "/auth/me", "", res.Data.Token, http.StatusOK)
+}
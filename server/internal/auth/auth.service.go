@@ -1,36 +1,162 @@
 package auth
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
+	"cards/internal/apperrors"
+	"cards/internal/mail"
 	"cards/internal/models"
 )
 
+const (
+	defaultAccessTokenTTLMinutes = 15
+	defaultRefreshTokenTTLDays   = 30
+	defaultPasswordResetURL      = "http://localhost:5173/reset-password"
+	defaultEmailVerificationURL  = "http://localhost:5173/verify-email"
+	defaultMFAIssuer             = "Cards"
+
+	passwordResetTTL    = time.Hour
+	passwordResetLimit  = 3
+	passwordResetWindow = time.Hour
+
+	emailVerificationTTL    = 48 * time.Hour
+	emailVerificationLimit  = 3
+	emailVerificationWindow = time.Hour
+
+	personalAccessTokenPrefix     = "cards_pat_"
+	defaultPersonalTokenValidDays = 90
+
+	mfaChallengeTTL  = 5 * time.Minute
+	mfaAttemptLimit  = 5
+	mfaAttemptWindow = 5 * time.Minute
+
+	mailTimeout = 30 * time.Second
+)
+
+var (
+	ErrEmailTaken          = apperrors.Conflict("email already registered")
+	ErrUserNotFound        = apperrors.Unauthorized("user not found")
+	ErrInvalidCredentials  = apperrors.Unauthorized("invalid credentials")
+	ErrInvalidRefreshToken = apperrors.Unauthorized("invalid refresh token")
+	ErrRefreshTokenReused  = apperrors.Unauthorized("refresh token was already used; session revoked")
+	ErrInvalidResetToken   = apperrors.Validation("invalid or expired reset token")
+	ErrTooManyResetEmails  = apperrors.RateLimited("too many password reset requests; try again later")
+
+	ErrInvalidVerificationToken  = apperrors.Validation("invalid or expired verification token")
+	ErrEmailAlreadyVerified      = apperrors.Conflict("email already verified")
+	ErrTooManyVerificationEmails = apperrors.RateLimited("too many verification emails; try again later")
+
+	ErrInvalidMFAToken    = apperrors.Unauthorized("invalid or expired two-factor challenge")
+	ErrInvalidMFACode     = apperrors.Validation("invalid authentication code")
+	ErrTooManyMFAAttempts = apperrors.RateLimited("too many authentication codes tried; try again later")
+	ErrMFAAlreadyEnabled  = apperrors.Conflict("two-factor authentication is already enabled")
+	ErrMFANotEnrolled     = apperrors.Conflict("start two-factor enrollment first")
+	ErrMFANotEnabled      = apperrors.Conflict("two-factor authentication is not enabled")
+
+	ErrOAuthNoEmail         = apperrors.Validation("the sign-in provider did not share an email address")
+	ErrOAuthEmailUnverified = apperrors.Conflict("an account with this email exists, but the sign-in provider has not verified the email")
+	ErrOAuthLinkUnverified  = apperrors.Conflict("an account with this email exists; sign in with your password and verify your email to link it")
+
+	ErrPersonalAccessTokenNotFound = apperrors.NotFound("personal access token not found")
+
+	ErrAccountDisabled       = apperrors.Forbidden("account is disabled")
+	ErrPasswordResetRequired = apperrors.Forbidden("password reset required; check your email for a reset link")
+)
+
 type AuthService interface {
 	Register(input RegisterRequestDTO) (*models.User, error)
 	Login(email, password string) (*LoginResponseDTO, error)
-	GetUser(id string) (*models.User, error)
+	CompleteMFALogin(mfaToken string, code string) (*LoginResponseDTO, error)
+	OAuthLogin(identity ExternalIdentity) (*LoginResponseDTO, error)
+	Refresh(refreshToken string) (*TokenPairDTO, error)
+	Logout(userID uuid.UUID, sessionID uuid.UUID) error
+	LogoutAll(userID uuid.UUID) error
+	ForgotPassword(email string) error
+	ResetPassword(token string, password string) error
+	VerifyEmail(token string) error
+	ResendVerification(userID uuid.UUID) error
+	EnrollTOTP(userID uuid.UUID) (*TOTPEnrollmentDTO, error)
+	ConfirmTOTP(userID uuid.UUID, code string) (*RecoveryCodesDTO, error)
+	DisableTOTP(userID uuid.UUID, code string) error
+	CreatePersonalAccessToken(userID uuid.UUID, input CreatePersonalAccessTokenRequestDTO) (*PersonalAccessTokenCreatedDTO, error)
+	ListPersonalAccessTokens(userID uuid.UUID) ([]PersonalAccessTokenResponseDTO, error)
+	RevokePersonalAccessToken(userID uuid.UUID, tokenID uuid.UUID) error
+	SetUserDisabled(userID uuid.UUID, disabled bool) error
+	ForcePasswordReset(userID uuid.UUID) error
+	GetUser(id string) (*UserResponseDTO, error)
 }
 
 type authService struct {
-	repository AuthRepository
-	jwtSecret  []byte
+	repository           AuthRepository
+	mailer               mail.Mailer
+	jwtSecret            []byte
+	accessTokenTTL       time.Duration
+	refreshTokenTTL      time.Duration
+	passwordResetURL     string
+	resetLimiter         *attemptLimiter
+	emailVerificationURL string
+	verificationLimiter  *attemptLimiter
+	mfaIssuer            string
+	mfaLimiter           *attemptLimiter
+	now                  func() time.Time
 }
 
-func NewAuthService(repository AuthRepository) AuthService {
+func NewAuthService(repository AuthRepository, mailer mail.Mailer) AuthService {
 	secret := os.Getenv("JWT_SECRET")
-	return &authService{repository: repository, jwtSecret: []byte(secret)}
+	return &authService{
+		repository:           repository,
+		mailer:               mailer,
+		jwtSecret:            []byte(secret),
+		accessTokenTTL:       time.Duration(envInt("ACCESS_TOKEN_TTL_MINUTES", defaultAccessTokenTTLMinutes)) * time.Minute,
+		refreshTokenTTL:      time.Duration(envInt("REFRESH_TOKEN_TTL_DAYS", defaultRefreshTokenTTLDays)) * 24 * time.Hour,
+		passwordResetURL:     envString("PASSWORD_RESET_URL", defaultPasswordResetURL),
+		resetLimiter:         newAttemptLimiter(passwordResetLimit, passwordResetWindow),
+		emailVerificationURL: envString("EMAIL_VERIFICATION_URL", defaultEmailVerificationURL),
+		verificationLimiter:  newAttemptLimiter(emailVerificationLimit, emailVerificationWindow),
+		mfaIssuer:            envString("MFA_ISSUER", defaultMFAIssuer),
+		mfaLimiter:           newAttemptLimiter(mfaAttemptLimit, mfaAttemptWindow),
+		now:                  time.Now,
+	}
+}
+
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func envString(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
 }
 
 func (s *authService) Register(input RegisterRequestDTO) (*models.User, error) {
 	_, err := s.repository.FindUserByEmail(input.Email)
 	if err == nil {
-		return nil, errors.New("email already registered")
+		return nil, ErrEmailTaken
 	}
 
 	user := models.User{
@@ -42,22 +168,657 @@ func (s *authService) Register(input RegisterRequestDTO) (*models.User, error) {
 		return nil, err
 	}
 
+	// The account exists either way; a lost email can be sent again.
+	if err := s.sendVerification(&user); err != nil {
+		log.Printf("verification email for user %s failed: %v", user.ID, err)
+	}
+
 	return &user, nil
 }
 
+// Login checks the credentials and starts a session, returning a short-lived
+// access token and the session's first refresh token. Users with two-factor
+// authentication get a challenge token instead, for CompleteMFALogin.
 func (s *authService) Login(email, password string) (*LoginResponseDTO, error) {
+	// Unknown emails fail like wrong passwords, so that logging in does not
+	// tell who has an account any more than ForgotPassword does.
 	user, err := s.repository.FindUserByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, err
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
+	}
+	if err := checkPasswordReset(user); err != nil {
+		return nil, err
+	}
+
+	return s.loginAs(user)
+}
+
+// checkPasswordReset keeps a user an admin forced to reset their password out
+// until they do, whichever way they sign in. A disabled account says so in
+// loginAs rather than sending the user to reset a password that would not let
+// them in.
+func checkPasswordReset(user *models.User) error {
+	if user.PasswordResetRequired && !user.Disabled() {
+		return ErrPasswordResetRequired
 	}
+	return nil
+}
 
+// loginAs starts a session for a user whose first factor checked out, or
+// asks for the second one.
+func (s *authService) loginAs(user *models.User) (*LoginResponseDTO, error) {
+	if user.Disabled() {
+		return nil, ErrAccountDisabled
+	}
+
+	factor, err := s.repository.FindTOTPFactor(user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && factor.Confirmed() {
+		challenge, err := s.issueMFAChallenge(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResponseDTO{MFARequired: true, MFAToken: challenge}, nil
+	}
+
+	return s.startSession(user, false)
+}
+
+// CompleteMFALogin finishes a login Login answered with a challenge, given a
+// code from the user's authenticator app or a recovery code.
+func (s *authService) CompleteMFALogin(mfaToken string, code string) (*LoginResponseDTO, error) {
+	userID, err := s.parseMFAChallenge(mfaToken)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	factor, err := s.repository.FindTOTPFactor(userID)
+	if err != nil || !factor.Confirmed() {
+		return nil, ErrInvalidMFAToken
+	}
+	if err := s.checkSecondFactor(*factor, code); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repository.FindUserByID(userID.String())
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Disabled() {
+		return nil, ErrAccountDisabled
+	}
+	if err := checkPasswordReset(user); err != nil {
+		return nil, err
+	}
+	return s.startSession(user, true)
+}
+
+// OAuthLogin signs in the user a sign-in provider vouched for, like Login
+// does for a password. A new identity is linked to the account with the same
+// email if both the provider and the account have verified it; otherwise a
+// new account is created.
+func (s *authService) OAuthLogin(identity ExternalIdentity) (*LoginResponseDTO, error) {
+	linked, err := s.repository.FindUserIdentity(identity.Provider, identity.Subject)
+	if err == nil {
+		user, err := s.repository.FindUserByID(linked.UserID.String())
+		if err != nil {
+			return nil, ErrUserNotFound
+		}
+		if err := checkPasswordReset(user); err != nil {
+			return nil, err
+		}
+		return s.loginAs(user)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" {
+		return nil, ErrOAuthNoEmail
+	}
+	link := models.UserIdentity{Provider: identity.Provider, Subject: identity.Subject, Email: identity.Email}
+
+	existing, err := s.repository.FindUserByEmail(identity.Email)
+	if err == nil {
+		// Linking on an unverified email on either side would hand the
+		// account to whoever claimed the address first.
+		if !identity.EmailVerified {
+			return nil, ErrOAuthEmailUnverified
+		}
+		if !existing.EmailVerified() {
+			return nil, ErrOAuthLinkUnverified
+		}
+		if err := checkPasswordReset(existing); err != nil {
+			return nil, err
+		}
+		link.UserID = existing.ID
+		if err := s.repository.CreateUserIdentity(&link); err != nil {
+			return nil, err
+		}
+		return s.loginAs(existing)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// The account has no usable password until the user resets one.
+	password, err := newSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	user := models.User{Name: identity.Name, Email: identity.Email, Password: password}
+	if user.Name == "" {
+		user.Name, _, _ = strings.Cut(identity.Email, "@")
+	}
+	if identity.EmailVerified {
+		verifiedAt := s.now()
+		user.EmailVerifiedAt = &verifiedAt
+	}
+	if err := s.repository.CreateUserWithIdentity(&user, &link); err != nil {
+		return nil, err
+	}
+	if !identity.EmailVerified {
+		if err := s.sendVerification(&user); err != nil {
+			log.Printf("verification email for user %s failed: %v", user.ID, err)
+		}
+	}
+
+	return s.loginAs(&user)
+}
+
+func (s *authService) startSession(user *models.User, mfaEnabled bool) (*LoginResponseDTO, error) {
+	secret, err := newSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	session := models.Session{
+		Base:             models.Base{ID: uuid.New()},
+		UserID:           user.ID,
+		RefreshTokenHash: hashSecretToken(secret),
+		ExpiresAt:        s.now().Add(s.refreshTokenTTL),
+	}
+	if err := s.repository.CreateSession(&session); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.issueTokens(session, secret, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	res := newUserResponse(user, mfaEnabled)
+	return &LoginResponseDTO{TokenPairDTO: tokens, User: &res}, nil
+}
+
+// Refresh trades a refresh token for a new access token and rotates it. A
+// token that was already traded means it has been copied, so the session is
+// revoked for whoever holds it.
+func (s *authService) Refresh(refreshToken string) (*TokenPairDTO, error) {
+	sessionID, secret, ok := parseRefreshToken(refreshToken)
+	if !ok {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := s.repository.FindSessionByID(sessionID)
+	if err != nil || !session.Active(s.now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+	user, err := s.repository.FindUserByID(session.UserID.String())
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if user.Disabled() {
+		return nil, ErrAccountDisabled
+	}
+
+	currentHash := hashSecretToken(secret)
+	if subtle.ConstantTimeCompare([]byte(currentHash), []byte(session.RefreshTokenHash)) != 1 {
+		return nil, s.revokeReused(*session)
+	}
+
+	nextSecret, err := newSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := s.repository.RotateSession(session.ID, currentHash, hashSecretToken(nextSecret))
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, s.revokeReused(*session)
+	}
+
+	return s.issueTokens(*session, nextSecret, user.Role)
+}
+
+func (s *authService) revokeReused(session models.Session) error {
+	if err := s.repository.RevokeSession(session.UserID, session.ID, s.now()); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Logout revokes the session the access token belongs to. Tokens issued
+// before sessions existed carry none and simply run out.
+func (s *authService) Logout(userID uuid.UUID, sessionID uuid.UUID) error {
+	if sessionID == uuid.Nil {
+		return nil
+	}
+	return s.repository.RevokeSession(userID, sessionID, s.now())
+}
+
+func (s *authService) LogoutAll(userID uuid.UUID) error {
+	return s.repository.RevokeUserSessions(userID, s.now())
+}
+
+// ForgotPassword emails a reset link if the address belongs to a user. It
+// answers the same either way, and mails in the background so the response
+// time does not depend on the mail server either; both would reveal which
+// addresses have accounts.
+func (s *authService) ForgotPassword(email string) error {
+	// The same address, spaced or cased differently, shares one limit.
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !s.resetLimiter.Allow(email, s.now()) {
+		return ErrTooManyResetEmails
+	}
+
+	user, err := s.repository.FindUserByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.Disabled() {
+		return nil
+	}
+
+	return s.sendPasswordReset(user, "If you did not ask for this, you can ignore this email.")
+}
+
+func (s *authService) sendPasswordReset(user *models.User, footer string) error {
+	token, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+	reset := models.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hashSecretToken(token),
+		ExpiresAt: s.now().Add(passwordResetTTL),
+	}
+	if err := s.repository.CreatePasswordReset(&reset); err != nil {
+		return err
+	}
+
+	s.sendInBackground(user.ID, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes and works once.\n\n%s?token=%s\n\n%s\n",
+			user.Name, int(passwordResetTTL.Minutes()), s.passwordResetURL, url.QueryEscape(token), footer,
+		),
+	})
+
+	return nil
+}
+
+// ResetPassword redeems a reset token for a new password and signs the user
+// out of every session.
+func (s *authService) ResetPassword(token string, password string) error {
+	reset, err := s.repository.FindPasswordResetByTokenHash(hashSecretToken(token))
+	if err != nil || !reset.Usable(s.now()) {
+		return ErrInvalidResetToken
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	redeemed, err := s.repository.ResetPassword(*reset, string(hash), s.now())
+	if err != nil {
+		return err
+	}
+	if !redeemed {
+		return ErrInvalidResetToken
+	}
+	return nil
+}
+
+// VerifyEmail redeems a verification token, marking the user's email
+// verified.
+func (s *authService) VerifyEmail(token string) error {
+	verification, err := s.repository.FindEmailVerificationByTokenHash(hashSecretToken(token))
+	if err != nil || !verification.Usable(s.now()) {
+		return ErrInvalidVerificationToken
+	}
+
+	redeemed, err := s.repository.VerifyEmail(*verification, s.now())
+	if err != nil {
+		return err
+	}
+	if !redeemed {
+		return ErrInvalidVerificationToken
+	}
+	return nil
+}
+
+// ResendVerification sends the signed-in user a new verification email.
+// Earlier tokens keep working until they expire.
+func (s *authService) ResendVerification(userID uuid.UUID) error {
+	user, err := s.repository.FindUserByID(userID.String())
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+	if !s.verificationLimiter.Allow(user.Email, s.now()) {
+		return ErrTooManyVerificationEmails
+	}
+
+	return s.sendVerification(user)
+}
+
+func (s *authService) sendVerification(user *models.User) error {
+	token, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+	verification := models.EmailVerification{
+		UserID:    user.ID,
+		TokenHash: hashSecretToken(token),
+		ExpiresAt: s.now().Add(emailVerificationTTL),
+	}
+	if err := s.repository.CreateEmailVerification(&verification); err != nil {
+		return err
+	}
+
+	s.sendInBackground(user.ID, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nConfirm this is your email address by opening the link below. It expires in %d hours.\n\n%s?token=%s\n\nIf you did not create an account, you can ignore this email.\n",
+			user.Name, int(emailVerificationTTL.Hours()), s.emailVerificationURL, url.QueryEscape(token),
+		),
+	})
+	return nil
+}
+
+// sendInBackground sends message without holding up the request; failures are
+// only logged.
+func (s *authService) sendInBackground(userID uuid.UUID, message mail.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		defer cancel()
+		if err := s.mailer.Send(ctx, message); err != nil {
+			log.Printf("email %q to user %s failed: %v", message.Subject, userID, err)
+		}
+	}()
+}
+
+// EnrollTOTP starts setting up an authenticator app. Two-factor
+// authentication stays off until ConfirmTOTP; enrolling again starts over.
+func (s *authService) EnrollTOTP(userID uuid.UUID) (*TOTPEnrollmentDTO, error) {
+	user, err := s.repository.FindUserByID(userID.String())
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	factor, err := s.repository.FindTOTPFactor(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && factor.Confirmed() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.ReplaceTOTPFactor(&models.TOTPFactor{UserID: userID, Secret: secret}); err != nil {
+		return nil, err
+	}
+
+	return &TOTPEnrollmentDTO{Secret: secret, ProvisioningURI: totpURI(s.mfaIssuer, user.Email, secret)}, nil
+}
+
+// ConfirmTOTP turns two-factor authentication on once the user proves their
+// app works with a first code, and returns their recovery codes.
+func (s *authService) ConfirmTOTP(userID uuid.UUID, code string) (*RecoveryCodesDTO, error) {
+	factor, err := s.repository.FindTOTPFactor(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if factor.Confirmed() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if !s.mfaLimiter.Allow(userID.String(), s.now()) {
+		return nil, ErrTooManyMFAAttempts
+	}
+
+	step, ok := matchTOTP(factor.Secret, normalizeMFACode(code), s.now(), 0)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = hashSecretToken(normalizeMFACode(code))
+	}
+	confirmed, err := s.repository.ConfirmTOTPFactor(userID, step, s.now(), hashes)
+	if err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		return nil, ErrMFANotEnrolled
+	}
+
+	return &RecoveryCodesDTO{RecoveryCodes: codes}, nil
+}
+
+// DisableTOTP turns two-factor authentication off. It takes a current code so
+// a stolen session alone cannot remove the second factor.
+func (s *authService) DisableTOTP(userID uuid.UUID, code string) error {
+	factor, err := s.repository.FindTOTPFactor(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMFANotEnabled
+	}
+	if err != nil {
+		return err
+	}
+	if !factor.Confirmed() {
+		return ErrMFANotEnabled
+	}
+	if err := s.checkSecondFactor(*factor, code); err != nil {
+		return err
+	}
+
+	return s.repository.DeleteTOTPFactor(userID)
+}
+
+// checkSecondFactor accepts a code from the authenticator app, once, or an
+// unused recovery code.
+func (s *authService) checkSecondFactor(factor models.TOTPFactor, code string) error {
+	if !s.mfaLimiter.Allow(factor.UserID.String(), s.now()) {
+		return ErrTooManyMFAAttempts
+	}
+
+	code = normalizeMFACode(code)
+	if isTOTPCode(code) {
+		step, ok := matchTOTP(factor.Secret, code, s.now(), factor.LastUsedStep)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		used, err := s.repository.UseTOTPStep(factor.UserID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	used, err := s.repository.UseRecoveryCode(factor.UserID, hashSecretToken(code), s.now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// CreatePersonalAccessToken issues a long-lived token for scripts and
+// integrations. The raw token is returned once; only its hash is stored.
+func (s *authService) CreatePersonalAccessToken(userID uuid.UUID, input CreatePersonalAccessTokenRequestDTO) (*PersonalAccessTokenCreatedDTO, error) {
+	secret, err := newSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	days := input.ExpiresInDays
+	if days == 0 {
+		days = defaultPersonalTokenValidDays
+	}
+
+	raw := personalAccessTokenPrefix + secret
+	token := models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      strings.TrimSpace(input.Name),
+		TokenHash: hashSecretToken(raw),
+		Scopes:    strings.Join(uniqueStrings(input.Scopes), " "),
+		ExpiresAt: s.now().Add(time.Duration(days) * 24 * time.Hour),
+	}
+	if err := s.repository.CreatePersonalAccessToken(&token); err != nil {
+		return nil, err
+	}
+
+	return &PersonalAccessTokenCreatedDTO{
+		PersonalAccessTokenResponseDTO: personalAccessTokenResponse(token),
+		Token:                          raw,
+	}, nil
+}
+
+func (s *authService) ListPersonalAccessTokens(userID uuid.UUID) ([]PersonalAccessTokenResponseDTO, error) {
+	tokens, err := s.repository.ListPersonalAccessTokens(userID, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]PersonalAccessTokenResponseDTO, len(tokens))
+	for i, token := range tokens {
+		res[i] = personalAccessTokenResponse(token)
+	}
+	return res, nil
+}
+
+func (s *authService) RevokePersonalAccessToken(userID uuid.UUID, tokenID uuid.UUID) error {
+	revoked, err := s.repository.RevokePersonalAccessToken(userID, tokenID, s.now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrPersonalAccessTokenNotFound
+	}
+	return nil
+}
+
+// SetUserDisabled disables or re-enables an account. Disabling signs the user
+// out everywhere; their personal access tokens stop working while disabled.
+func (s *authService) SetUserDisabled(userID uuid.UUID, disabled bool) error {
+	var disabledAt *time.Time
+	if disabled {
+		now := s.now()
+		disabledAt = &now
+	}
+	if err := s.repository.SetUserDisabled(userID, disabledAt); err != nil {
+		return err
+	}
+	if disabled {
+		return s.repository.RevokeUserSessions(userID, s.now())
+	}
+	return nil
+}
+
+// ForcePasswordReset signs the user out everywhere and emails them a reset
+// link. Their current password stops working until they use it.
+func (s *authService) ForcePasswordReset(userID uuid.UUID) error {
+	user, err := s.repository.FindUserByID(userID.String())
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if err := s.repository.RequirePasswordReset(userID, s.now()); err != nil {
+		return err
+	}
+
+	return s.sendPasswordReset(user, "An administrator asked you to choose a new password; you cannot sign in with your old one.")
+}
+
+func personalAccessTokenResponse(token models.PersonalAccessToken) PersonalAccessTokenResponseDTO {
+	res := PersonalAccessTokenResponseDTO{
+		ID:        token.ID.String(),
+		Name:      token.Name,
+		Scopes:    token.ScopeList(),
+		CreatedAt: token.CreatedAt.Format(time.RFC3339),
+		ExpiresAt: token.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+	if token.LastUsedAt != nil {
+		lastUsedAt := token.LastUsedAt.UTC().Format(time.RFC3339)
+		res.LastUsedAt = &lastUsedAt
+	}
+	return res
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+func (s *authService) GetUser(id string) (*UserResponseDTO, error) {
+	user, err := s.repository.FindUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	factor, err := s.repository.FindTOTPFactor(user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	res := newUserResponse(user, err == nil && factor.Confirmed())
+	return &res, nil
+}
+
+func (s *authService) issueTokens(session models.Session, secret string, role models.UserRole) (*TokenPairDTO, error) {
+	expiresAt := s.now().Add(s.accessTokenTTL)
 	claims := jwt.MapClaims{
-		"sub": user.ID.String(),
-		"exp": time.Now().Add(24 * time.Hour).Unix(),
+		"sub":  session.UserID.String(),
+		"sid":  session.ID.String(),
+		"role": string(role),
+		"exp":  expiresAt.Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signed, err := token.SignedString(s.jwtSecret)
@@ -65,19 +826,73 @@ func (s *authService) Login(email, password string) (*LoginResponseDTO, error) {
 		return nil, err
 	}
 
-	return &LoginResponseDTO{Token: signed, User: &UserResponseDTO{
-		ID:        user.ID.String(),
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-	}}, nil
+	return &TokenPairDTO{
+		Token:        signed,
+		RefreshToken: session.ID.String() + "." + secret,
+		ExpiresAt:    expiresAt.UTC().Format(time.RFC3339),
+	}, nil
 }
 
-func (s *authService) GetUser(id string) (*models.User, error) {
-	user, err := s.repository.FindUserByID(id)
+// MFA challenge tokens are JWTs, but signed with a key derived from the JWT
+// secret so they can never pass for access tokens.
+func (s *authService) mfaChallengeKey() []byte {
+	mac := hmac.New(sha256.New, s.jwtSecret)
+	mac.Write([]byte("mfa-challenge"))
+	return mac.Sum(nil)
+}
+
+func (s *authService) issueMFAChallenge(userID uuid.UUID) (string, error) {
+	claims := jwt.MapClaims{
+		"sub": userID.String(),
+		"aud": "mfa",
+		"exp": s.now().Add(mfaChallengeTTL).Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.mfaChallengeKey())
+}
+
+func (s *authService) parseMFAChallenge(challenge string) (uuid.UUID, error) {
+	token, err := jwt.Parse(challenge, func(token *jwt.Token) (interface{}, error) {
+		return s.mfaChallengeKey(), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience("mfa"),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
 	if err != nil {
-		return nil, err
+		return uuid.Nil, err
+	}
+
+	sub, err := token.Claims.GetSubject()
+	if err != nil {
+		return uuid.Nil, err
 	}
+	return uuid.Parse(sub)
+}
+
+// Refresh tokens are "<session ID>.<secret>", so the session can be found
+// even when the secret no longer matches.
+func parseRefreshToken(token string) (uuid.UUID, string, bool) {
+	rawID, secret, ok := strings.Cut(token, ".")
+	if !ok || secret == "" {
+		return uuid.Nil, "", false
+	}
+	sessionID, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.Nil, "", false
+	}
+	return sessionID, secret, true
+}
+
+func newSecretToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
 
-	return user, nil
+func hashSecretToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
 }
@@ -1,15 +1,24 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
+	"cards/internal/apperrors"
+	"cards/internal/mail"
 	"cards/internal/models"
 
+	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type fakeAuthRepository struct {
@@ -17,7 +26,36 @@ type fakeAuthRepository struct {
 	saveUser    func(user *models.User) error
 	findByID    func(id string) (*models.User, error)
 
-	savedUser *models.User
+	savedUser    *models.User
+	sessions     map[uuid.UUID]models.Session
+	resets       map[string]models.PasswordReset
+	passwordHash string
+
+	verifications map[string]models.EmailVerification
+	verifiedUser  uuid.UUID
+
+	factors       map[uuid.UUID]models.TOTPFactor
+	recoveryCodes map[string]models.RecoveryCode
+
+	identities []models.UserIdentity
+
+	accessTokens map[uuid.UUID]models.PersonalAccessToken
+	tokenTouches int
+
+	disabledAt    map[uuid.UUID]*time.Time
+	resetRequired map[uuid.UUID]bool
+}
+
+// fakeMailer hands sent messages to the test through sent, if set.
+type fakeMailer struct {
+	sent chan mail.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, message mail.Message) error {
+	if m.sent != nil {
+		m.sent <- message
+	}
+	return nil
 }
 
 func (r *fakeAuthRepository) FindUserByEmail(email string) (*models.User, error) {
@@ -42,6 +80,299 @@ func (r *fakeAuthRepository) FindUserByID(id string) (*models.User, error) {
 	return nil, errors.New("not implemented")
 }
 
+func (r *fakeAuthRepository) CreateSession(session *models.Session) error {
+	if r.sessions == nil {
+		r.sessions = map[uuid.UUID]models.Session{}
+	}
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *fakeAuthRepository) FindSessionByID(id uuid.UUID) (*models.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+	return &session, nil
+}
+
+func (r *fakeAuthRepository) RotateSession(id uuid.UUID, fromHash string, toHash string) (bool, error) {
+	session, ok := r.sessions[id]
+	if !ok || session.RevokedAt != nil || session.RefreshTokenHash != fromHash {
+		return false, nil
+	}
+	session.RefreshTokenHash = toHash
+	r.sessions[id] = session
+	return true, nil
+}
+
+func (r *fakeAuthRepository) RevokeSession(userID uuid.UUID, id uuid.UUID, at time.Time) error {
+	session, ok := r.sessions[id]
+	if ok && session.UserID == userID && session.RevokedAt == nil {
+		session.RevokedAt = &at
+		r.sessions[id] = session
+	}
+	return nil
+}
+
+func (r *fakeAuthRepository) RevokeUserSessions(userID uuid.UUID, at time.Time) error {
+	for id, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+			r.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func (r *fakeAuthRepository) CreatePasswordReset(reset *models.PasswordReset) error {
+	if r.resets == nil {
+		r.resets = map[string]models.PasswordReset{}
+	}
+	reset.ID = uuid.New()
+	r.resets[reset.TokenHash] = *reset
+	return nil
+}
+
+func (r *fakeAuthRepository) FindPasswordResetByTokenHash(tokenHash string) (*models.PasswordReset, error) {
+	reset, ok := r.resets[tokenHash]
+	if !ok {
+		return nil, errors.New("reset not found")
+	}
+	return &reset, nil
+}
+
+func (r *fakeAuthRepository) ResetPassword(reset models.PasswordReset, passwordHash string, at time.Time) (bool, error) {
+	stored, ok := r.resets[reset.TokenHash]
+	if !ok || stored.UsedAt != nil {
+		return false, nil
+	}
+	for hash, other := range r.resets {
+		if other.UserID == reset.UserID && other.UsedAt == nil {
+			other.UsedAt = &at
+			r.resets[hash] = other
+		}
+	}
+	r.passwordHash = passwordHash
+	r.revokeAccessTokens(reset.UserID, at)
+	return true, r.RevokeUserSessions(reset.UserID, at)
+}
+
+func (r *fakeAuthRepository) CreateEmailVerification(verification *models.EmailVerification) error {
+	if r.verifications == nil {
+		r.verifications = map[string]models.EmailVerification{}
+	}
+	verification.ID = uuid.New()
+	r.verifications[verification.TokenHash] = *verification
+	return nil
+}
+
+func (r *fakeAuthRepository) FindEmailVerificationByTokenHash(tokenHash string) (*models.EmailVerification, error) {
+	verification, ok := r.verifications[tokenHash]
+	if !ok {
+		return nil, errors.New("verification not found")
+	}
+	return &verification, nil
+}
+
+func (r *fakeAuthRepository) VerifyEmail(verification models.EmailVerification, at time.Time) (bool, error) {
+	stored, ok := r.verifications[verification.TokenHash]
+	if !ok || stored.UsedAt != nil {
+		return false, nil
+	}
+	for hash, other := range r.verifications {
+		if other.UserID == verification.UserID && other.UsedAt == nil {
+			other.UsedAt = &at
+			r.verifications[hash] = other
+		}
+	}
+	r.verifiedUser = verification.UserID
+	return true, nil
+}
+
+func (r *fakeAuthRepository) FindTOTPFactor(userID uuid.UUID) (*models.TOTPFactor, error) {
+	factor, ok := r.factors[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &factor, nil
+}
+
+func (r *fakeAuthRepository) ReplaceTOTPFactor(factor *models.TOTPFactor) error {
+	if r.factors == nil {
+		r.factors = map[uuid.UUID]models.TOTPFactor{}
+	}
+	if existing, ok := r.factors[factor.UserID]; ok && existing.Confirmed() {
+		return errors.New("duplicate key")
+	}
+	factor.ID = uuid.New()
+	r.factors[factor.UserID] = *factor
+	return nil
+}
+
+func (r *fakeAuthRepository) ConfirmTOTPFactor(userID uuid.UUID, step int64, at time.Time, recoveryCodeHashes []string) (bool, error) {
+	factor, ok := r.factors[userID]
+	if !ok || factor.Confirmed() {
+		return false, nil
+	}
+	factor.ConfirmedAt = &at
+	factor.LastUsedStep = step
+	r.factors[userID] = factor
+
+	r.recoveryCodes = map[string]models.RecoveryCode{}
+	for _, hash := range recoveryCodeHashes {
+		r.recoveryCodes[hash] = models.RecoveryCode{UserID: userID, CodeHash: hash}
+	}
+	return true, nil
+}
+
+func (r *fakeAuthRepository) UseTOTPStep(userID uuid.UUID, step int64) (bool, error) {
+	factor, ok := r.factors[userID]
+	if !ok || factor.LastUsedStep >= step {
+		return false, nil
+	}
+	factor.LastUsedStep = step
+	r.factors[userID] = factor
+	return true, nil
+}
+
+func (r *fakeAuthRepository) UseRecoveryCode(userID uuid.UUID, codeHash string, at time.Time) (bool, error) {
+	code, ok := r.recoveryCodes[codeHash]
+	if !ok || code.UserID != userID || code.UsedAt != nil {
+		return false, nil
+	}
+	code.UsedAt = &at
+	r.recoveryCodes[codeHash] = code
+	return true, nil
+}
+
+func (r *fakeAuthRepository) DeleteTOTPFactor(userID uuid.UUID) error {
+	delete(r.factors, userID)
+	for hash, code := range r.recoveryCodes {
+		if code.UserID == userID {
+			delete(r.recoveryCodes, hash)
+		}
+	}
+	return nil
+}
+
+func (r *fakeAuthRepository) FindUserIdentity(provider string, subject string) (*models.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAuthRepository) CreateUserIdentity(identity *models.UserIdentity) error {
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *fakeAuthRepository) CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error {
+	if err := r.SaveUser(user); err != nil {
+		return err
+	}
+	identity.UserID = user.ID
+	return r.CreateUserIdentity(identity)
+}
+
+func (r *fakeAuthRepository) CreatePersonalAccessToken(token *models.PersonalAccessToken) error {
+	if r.accessTokens == nil {
+		r.accessTokens = map[uuid.UUID]models.PersonalAccessToken{}
+	}
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	r.accessTokens[token.ID] = *token
+	return nil
+}
+
+func (r *fakeAuthRepository) ListPersonalAccessTokens(userID uuid.UUID, now time.Time) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	for _, token := range r.accessTokens {
+		if token.UserID == userID && token.Active(now) {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *fakeAuthRepository) RevokePersonalAccessToken(userID uuid.UUID, id uuid.UUID, at time.Time) (bool, error) {
+	token, ok := r.accessTokens[id]
+	if !ok || token.UserID != userID || token.RevokedAt != nil {
+		return false, nil
+	}
+	token.RevokedAt = &at
+	r.accessTokens[id] = token
+	return true, nil
+}
+
+func (r *fakeAuthRepository) FindPersonalAccessTokenByHash(tokenHash string) (*models.PersonalAccessToken, error) {
+	for _, token := range r.accessTokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAuthRepository) SetUserDisabled(userID uuid.UUID, disabledAt *time.Time) error {
+	if r.disabledAt == nil {
+		r.disabledAt = map[uuid.UUID]*time.Time{}
+	}
+	r.disabledAt[userID] = disabledAt
+	return nil
+}
+
+func (r *fakeAuthRepository) RequirePasswordReset(userID uuid.UUID, at time.Time) error {
+	if r.resetRequired == nil {
+		r.resetRequired = map[uuid.UUID]bool{}
+	}
+	r.resetRequired[userID] = true
+	r.revokeAccessTokens(userID, at)
+	return r.RevokeUserSessions(userID, at)
+}
+
+func (r *fakeAuthRepository) revokeAccessTokens(userID uuid.UUID, at time.Time) {
+	for id, token := range r.accessTokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+			r.accessTokens[id] = token
+		}
+	}
+}
+
+func (r *fakeAuthRepository) TouchPersonalAccessToken(id uuid.UUID, at time.Time) error {
+	token := r.accessTokens[id]
+	token.LastUsedAt = &at
+	r.accessTokens[id] = token
+	r.tokenTouches++
+	return nil
+}
+
+// mailedToken returns the token from the link in the next email sent.
+func mailedToken(t *testing.T, mailer *fakeMailer, to string) string {
+	t.Helper()
+	select {
+	case message := <-mailer.sent:
+		if message.To != to {
+			t.Fatalf("expected email to %s, got %s", to, message.To)
+		}
+		_, link, _ := strings.Cut(message.Body, "?token=")
+		link, _, _ = strings.Cut(link, "\n")
+		token, err := url.QueryUnescape(link)
+		if err != nil || token == "" {
+			t.Fatalf("expected a link with a token in %q", message.Body)
+		}
+		return token
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected an email to %s", to)
+		return ""
+	}
+}
+
 func TestAuthService_Register(t *testing.T) {
 	t.Run("returns error when email already exists", func(t *testing.T) {
 		repo := &fakeAuthRepository{
@@ -53,7 +384,7 @@ func TestAuthService_Register(t *testing.T) {
 				return nil
 			},
 		}
-		svc := NewAuthService(repo)
+		svc := NewAuthService(repo, &fakeMailer{})
 
 		_, err := svc.Register(RegisterRequestDTO{Name: "A", Email: "a@example.com", Password: "pw"})
 		if err == nil || err.Error() != "email already registered" {
@@ -67,7 +398,7 @@ func TestAuthService_Register(t *testing.T) {
 				return nil, errors.New("not found")
 			},
 		}
-		svc := NewAuthService(repo)
+		svc := NewAuthService(repo, &fakeMailer{})
 
 		user, err := svc.Register(RegisterRequestDTO{Name: "A", Email: "a@example.com", Password: "pw"})
 		if err != nil {
@@ -86,18 +417,33 @@ func TestAuthService_Register(t *testing.T) {
 }
 
 func TestAuthService_Login(t *testing.T) {
-	t.Run("returns user not found when repository errors", func(t *testing.T) {
+	t.Run("returns invalid credentials for unknown emails", func(t *testing.T) {
 		t.Setenv("JWT_SECRET", "secret")
 		repo := &fakeAuthRepository{
 			findByEmail: func(email string) (*models.User, error) {
-				return nil, errors.New("db error")
+				return nil, gorm.ErrRecordNotFound
 			},
 		}
-		svc := NewAuthService(repo)
+		svc := NewAuthService(repo, &fakeMailer{})
 
 		_, err := svc.Login("a@example.com", "pw")
-		if err == nil || err.Error() != "user not found" {
-			t.Fatalf("expected user not found error, got %v", err)
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("expected %v, got %v", ErrInvalidCredentials, err)
+		}
+	})
+
+	t.Run("returns repository errors", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "secret")
+		dbErr := errors.New("db error")
+		repo := &fakeAuthRepository{
+			findByEmail: func(email string) (*models.User, error) {
+				return nil, dbErr
+			},
+		}
+		svc := NewAuthService(repo, &fakeMailer{})
+
+		if _, err := svc.Login("a@example.com", "pw"); !errors.Is(err, dbErr) {
+			t.Fatalf("expected the repository error, got %v", err)
 		}
 	})
 
@@ -113,7 +459,7 @@ func TestAuthService_Login(t *testing.T) {
 				return &models.User{Email: email, Password: string(hash)}, nil
 			},
 		}
-		svc := NewAuthService(repo)
+		svc := NewAuthService(repo, &fakeMailer{})
 
 		_, err = svc.Login("a@example.com", "wrong")
 		if err == nil || err.Error() != "invalid credentials" {
@@ -136,7 +482,7 @@ func TestAuthService_Login(t *testing.T) {
 				return user, nil
 			},
 		}
-		svc := NewAuthService(repo)
+		svc := NewAuthService(repo, &fakeMailer{})
 
 		res, err := svc.Login("a@example.com", "pw")
 		if err != nil {
@@ -171,8 +517,885 @@ func TestAuthService_Login(t *testing.T) {
 	})
 }
 
+func TestAuthService_Sessions(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	hash, err := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	user := &models.User{Base: models.Base{ID: uuid.New()}, Email: "a@example.com", Password: string(hash), Role: models.UserRoleAdmin}
+
+	login := func(t *testing.T) (*fakeAuthRepository, AuthService, *LoginResponseDTO) {
+		t.Helper()
+		repo := &fakeAuthRepository{
+			findByEmail: func(email string) (*models.User, error) { return user, nil },
+			findByID:    func(id string) (*models.User, error) { return user, nil },
+		}
+		svc := NewAuthService(repo, &fakeMailer{})
+		res, err := svc.Login(user.Email, "pw")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		return repo, svc, res
+	}
+	sessionOf := func(t *testing.T, repo *fakeAuthRepository, token string) models.Session {
+		t.Helper()
+		sessionID, _, ok := parseRefreshToken(token)
+		if !ok {
+			t.Fatalf("malformed refresh token %q", token)
+		}
+		return repo.sessions[sessionID]
+	}
+
+	t.Run("login starts a session the access token points to", func(t *testing.T) {
+		repo, _, res := login(t)
+
+		session := sessionOf(t, repo, res.RefreshToken)
+		if session.UserID != user.ID || !session.Active(time.Now()) {
+			t.Fatalf("expected an active session for the user, got %+v", session)
+		}
+		if session.RefreshTokenHash == "" || strings.Contains(res.RefreshToken, session.RefreshTokenHash) {
+			t.Fatalf("expected only a hash of the refresh token to be stored")
+		}
+
+		parsed, err := jwt.Parse(res.Token, func(token *jwt.Token) (interface{}, error) {
+			return []byte("secret"), nil
+		})
+		if err != nil {
+			t.Fatalf("failed to parse token: %v", err)
+		}
+		claims := parsed.Claims.(jwt.MapClaims)
+		if claims["sid"] != session.ID.String() {
+			t.Fatalf("expected sid %q, got %v", session.ID, claims["sid"])
+		}
+		if claims["role"] != "admin" {
+			t.Fatalf("expected role %q, got %v", "admin", claims["role"])
+		}
+		exp, _ := claims.GetExpirationTime()
+		if time.Until(exp.Time) > 15*time.Minute {
+			t.Fatalf("expected a short-lived access token, expires %v", exp.Time)
+		}
+	})
+
+	t.Run("refresh rotates the refresh token", func(t *testing.T) {
+		repo, svc, res := login(t)
+
+		tokens, err := svc.Refresh(res.RefreshToken)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if tokens.RefreshToken == res.RefreshToken || tokens.Token == "" {
+			t.Fatalf("expected new tokens, got %+v", tokens)
+		}
+		if !sessionOf(t, repo, tokens.RefreshToken).Active(time.Now()) {
+			t.Fatalf("expected the session to stay active")
+		}
+
+		if _, err := svc.Refresh(tokens.RefreshToken); err != nil {
+			t.Fatalf("expected the rotated token to work, got %v", err)
+		}
+	})
+
+	t.Run("reusing a refresh token revokes the session", func(t *testing.T) {
+		repo, svc, res := login(t)
+
+		tokens, err := svc.Refresh(res.RefreshToken)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if _, err := svc.Refresh(res.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+			t.Fatalf("expected reuse to be detected, got %v", err)
+		}
+		if sessionOf(t, repo, tokens.RefreshToken).RevokedAt == nil {
+			t.Fatalf("expected the session to be revoked")
+		}
+		if _, err := svc.Refresh(tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Fatalf("expected the latest token to die with the session, got %v", err)
+		}
+	})
+
+	t.Run("rejects malformed, unknown and expired refresh tokens", func(t *testing.T) {
+		repo, svc, res := login(t)
+
+		session := sessionOf(t, repo, res.RefreshToken)
+		session.ExpiresAt = time.Now().Add(-time.Minute)
+		repo.sessions[session.ID] = session
+
+		for _, token := range []string{"garbage", uuid.NewString() + ".secret", res.RefreshToken} {
+			if _, err := svc.Refresh(token); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Fatalf("%q: expected invalid refresh token, got %v", token, err)
+			}
+		}
+	})
+
+	t.Run("logout revokes one session, logout all every session", func(t *testing.T) {
+		repo, svc, first := login(t)
+		second, err := svc.Login(user.Email, "pw")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		third, err := svc.Login(user.Email, "pw")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+
+		if err := svc.Logout(uuid.New(), sessionOf(t, repo, first.RefreshToken).ID); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if sessionOf(t, repo, first.RefreshToken).RevokedAt != nil {
+			t.Fatalf("expected another user's logout to leave the session alone")
+		}
+
+		if err := svc.Logout(user.ID, sessionOf(t, repo, first.RefreshToken).ID); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if sessionOf(t, repo, first.RefreshToken).RevokedAt == nil || sessionOf(t, repo, second.RefreshToken).RevokedAt != nil {
+			t.Fatalf("expected only the first session to be revoked")
+		}
+
+		if err := svc.LogoutAll(user.ID); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		for _, res := range []*LoginResponseDTO{second, third} {
+			if _, err := svc.Refresh(res.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Fatalf("expected every session to be revoked, got %v", err)
+			}
+		}
+	})
+}
+
+func TestAuthService_PasswordReset(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	user := &models.User{Base: models.Base{ID: uuid.New()}, Name: "Ana", Email: "ana@example.com"}
+
+	newService := func(t *testing.T) (*fakeAuthRepository, *fakeMailer, AuthService) {
+		t.Helper()
+		repo := &fakeAuthRepository{
+			findByEmail: func(email string) (*models.User, error) {
+				if email == user.Email {
+					return user, nil
+				}
+				return nil, gorm.ErrRecordNotFound
+			},
+		}
+		mailer := &fakeMailer{sent: make(chan mail.Message, 10)}
+		return repo, mailer, NewAuthService(repo, mailer)
+	}
+	// requestToken asks for a reset and returns the token from the email.
+	requestToken := func(t *testing.T, svc AuthService, mailer *fakeMailer) string {
+		t.Helper()
+		if err := svc.ForgotPassword(user.Email); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		return mailedToken(t, mailer, user.Email)
+	}
+
+	t.Run("does not reveal unknown emails", func(t *testing.T) {
+		repo, mailer, svc := newService(t)
+
+		if err := svc.ForgotPassword("nobody@example.com"); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(repo.resets) != 0 || len(mailer.sent) != 0 {
+			t.Fatalf("expected no reset and no email for an unknown address")
+		}
+	})
+
+	t.Run("stores only a hash of the token", func(t *testing.T) {
+		repo, mailer, svc := newService(t)
+		token := requestToken(t, svc, mailer)
+
+		reset, ok := repo.resets[hashSecretToken(token)]
+		if !ok || reset.UserID != user.ID || !reset.Usable(time.Now()) {
+			t.Fatalf("expected a usable reset stored under the token hash, got %+v", repo.resets)
+		}
+	})
+
+	t.Run("looks up the normalized address", func(t *testing.T) {
+		_, mailer, svc := newService(t)
+
+		if err := svc.ForgotPassword("  " + strings.ToUpper(user.Email) + " "); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		mailedToken(t, mailer, user.Email)
+	})
+
+	t.Run("rate limits per email whether or not it is registered", func(t *testing.T) {
+		_, _, svc := newService(t)
+
+		for _, email := range []string{user.Email, "nobody@example.com"} {
+			for i := 0; i < passwordResetLimit; i++ {
+				if err := svc.ForgotPassword(email); err != nil {
+					t.Fatalf("expected nil error, got %v", err)
+				}
+			}
+			if err := svc.ForgotPassword(" " + strings.ToUpper(email)); !errors.Is(err, apperrors.ErrRateLimited) {
+				t.Fatalf("expected rate limited error for %s, got %v", email, err)
+			}
+		}
+	})
+
+	t.Run("token resets the password once and signs out every session", func(t *testing.T) {
+		repo, mailer, svc := newService(t)
+		repo.CreateSession(&models.Session{Base: models.Base{ID: uuid.New()}, UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)})
+		accessToken, err := svc.CreatePersonalAccessToken(user.ID, CreatePersonalAccessTokenRequestDTO{Name: "script", Scopes: []string{ScopeCardsRead}})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		repo.findByID = func(id string) (*models.User, error) { return user, nil }
+		router := gin.New()
+		router.GET("/cards", NewMiddleware(repo).Authenticate(ScopeCardsRead), func(c *gin.Context) { c.Status(http.StatusOK) })
+		readCards := func() int {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/cards", nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken.Token)
+			router.ServeHTTP(w, req)
+			return w.Code
+		}
+		if code := readCards(); code != http.StatusOK {
+			t.Fatalf("expected the access token to work before the reset, got %d", code)
+		}
+		token := requestToken(t, svc, mailer)
+
+		if err := svc.ResetPassword(token, "new-password"); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if bcrypt.CompareHashAndPassword([]byte(repo.passwordHash), []byte("new-password")) != nil {
+			t.Fatalf("expected the new password to be stored hashed")
+		}
+		for _, session := range repo.sessions {
+			if session.Active(time.Now()) {
+				t.Fatalf("expected every session revoked, got %+v", session)
+			}
+		}
+		if code := readCards(); code != http.StatusUnauthorized {
+			t.Fatalf("expected the access token to stop working after the reset, got %d", code)
+		}
+
+		if err := svc.ResetPassword(token, "another-password"); !errors.Is(err, ErrInvalidResetToken) {
+			t.Fatalf("expected invalid reset token error, got %v", err)
+		}
+	})
+
+	t.Run("rejects expired and unknown tokens", func(t *testing.T) {
+		repo, mailer, svc := newService(t)
+		token := requestToken(t, svc, mailer)
+		svc.(*authService).now = func() time.Time { return time.Now().Add(passwordResetTTL + time.Minute) }
+
+		for _, candidate := range []string{token, "unknown"} {
+			if err := svc.ResetPassword(candidate, "new-password"); !errors.Is(err, ErrInvalidResetToken) {
+				t.Fatalf("expected invalid reset token error, got %v", err)
+			}
+		}
+		if repo.passwordHash != "" {
+			t.Fatalf("expected the password to be unchanged")
+		}
+	})
+}
+
+func TestAuthService_EmailVerification(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+
+	register := func(t *testing.T) (*fakeAuthRepository, *fakeMailer, AuthService, *models.User) {
+		t.Helper()
+		repo := &fakeAuthRepository{
+			findByEmail: func(email string) (*models.User, error) { return nil, gorm.ErrRecordNotFound },
+			saveUser:    func(user *models.User) error { user.ID = uuid.New(); return nil },
+		}
+		repo.findByID = func(id string) (*models.User, error) { return repo.savedUser, nil }
+		mailer := &fakeMailer{sent: make(chan mail.Message, 10)}
+		svc := NewAuthService(repo, mailer)
+
+		user, err := svc.Register(RegisterRequestDTO{Name: "Ana", Email: "ana@example.com", Password: "pw"})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		return repo, mailer, svc, user
+	}
+
+	t.Run("registering sends a token that verifies the email once", func(t *testing.T) {
+		repo, mailer, svc, user := register(t)
+		if user.EmailVerified() {
+			t.Fatalf("expected a new user to be unverified")
+		}
+		token := mailedToken(t, mailer, user.Email)
+
+		if err := svc.VerifyEmail(token); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.verifiedUser != user.ID {
+			t.Fatalf("expected user %s verified, got %s", user.ID, repo.verifiedUser)
+		}
+		if err := svc.VerifyEmail(token); !errors.Is(err, ErrInvalidVerificationToken) {
+			t.Fatalf("expected invalid verification token error, got %v", err)
+		}
+	})
+
+	t.Run("rejects expired and unknown tokens", func(t *testing.T) {
+		repo, mailer, svc, user := register(t)
+		token := mailedToken(t, mailer, user.Email)
+		svc.(*authService).now = func() time.Time { return time.Now().Add(emailVerificationTTL + time.Minute) }
+
+		for _, candidate := range []string{token, "unknown"} {
+			if err := svc.VerifyEmail(candidate); !errors.Is(err, ErrInvalidVerificationToken) {
+				t.Fatalf("expected invalid verification token error, got %v", err)
+			}
+		}
+		if repo.verifiedUser != uuid.Nil {
+			t.Fatalf("expected no user verified")
+		}
+	})
+
+	t.Run("resends until the limit", func(t *testing.T) {
+		_, mailer, svc, user := register(t)
+		mailedToken(t, mailer, user.Email)
+
+		for i := 0; i < emailVerificationLimit; i++ {
+			if err := svc.ResendVerification(user.ID); err != nil {
+				t.Fatalf("expected nil error, got %v", err)
+			}
+			mailedToken(t, mailer, user.Email)
+		}
+		if err := svc.ResendVerification(user.ID); !errors.Is(err, apperrors.ErrRateLimited) {
+			t.Fatalf("expected rate limited error, got %v", err)
+		}
+	})
+
+	t.Run("does not resend to a verified email", func(t *testing.T) {
+		_, _, svc, user := register(t)
+		verifiedAt := time.Now()
+		user.EmailVerifiedAt = &verifiedAt
+
+		if err := svc.ResendVerification(user.ID); !errors.Is(err, ErrEmailAlreadyVerified) {
+			t.Fatalf("expected email already verified error, got %v", err)
+		}
+	})
+}
+
+func TestAuthService_TwoFactor(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	hash, err := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	user := &models.User{Base: models.Base{ID: uuid.New()}, Email: "a@example.com", Password: string(hash)}
+
+	// clock lets a test move time forward, since a TOTP code works once.
+	clock := time.Unix(1_700_000_000, 0)
+	newService := func(t *testing.T) (*fakeAuthRepository, *authService) {
+		t.Helper()
+		repo := &fakeAuthRepository{
+			findByEmail: func(email string) (*models.User, error) { return user, nil },
+			findByID:    func(id string) (*models.User, error) { return user, nil },
+		}
+		svc := NewAuthService(repo, &fakeMailer{}).(*authService)
+		svc.now = func() time.Time { return clock }
+		return repo, svc
+	}
+	codeFor := func(t *testing.T, secret string) string {
+		t.Helper()
+		code, err := totpCode(secret, totpStep(clock), totpDigits)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		return code
+	}
+	// enable enrolls the user and confirms with a first code.
+	enable := func(t *testing.T, svc *authService) (string, []string) {
+		t.Helper()
+		enrollment, err := svc.EnrollTOTP(user.ID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		codes, err := svc.ConfirmTOTP(user.ID, codeFor(t, enrollment.Secret))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		clock = clock.Add(totpPeriod * time.Second)
+		return enrollment.Secret, codes.RecoveryCodes
+	}
+
+	t.Run("enrollment only takes effect once confirmed", func(t *testing.T) {
+		repo, svc := newService(t)
+		enrollment, err := svc.EnrollTOTP(user.ID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !strings.HasPrefix(enrollment.ProvisioningURI, "otpauth://totp/") || !strings.Contains(enrollment.ProvisioningURI, enrollment.Secret) {
+			t.Fatalf("unexpected provisioning URI %q", enrollment.ProvisioningURI)
+		}
+
+		res, err := svc.Login(user.Email, "pw")
+		if err != nil || res.MFARequired || res.TokenPairDTO == nil {
+			t.Fatalf("expected a plain login before confirmation, got %+v, %v", res, err)
+		}
+
+		if _, err := svc.ConfirmTOTP(user.ID, "not-a-code"); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("expected invalid code error, got %v", err)
+		}
+		codes, err := svc.ConfirmTOTP(user.ID, codeFor(t, enrollment.Secret))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(codes.RecoveryCodes) != recoveryCodeCount || len(repo.recoveryCodes) != recoveryCodeCount {
+			t.Fatalf("expected %d recovery codes", recoveryCodeCount)
+		}
+		for hash := range repo.recoveryCodes {
+			for _, code := range codes.RecoveryCodes {
+				if strings.Contains(hash, code) {
+					t.Fatalf("expected only hashes of recovery codes to be stored")
+				}
+			}
+		}
+		if _, err := svc.EnrollTOTP(user.ID); !errors.Is(err, ErrMFAAlreadyEnabled) {
+			t.Fatalf("expected already enabled error, got %v", err)
+		}
+	})
+
+	t.Run("login requires a second factor once enabled", func(t *testing.T) {
+		repo, svc := newService(t)
+		secret, _ := enable(t, svc)
+
+		res, err := svc.Login(user.Email, "pw")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !res.MFARequired || res.MFAToken == "" || res.TokenPairDTO != nil || res.User != nil {
+			t.Fatalf("expected only a challenge, got %+v", res)
+		}
+		if len(repo.sessions) != 0 {
+			t.Fatalf("expected no session before the second factor")
+		}
+		if _, err := ValidateJWTToken(res.MFAToken); err == nil {
+			t.Fatalf("expected the challenge not to pass as an access token")
+		}
+
+		code := codeFor(t, secret)
+		done, err := svc.CompleteMFALogin(res.MFAToken, code)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if done.TokenPairDTO == nil || done.RefreshToken == "" || !done.User.MFAEnabled {
+			t.Fatalf("expected a session, got %+v", done)
+		}
+		if _, err := svc.CompleteMFALogin(res.MFAToken, code); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("expected a replayed code to be refused, got %v", err)
+		}
+	})
+
+	t.Run("recovery codes work once", func(t *testing.T) {
+		_, svc := newService(t)
+		_, recoveryCodes := enable(t, svc)
+		res, err := svc.Login(user.Email, "pw")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+
+		if _, err := svc.CompleteMFALogin(res.MFAToken, " "+strings.ToUpper(recoveryCodes[0])); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if _, err := svc.CompleteMFALogin(res.MFAToken, recoveryCodes[0]); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("expected a used recovery code to be refused, got %v", err)
+		}
+	})
+
+	t.Run("refuses bad and expired challenges", func(t *testing.T) {
+		_, svc := newService(t)
+		secret, _ := enable(t, svc)
+		res, err := svc.Login(user.Email, "pw")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+
+		accessToken, err := svc.issueTokens(models.Session{Base: models.Base{ID: uuid.New()}, UserID: user.ID}, "secret", user.Role)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if _, err := svc.CompleteMFALogin(accessToken.Token, codeFor(t, secret)); !errors.Is(err, ErrInvalidMFAToken) {
+			t.Fatalf("expected an access token to be refused as a challenge, got %v", err)
+		}
+
+		clock = clock.Add(mfaChallengeTTL + time.Minute)
+		if _, err := svc.CompleteMFALogin(res.MFAToken, codeFor(t, secret)); !errors.Is(err, ErrInvalidMFAToken) {
+			t.Fatalf("expected an expired challenge to be refused, got %v", err)
+		}
+	})
+
+	t.Run("limits code attempts", func(t *testing.T) {
+		_, svc := newService(t)
+		enable(t, svc)
+		res, err := svc.Login(user.Email, "pw")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+
+		for i := 0; i < mfaAttemptLimit-1; i++ {
+			if _, err := svc.CompleteMFALogin(res.MFAToken, "not-a-code"); !errors.Is(err, ErrInvalidMFACode) {
+				t.Fatalf("expected invalid code error, got %v", err)
+			}
+		}
+		if _, err := svc.CompleteMFALogin(res.MFAToken, "not-a-code"); !errors.Is(err, apperrors.ErrRateLimited) {
+			t.Fatalf("expected rate limited error, got %v", err)
+		}
+	})
+
+	t.Run("disabling takes a code", func(t *testing.T) {
+		repo, svc := newService(t)
+		secret, _ := enable(t, svc)
+
+		if err := svc.DisableTOTP(user.ID, "not-a-code"); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("expected invalid code error, got %v", err)
+		}
+		if err := svc.DisableTOTP(user.ID, codeFor(t, secret)); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(repo.factors) != 0 || len(repo.recoveryCodes) != 0 {
+			t.Fatalf("expected the factor and recovery codes removed")
+		}
+		if err := svc.DisableTOTP(user.ID, codeFor(t, secret)); !errors.Is(err, ErrMFANotEnabled) {
+			t.Fatalf("expected not enabled error, got %v", err)
+		}
+	})
+}
+
+func TestAuthService_OAuthLogin(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	verifiedAt := time.Now()
+	google := ExternalIdentity{Provider: "google", Subject: "g-1", Email: "ana@example.com", EmailVerified: true, Name: "Ana"}
+
+	// newService knows the given users by email and ID.
+	newService := func(t *testing.T, users ...*models.User) (*fakeAuthRepository, *authService) {
+		t.Helper()
+		repo := &fakeAuthRepository{
+			saveUser: func(user *models.User) error { user.ID = uuid.New(); return nil },
+		}
+		find := func(match func(*models.User) bool) (*models.User, error) {
+			for _, user := range append(users, repo.savedUser) {
+				if user != nil && match(user) {
+					return user, nil
+				}
+			}
+			return nil, gorm.ErrRecordNotFound
+		}
+		repo.findByEmail = func(email string) (*models.User, error) {
+			return find(func(u *models.User) bool { return u.Email == email })
+		}
+		repo.findByID = func(id string) (*models.User, error) {
+			return find(func(u *models.User) bool { return u.ID.String() == id })
+		}
+		return repo, NewAuthService(repo, &fakeMailer{}).(*authService)
+	}
+
+	t.Run("signs up a new user", func(t *testing.T) {
+		repo, svc := newService(t)
+
+		res, err := svc.OAuthLogin(google)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if res.TokenPairDTO == nil || res.User.Email != google.Email || !res.User.EmailVerified {
+			t.Fatalf("expected a session for a verified new user, got %+v", res)
+		}
+		if len(repo.identities) != 1 || repo.identities[0].UserID != repo.savedUser.ID {
+			t.Fatalf("expected the identity linked to the new user, got %+v", repo.identities)
+		}
+
+		again, err := svc.OAuthLogin(google)
+		if err != nil || again.User.ID != res.User.ID {
+			t.Fatalf("expected the same user on the next sign-in, got %+v, %v", again, err)
+		}
+		if len(repo.identities) != 1 {
+			t.Fatalf("expected no second identity")
+		}
+	})
+
+	t.Run("links an existing account with the same verified email", func(t *testing.T) {
+		existing := &models.User{Base: models.Base{ID: uuid.New()}, Email: google.Email, EmailVerifiedAt: &verifiedAt}
+		repo, svc := newService(t, existing)
+
+		res, err := svc.OAuthLogin(google)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if res.User.ID != existing.ID.String() || repo.savedUser != nil {
+			t.Fatalf("expected to sign in as the existing user, got %+v", res.User)
+		}
+		if len(repo.identities) != 1 || repo.identities[0].UserID != existing.ID {
+			t.Fatalf("expected the identity linked to the existing user, got %+v", repo.identities)
+		}
+	})
+
+	t.Run("does not link unverified emails", func(t *testing.T) {
+		unverified := google
+		unverified.EmailVerified = false
+		verifiedUser := &models.User{Base: models.Base{ID: uuid.New()}, Email: google.Email, EmailVerifiedAt: &verifiedAt}
+		unverifiedUser := &models.User{Base: models.Base{ID: uuid.New()}, Email: google.Email}
+
+		tests := []struct {
+			name     string
+			identity ExternalIdentity
+			user     *models.User
+			want     error
+		}{
+			{"provider has not verified it", unverified, verifiedUser, ErrOAuthEmailUnverified},
+			{"account has not verified it", google, unverifiedUser, ErrOAuthLinkUnverified},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo, svc := newService(t, tt.user)
+				if _, err := svc.OAuthLogin(tt.identity); !errors.Is(err, tt.want) {
+					t.Fatalf("expected %v, got %v", tt.want, err)
+				}
+				if len(repo.identities) != 0 {
+					t.Fatalf("expected no identity linked")
+				}
+			})
+		}
+	})
+
+	t.Run("refuses users who must reset their password", func(t *testing.T) {
+		user := &models.User{Base: models.Base{ID: uuid.New()}, Email: google.Email, EmailVerifiedAt: &verifiedAt, PasswordResetRequired: true}
+		repo, svc := newService(t, user)
+
+		if _, err := svc.OAuthLogin(google); !errors.Is(err, ErrPasswordResetRequired) {
+			t.Fatalf("expected %v when linking, got %v", ErrPasswordResetRequired, err)
+		}
+		if len(repo.identities) != 0 {
+			t.Fatalf("expected no identity linked")
+		}
+
+		repo.identities = []models.UserIdentity{{UserID: user.ID, Provider: google.Provider, Subject: google.Subject, Email: google.Email}}
+		if _, err := svc.OAuthLogin(google); !errors.Is(err, ErrPasswordResetRequired) {
+			t.Fatalf("expected %v for a linked identity, got %v", ErrPasswordResetRequired, err)
+		}
+		if len(repo.sessions) != 0 {
+			t.Fatalf("expected no session started")
+		}
+	})
+
+	t.Run("asks for the second factor", func(t *testing.T) {
+		existing := &models.User{Base: models.Base{ID: uuid.New()}, Email: google.Email, EmailVerifiedAt: &verifiedAt}
+		repo, svc := newService(t, existing)
+		repo.factors = map[uuid.UUID]models.TOTPFactor{existing.ID: {UserID: existing.ID, ConfirmedAt: &verifiedAt}}
+
+		res, err := svc.OAuthLogin(google)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !res.MFARequired || res.TokenPairDTO != nil {
+			t.Fatalf("expected only a challenge, got %+v", res)
+		}
+	})
+}
+
+func TestAuthService_AccountControls(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	hash, err := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	newService := func(t *testing.T) (*models.User, *fakeAuthRepository, *fakeMailer, AuthService) {
+		t.Helper()
+		user := &models.User{Base: models.Base{ID: uuid.New()}, Name: "Ana", Email: "ana@example.com", Password: string(hash)}
+		repo := &fakeAuthRepository{
+			findByEmail: func(email string) (*models.User, error) { return user, nil },
+			findByID:    func(id string) (*models.User, error) { return user, nil },
+		}
+		mailer := &fakeMailer{sent: make(chan mail.Message, 10)}
+		return user, repo, mailer, NewAuthService(repo, mailer)
+	}
+
+	t.Run("disabling signs the user out and blocks sign-in", func(t *testing.T) {
+		user, repo, _, svc := newService(t)
+		res, err := svc.Login(user.Email, "pw")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+
+		if err := svc.SetUserDisabled(user.ID, true); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.disabledAt[user.ID] == nil {
+			t.Fatalf("expected the user disabled")
+		}
+		for _, session := range repo.sessions {
+			if session.RevokedAt == nil {
+				t.Fatalf("expected every session revoked, got %+v", session)
+			}
+		}
+
+		user.DisabledAt = repo.disabledAt[user.ID]
+		if _, err := svc.Login(user.Email, "pw"); !errors.Is(err, ErrAccountDisabled) {
+			t.Fatalf("expected %v, got %v", ErrAccountDisabled, err)
+		}
+		repo.identities = []models.UserIdentity{{UserID: user.ID, Provider: "google", Subject: "1"}}
+		if _, err := svc.OAuthLogin(ExternalIdentity{Provider: "google", Subject: "1"}); !errors.Is(err, ErrAccountDisabled) {
+			t.Fatalf("expected %v for provider sign-in, got %v", ErrAccountDisabled, err)
+		}
+
+		if err := svc.SetUserDisabled(user.ID, false); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.disabledAt[user.ID] != nil {
+			t.Fatalf("expected the user enabled again")
+		}
+		user.DisabledAt = nil
+		if _, err := svc.Refresh(res.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Fatalf("expected sessions to stay revoked after enabling, got %v", err)
+		}
+		if _, err := svc.Login(user.Email, "pw"); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+	})
+
+	t.Run("refresh refuses disabled users", func(t *testing.T) {
+		user, _, _, svc := newService(t)
+		res, err := svc.Login(user.Email, "pw")
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+
+		disabledAt := time.Now()
+		user.DisabledAt = &disabledAt
+		if _, err := svc.Refresh(res.RefreshToken); !errors.Is(err, ErrAccountDisabled) {
+			t.Fatalf("expected %v, got %v", ErrAccountDisabled, err)
+		}
+	})
+
+	t.Run("forcing a reset locks the password until it is reset", func(t *testing.T) {
+		user, repo, mailer, svc := newService(t)
+		if _, err := svc.Login(user.Email, "pw"); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if _, err := svc.CreatePersonalAccessToken(user.ID, CreatePersonalAccessTokenRequestDTO{Name: "script", Scopes: []string{ScopeCardsRead}}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+
+		if err := svc.ForcePasswordReset(user.ID); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !repo.resetRequired[user.ID] {
+			t.Fatalf("expected a reset required")
+		}
+		for _, session := range repo.sessions {
+			if session.RevokedAt == nil {
+				t.Fatalf("expected every session revoked, got %+v", session)
+			}
+		}
+		for _, accessToken := range repo.accessTokens {
+			if accessToken.RevokedAt == nil {
+				t.Fatalf("expected every personal access token revoked, got %+v", accessToken)
+			}
+		}
+		token := mailedToken(t, mailer, user.Email)
+
+		user.PasswordResetRequired = true
+		if _, err := svc.Login(user.Email, "pw"); !errors.Is(err, ErrPasswordResetRequired) {
+			t.Fatalf("expected %v, got %v", ErrPasswordResetRequired, err)
+		}
+		if err := svc.ResetPassword(token, "a new password"); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+	})
+
+	t.Run("disabled users get no reset emails", func(t *testing.T) {
+		user, _, mailer, svc := newService(t)
+		disabledAt := time.Now()
+		user.DisabledAt = &disabledAt
+
+		if err := svc.ForgotPassword(user.Email); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		select {
+		case message := <-mailer.sent:
+			t.Fatalf("expected no email, got %+v", message)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
+
+func TestAuthService_PersonalAccessTokens(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("stores only a hash and shows the token once", func(t *testing.T) {
+		repo := &fakeAuthRepository{}
+		svc := NewAuthService(repo, &fakeMailer{})
+
+		created, err := svc.CreatePersonalAccessToken(userID, CreatePersonalAccessTokenRequestDTO{
+			Name:   "backup script",
+			Scopes: []string{ScopeCardsRead, ScopeCardsRead, ScopeCardsWrite},
+		})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !strings.HasPrefix(created.Token, personalAccessTokenPrefix) {
+			t.Fatalf("expected a prefixed token, got %q", created.Token)
+		}
+
+		stored := repo.accessTokens[uuid.MustParse(created.ID)]
+		if stored.TokenHash != hashSecretToken(created.Token) || strings.Contains(stored.TokenHash, created.Token) {
+			t.Fatalf("expected only the token hash stored, got %q", stored.TokenHash)
+		}
+		if stored.Scopes != "cards:read cards:write" {
+			t.Fatalf("expected deduplicated scopes, got %q", stored.Scopes)
+		}
+		if expiry := time.Until(stored.ExpiresAt); expiry < 89*24*time.Hour || expiry > 90*24*time.Hour {
+			t.Fatalf("expected the default 90 day expiry, got %v", expiry)
+		}
+
+		listed, err := svc.ListPersonalAccessTokens(userID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(listed) != 1 || listed[0].ID != created.ID || listed[0].Name != "backup script" {
+			t.Fatalf("expected the new token listed, got %+v", listed)
+		}
+	})
+
+	t.Run("honours a custom expiry", func(t *testing.T) {
+		repo := &fakeAuthRepository{}
+		svc := NewAuthService(repo, &fakeMailer{})
+
+		created, err := svc.CreatePersonalAccessToken(userID, CreatePersonalAccessTokenRequestDTO{
+			Name: "ci", Scopes: []string{ScopeLLMGenerate}, ExpiresInDays: 7,
+		})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if expiry := time.Until(repo.accessTokens[uuid.MustParse(created.ID)].ExpiresAt); expiry > 7*24*time.Hour {
+			t.Fatalf("expected a 7 day expiry, got %v", expiry)
+		}
+	})
+
+	t.Run("revokes only the user's own tokens", func(t *testing.T) {
+		repo := &fakeAuthRepository{}
+		svc := NewAuthService(repo, &fakeMailer{})
+		created, err := svc.CreatePersonalAccessToken(userID, CreatePersonalAccessTokenRequestDTO{Name: "ci", Scopes: []string{ScopeCardsRead}})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		tokenID := uuid.MustParse(created.ID)
+
+		if err := svc.RevokePersonalAccessToken(uuid.New(), tokenID); !errors.Is(err, ErrPersonalAccessTokenNotFound) {
+			t.Fatalf("expected %v for another user, got %v", ErrPersonalAccessTokenNotFound, err)
+		}
+		if err := svc.RevokePersonalAccessToken(userID, tokenID); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if err := svc.RevokePersonalAccessToken(userID, tokenID); !errors.Is(err, ErrPersonalAccessTokenNotFound) {
+			t.Fatalf("expected %v when revoked twice, got %v", ErrPersonalAccessTokenNotFound, err)
+		}
+
+		listed, _ := svc.ListPersonalAccessTokens(userID)
+		if len(listed) != 0 {
+			t.Fatalf("expected revoked tokens hidden, got %+v", listed)
+		}
+	})
+}
+
 func TestAuthService_GetUser(t *testing.T) {
-	user := &models.User{Name: "A"}
+	user := &models.User{Base: models.Base{ID: uuid.New()}, Name: "A", Password: "hash"}
 	repo := &fakeAuthRepository{
 		findByID: func(id string) (*models.User, error) {
 			if id != "123" {
@@ -181,14 +1404,14 @@ func TestAuthService_GetUser(t *testing.T) {
 			return user, nil
 		},
 	}
-	svc := NewAuthService(repo)
+	svc := NewAuthService(repo, &fakeMailer{})
 
 	got, err := svc.GetUser("123")
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
-	if got != user {
-		t.Fatalf("expected same user pointer")
+	if got.ID != user.ID.String() || got.Name != "A" || got.MFAEnabled {
+		t.Fatalf("expected the user without two-factor authentication, got %+v", got)
 	}
 }
 
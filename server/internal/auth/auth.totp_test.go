@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B, SHA1 key "12345678901234567890".
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		got, err := totpCode(secret, totpStep(time.Unix(tt.unix, 0)), 8)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got != tt.want {
+			t.Fatalf("at %d: expected %s, got %s", tt.unix, tt.want, got)
+		}
+	}
+}
+
+func TestMatchTOTP(t *testing.T) {
+	secret, err := newTOTPSecret()
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	codeAt := func(at time.Time) string {
+		code, err := totpCode(secret, totpStep(at), totpDigits)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		return code
+	}
+
+	if step, ok := matchTOTP(secret, codeAt(now), now, 0); !ok || step != totpStep(now) {
+		t.Fatalf("expected the current code to match at step %d, got %d %v", totpStep(now), step, ok)
+	}
+	if _, ok := matchTOTP(secret, codeAt(now.Add(-totpPeriod*time.Second)), now, 0); !ok {
+		t.Fatalf("expected the previous code to match")
+	}
+	if _, ok := matchTOTP(secret, codeAt(now.Add(-3*totpPeriod*time.Second)), now, 0); ok {
+		t.Fatalf("expected an old code not to match")
+	}
+	if _, ok := matchTOTP(secret, codeAt(now), now, totpStep(now)); ok {
+		t.Fatalf("expected a used code not to match again")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri, err := url.Parse(totpURI("Cards", "ana@example.com", "JBSWY3DPEHPK3PXP"))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Cards:ana@example.com" {
+		t.Fatalf("unexpected URI %s", uri)
+	}
+	if query := uri.Query(); query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "Cards" {
+		t.Fatalf("unexpected query %s", uri.RawQuery)
+	}
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, err := newRecoveryCodes()
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || strings.Count(code, "-") != 1 || seen[code] {
+			t.Fatalf("unexpected recovery codes %v", codes)
+		}
+		seen[code] = true
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("expected %d codes, got %d", recoveryCodeCount, len(codes))
+	}
+}
@@ -0,0 +1,95 @@
+package authz
+
+import (
+	"cards/internal/apperrors"
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Action is what a user wants to do with a resource.
+type Action string
+
+const (
+	// Read covers viewing a resource and everything attached to it.
+	Read Action = "read"
+	// Write covers creating and changing content: cards, comments,
+	// attachments, checklists.
+	Write Action = "write"
+	// Manage covers structural and destructive changes: board layout,
+	// purging cards, workspace settings and membership.
+	Manage Action = "manage"
+)
+
+var requiredRoles = map[Action]models.Role{
+	Read:   models.RoleViewer,
+	Write:  models.RoleMember,
+	Manage: models.RoleAdmin,
+}
+
+// Subject is the user acting, along with their role in every workspace they
+// belong to.
+type Subject struct {
+	UserID uuid.UUID
+	Roles  map[uuid.UUID]models.Role
+}
+
+func NewSubject(userID uuid.UUID, memberships []models.Membership) Subject {
+	subject := Subject{UserID: userID, Roles: make(map[uuid.UUID]models.Role, len(memberships))}
+	for _, membership := range memberships {
+		subject.Roles[membership.WorkspaceID] = membership.Role
+	}
+	return subject
+}
+
+// RoleIn returns the subject's role in the workspace, or "" for non-members.
+func (s Subject) RoleIn(workspaceID uuid.UUID) models.Role {
+	return s.Roles[workspaceID]
+}
+
+// Resource is what an action is performed on. Resources in a workspace are
+// governed by the members' roles; resources outside any workspace belong to
+// their owner alone.
+type Resource struct {
+	WorkspaceID *uuid.UUID
+	OwnerID     uuid.UUID
+}
+
+func Card(card models.Card) Resource {
+	return Resource{WorkspaceID: card.WorkspaceID, OwnerID: card.UserID}
+}
+
+func Board(board models.Board) Resource {
+	return Resource{WorkspaceID: board.WorkspaceID, OwnerID: board.UserID}
+}
+
+func Workspace(workspaceID uuid.UUID) Resource {
+	return Resource{WorkspaceID: &workspaceID}
+}
+
+// Can reports whether the subject may perform the action on the resource.
+// Subjects that cannot see the resource at all get ErrNotFound, so that its
+// existence is not leaked; subjects that can see it but lack the role for
+// the action get ErrForbidden. Both come from apperrors.
+func Can(subject Subject, action Action, resource Resource) error {
+	required, ok := requiredRoles[action]
+	if !ok {
+		return apperrors.ErrForbidden
+	}
+
+	if resource.WorkspaceID == nil {
+		if subject.UserID != resource.OwnerID {
+			return apperrors.ErrNotFound
+		}
+		return nil
+	}
+
+	role := subject.RoleIn(*resource.WorkspaceID)
+	if role == "" {
+		return apperrors.ErrNotFound
+	}
+	if !role.Allows(required) {
+		return apperrors.ErrForbidden
+	}
+	return nil
+}
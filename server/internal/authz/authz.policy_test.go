@@ -0,0 +1,51 @@
+package authz
+
+import (
+	"errors"
+	"testing"
+
+	"cards/internal/apperrors"
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestCan(t *testing.T) {
+	userID, otherID := uuid.New(), uuid.New()
+	workspaceID := uuid.New()
+
+	member := func(role models.Role) Subject {
+		return NewSubject(userID, []models.Membership{{WorkspaceID: workspaceID, UserID: userID, Role: role}})
+	}
+	shared := Card(models.Card{UserID: otherID, WorkspaceID: &workspaceID})
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		action   Action
+		resource Resource
+		want     error
+	}{
+		{"viewer reads", member(models.RoleViewer), Read, shared, nil},
+		{"viewer writes", member(models.RoleViewer), Write, shared, apperrors.ErrForbidden},
+		{"member writes", member(models.RoleMember), Write, shared, nil},
+		{"member manages", member(models.RoleMember), Manage, shared, apperrors.ErrForbidden},
+		{"admin manages", member(models.RoleAdmin), Manage, shared, nil},
+		{"owner manages", member(models.RoleOwner), Manage, Workspace(workspaceID), nil},
+		{"non-member reads", NewSubject(userID, nil), Read, shared, apperrors.ErrNotFound},
+		{"member of another workspace", member(models.RoleOwner), Read, Workspace(uuid.New()), apperrors.ErrNotFound},
+		{"author outside the workspace", NewSubject(otherID, nil), Read, shared, apperrors.ErrNotFound},
+		{"owner of an unshared resource", Subject{UserID: userID}, Manage, Resource{OwnerID: userID}, nil},
+		{"stranger to an unshared resource", member(models.RoleOwner), Read, Resource{OwnerID: otherID}, apperrors.ErrNotFound},
+		{"owner of a board outside workspaces", Subject{UserID: userID}, Write, Board(models.Board{UserID: userID}), nil},
+		{"unknown action", member(models.RoleOwner), Action("delete"), shared, apperrors.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Can(tt.subject, tt.action, tt.resource); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package boards
+
+import "github.com/google/uuid"
+
+type ListBoardsQuery struct {
+	WorkspaceID string `form:"workspace_id" binding:"omitempty,uuid"`
+}
+
+type CreateBoardDTO struct {
+	Name        string     `json:"name" binding:"required"`
+	Columns     []string   `json:"columns"`
+	WorkspaceID *uuid.UUID `json:"workspace_id"`
+}
+
+type UpdateBoardDTO struct {
+	Name *string `json:"name"`
+}
+
+type CreateColumnDTO struct {
+	Name     string `json:"name" binding:"required"`
+	Position *int   `json:"position" binding:"omitempty,min=0"`
+}
+
+type UpdateColumnDTO struct {
+	Name     *string `json:"name"`
+	Position *int    `json:"position" binding:"omitempty,min=0"`
+}
@@ -0,0 +1,235 @@
+package boards
+
+import (
+	"cards/internal/apperrors"
+	"cards/internal/params"
+	"cards/internal/types"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BoardsHandler interface {
+	List(c *gin.Context)
+	GetByID(c *gin.Context)
+	Create(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	AddColumn(c *gin.Context)
+	UpdateColumn(c *gin.Context)
+	DeleteColumn(c *gin.Context)
+}
+
+type boardsHandler struct {
+	Service BoardsService
+}
+
+func NewBoardsHandler(service BoardsService) BoardsHandler {
+	return &boardsHandler{Service: service}
+}
+
+func (h *boardsHandler) List(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var query ListBoardsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apperrors.Respond(c, "Invalid query parameters", apperrors.Binding(err))
+		return
+	}
+
+	boards, err := h.Service.List(userID, query)
+	if err != nil {
+		apperrors.Respond(c, "Failed to list boards", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Boards listed successfully", boards, nil))
+}
+
+func (h *boardsHandler) GetByID(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	boardID, err := params.UUID(c, "boardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid board ID", err)
+		return
+	}
+
+	board, err := h.Service.GetByID(userID, boardID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to get board", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Board retrieved successfully", board, nil))
+}
+
+func (h *boardsHandler) Create(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto CreateBoardDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	board, err := h.Service.Create(userID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to create board", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewApiResponse(http.StatusCreated, "Board created successfully", board, nil))
+}
+
+func (h *boardsHandler) Update(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto UpdateBoardDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	boardID, err := params.UUID(c, "boardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid board ID", err)
+		return
+	}
+
+	board, err := h.Service.Update(userID, boardID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to update board", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Board updated successfully", board, nil))
+}
+
+func (h *boardsHandler) Delete(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	boardID, err := params.UUID(c, "boardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid board ID", err)
+		return
+	}
+
+	board, err := h.Service.Delete(userID, boardID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to delete board", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Board deleted successfully", board, nil))
+}
+
+func (h *boardsHandler) AddColumn(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto CreateColumnDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	boardID, err := params.UUID(c, "boardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid board ID", err)
+		return
+	}
+
+	board, err := h.Service.AddColumn(userID, boardID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to create column", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewApiResponse(http.StatusCreated, "Column created successfully", board, nil))
+}
+
+func (h *boardsHandler) UpdateColumn(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto UpdateColumnDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	boardID, err := params.UUID(c, "boardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid board ID", err)
+		return
+	}
+
+	columnID, err := params.UUID(c, "columnID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid column ID", err)
+		return
+	}
+
+	board, err := h.Service.UpdateColumn(userID, boardID, columnID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to update column", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Column updated successfully", board, nil))
+}
+
+func (h *boardsHandler) DeleteColumn(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	boardID, err := params.UUID(c, "boardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid board ID", err)
+		return
+	}
+
+	columnID, err := params.UUID(c, "columnID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid column ID", err)
+		return
+	}
+
+	board, err := h.Service.DeleteColumn(userID, boardID, columnID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to delete column", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Column deleted successfully", board, nil))
+}
@@ -0,0 +1,115 @@
+package boards
+
+import (
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BoardsRepository interface {
+	FindByID(uuid.UUID) (models.Board, error)
+	FindDefaultByUserID(uuid.UUID) (models.Board, error)
+	ListByWorkspaceID(uuid.UUID) ([]models.Board, error)
+	Create(*models.Board) error
+	Update(*models.Board) error
+	Delete(uuid.UUID) error
+	FindColumnByID(uuid.UUID) (models.Column, error)
+	CountCardsInColumn(uuid.UUID) (int64, error)
+	CreateColumn(*models.Column) error
+	SaveColumns([]models.Column) error
+	DeleteColumn(uuid.UUID) error
+}
+
+type boardsRepository struct {
+	db *gorm.DB
+}
+
+func NewBoardsRepository(db *gorm.DB) BoardsRepository {
+	return &boardsRepository{db: db}
+}
+
+func orderedColumns(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+func (r *boardsRepository) FindByID(id uuid.UUID) (models.Board, error) {
+	var board models.Board
+	if err := r.db.Preload("Columns", orderedColumns).Where("id = ?", id).First(&board).Error; err != nil {
+		return models.Board{}, err
+	}
+	return board, nil
+}
+
+func (r *boardsRepository) FindDefaultByUserID(userID uuid.UUID) (models.Board, error) {
+	var board models.Board
+	if err := r.db.Preload("Columns", orderedColumns).Where("user_id = ? AND is_default = ?", userID, true).First(&board).Error; err != nil {
+		return models.Board{}, err
+	}
+	return board, nil
+}
+
+func (r *boardsRepository) ListByWorkspaceID(workspaceID uuid.UUID) ([]models.Board, error) {
+	var boards []models.Board
+	if err := r.db.Preload("Columns", orderedColumns).Where("workspace_id = ?", workspaceID).Order("created_at").Find(&boards).Error; err != nil {
+		return nil, err
+	}
+	return boards, nil
+}
+
+func (r *boardsRepository) Create(board *models.Board) error {
+	return r.db.Create(board).Error
+}
+
+func (r *boardsRepository) Update(board *models.Board) error {
+	return r.db.Omit("Columns").Save(board).Error
+}
+
+// Delete moves the board's cards to the trash rather than removing them, so
+// they can still be restored (into the default board) until purged.
+func (r *boardsRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("board_id = ?", id).Delete(&models.Card{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("board_id = ?", id).Delete(&models.Column{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Board{Base: models.Base{ID: id}}).Error
+	})
+}
+
+func (r *boardsRepository) FindColumnByID(id uuid.UUID) (models.Column, error) {
+	var column models.Column
+	if err := r.db.Where("id = ?", id).First(&column).Error; err != nil {
+		return models.Column{}, err
+	}
+	return column, nil
+}
+
+func (r *boardsRepository) CountCardsInColumn(columnID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.Card{}).Where("column_id = ?", columnID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *boardsRepository) CreateColumn(column *models.Column) error {
+	return r.db.Create(column).Error
+}
+
+func (r *boardsRepository) SaveColumns(columns []models.Column) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range columns {
+			if err := tx.Save(&columns[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *boardsRepository) DeleteColumn(id uuid.UUID) error {
+	return r.db.Delete(&models.Column{Base: models.Base{ID: id}}).Error
+}
@@ -0,0 +1,26 @@
+package boards
+
+import (
+	"cards/internal/auth"
+	"cards/internal/workspaces"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func RegisterBoardsRoutes(appGroup *gin.RouterGroup, db *gorm.DB, authMiddleware auth.Middleware) {
+	repository := NewBoardsRepository(db)
+	service := NewBoardsService(repository, workspaces.NewWorkspacesService(workspaces.NewWorkspacesRepository(db), nil))
+	handler := NewBoardsHandler(service)
+
+	boardsGroup := appGroup.Group("/boards")
+	boardsGroup.Use(authMiddleware.Authenticate())
+	boardsGroup.GET("/list", handler.List)
+	boardsGroup.GET("/by_id/:boardID", handler.GetByID)
+	boardsGroup.POST("/create", handler.Create)
+	boardsGroup.PATCH("/update/:boardID", handler.Update)
+	boardsGroup.DELETE("/delete/:boardID", handler.Delete)
+	boardsGroup.POST("/:boardID/columns/create", handler.AddColumn)
+	boardsGroup.PATCH("/:boardID/columns/update/:columnID", handler.UpdateColumn)
+	boardsGroup.DELETE("/:boardID/columns/delete/:columnID", handler.DeleteColumn)
+}
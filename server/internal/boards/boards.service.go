@@ -0,0 +1,294 @@
+package boards
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"cards/internal/apperrors"
+	"cards/internal/authz"
+	"cards/internal/models"
+	"cards/internal/workspaces"
+)
+
+type BoardsService interface {
+	List(userID uuid.UUID, query ListBoardsQuery) ([]models.Board, error)
+	GetByID(userID uuid.UUID, boardID uuid.UUID) (*models.Board, error)
+	Authorize(userID uuid.UUID, boardID uuid.UUID, action authz.Action) (*models.Board, error)
+	EnsureDefaultBoard(userID uuid.UUID) (*models.Board, error)
+	Create(userID uuid.UUID, dto CreateBoardDTO) (*models.Board, error)
+	Update(userID uuid.UUID, boardID uuid.UUID, dto UpdateBoardDTO) (*models.Board, error)
+	Delete(userID uuid.UUID, boardID uuid.UUID) (*models.Board, error)
+	GetColumn(userID uuid.UUID, columnID uuid.UUID) (*models.Column, error)
+	AddColumn(userID uuid.UUID, boardID uuid.UUID, dto CreateColumnDTO) (*models.Board, error)
+	UpdateColumn(userID uuid.UUID, boardID uuid.UUID, columnID uuid.UUID, dto UpdateColumnDTO) (*models.Board, error)
+	DeleteColumn(userID uuid.UUID, boardID uuid.UUID, columnID uuid.UUID) (*models.Board, error)
+}
+
+type boardsService struct {
+	Repository BoardsRepository
+	Workspaces workspaces.WorkspacesService
+}
+
+func NewBoardsService(repository BoardsRepository, workspacesService workspaces.WorkspacesService) BoardsService {
+	return &boardsService{Repository: repository, Workspaces: workspacesService}
+}
+
+func (s *boardsService) List(userID uuid.UUID, query ListBoardsQuery) ([]models.Board, error) {
+	workspaceID, err := s.Workspaces.Resolve(userID, query.WorkspaceID, authz.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.WorkspaceID == "" {
+		if _, err := s.EnsureDefaultBoard(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.Repository.ListByWorkspaceID(workspaceID)
+}
+
+func (s *boardsService) GetByID(userID uuid.UUID, boardID uuid.UUID) (*models.Board, error) {
+	return s.Authorize(userID, boardID, authz.Read)
+}
+
+// Authorize returns the board when the user may perform the action on it.
+func (s *boardsService) Authorize(userID uuid.UUID, boardID uuid.UUID, action authz.Action) (*models.Board, error) {
+	board, err := s.Repository.FindByID(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := s.Workspaces.Subject(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authz.Can(subject, action, authz.Board(board)); err != nil {
+		return nil, err
+	}
+
+	return &board, nil
+}
+
+func (s *boardsService) EnsureDefaultBoard(userID uuid.UUID) (*models.Board, error) {
+	board, err := s.Repository.FindDefaultByUserID(userID)
+	if err == nil {
+		return &board, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	workspace, err := s.Workspaces.EnsurePersonal(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	board = newBoard(userID, models.DefaultBoardName, models.DefaultBoardColumns)
+	board.IsDefault = true
+	board.WorkspaceID = &workspace.ID
+	if err := s.Repository.Create(&board); err != nil {
+		return nil, err
+	}
+
+	return &board, nil
+}
+
+func (s *boardsService) Create(userID uuid.UUID, dto CreateBoardDTO) (*models.Board, error) {
+	var workspaceID uuid.UUID
+	if dto.WorkspaceID != nil {
+		if _, err := s.Workspaces.Authorize(userID, *dto.WorkspaceID, authz.Manage); err != nil {
+			return nil, err
+		}
+		workspaceID = *dto.WorkspaceID
+	} else {
+		workspace, err := s.Workspaces.EnsurePersonal(userID)
+		if err != nil {
+			return nil, err
+		}
+		workspaceID = workspace.ID
+	}
+
+	columns := dto.Columns
+	if len(columns) == 0 {
+		columns = models.DefaultBoardColumns
+	}
+
+	board := newBoard(userID, dto.Name, columns)
+	board.WorkspaceID = &workspaceID
+	if err := s.Repository.Create(&board); err != nil {
+		return nil, err
+	}
+
+	return &board, nil
+}
+
+func (s *boardsService) Update(userID uuid.UUID, boardID uuid.UUID, dto UpdateBoardDTO) (*models.Board, error) {
+	board, err := s.Authorize(userID, boardID, authz.Manage)
+	if err != nil {
+		return nil, err
+	}
+
+	if dto.Name != nil {
+		board.Name = *dto.Name
+	}
+
+	if err := s.Repository.Update(board); err != nil {
+		return nil, err
+	}
+
+	return board, nil
+}
+
+func (s *boardsService) Delete(userID uuid.UUID, boardID uuid.UUID) (*models.Board, error) {
+	board, err := s.Authorize(userID, boardID, authz.Manage)
+	if err != nil {
+		return nil, err
+	}
+
+	if board.IsDefault {
+		return nil, apperrors.Conflict("default board cannot be deleted")
+	}
+
+	if err := s.Repository.Delete(boardID); err != nil {
+		return nil, err
+	}
+
+	return board, nil
+}
+
+func (s *boardsService) GetColumn(userID uuid.UUID, columnID uuid.UUID) (*models.Column, error) {
+	column, err := s.Repository.FindColumnByID(columnID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetByID(userID, column.BoardID); err != nil {
+		return nil, err
+	}
+
+	return &column, nil
+}
+
+func (s *boardsService) AddColumn(userID uuid.UUID, boardID uuid.UUID, dto CreateColumnDTO) (*models.Board, error) {
+	board, err := s.Authorize(userID, boardID, authz.Manage)
+	if err != nil {
+		return nil, err
+	}
+
+	column := models.Column{Name: dto.Name, Position: len(board.Columns), BoardID: board.ID}
+	if err := s.Repository.CreateColumn(&column); err != nil {
+		return nil, err
+	}
+
+	position := column.Position
+	if dto.Position != nil {
+		position = *dto.Position
+	}
+	board.Columns = moveColumn(append(board.Columns, column), column.ID, position)
+	if err := s.Repository.SaveColumns(board.Columns); err != nil {
+		return nil, err
+	}
+
+	return board, nil
+}
+
+func (s *boardsService) UpdateColumn(userID uuid.UUID, boardID uuid.UUID, columnID uuid.UUID, dto UpdateColumnDTO) (*models.Board, error) {
+	board, err := s.Authorize(userID, boardID, authz.Manage)
+	if err != nil {
+		return nil, err
+	}
+
+	index := columnIndex(board.Columns, columnID)
+	if index < 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if dto.Name != nil {
+		board.Columns[index].Name = *dto.Name
+	}
+	if dto.Position != nil {
+		board.Columns = moveColumn(board.Columns, columnID, *dto.Position)
+	}
+
+	if err := s.Repository.SaveColumns(board.Columns); err != nil {
+		return nil, err
+	}
+
+	return board, nil
+}
+
+func (s *boardsService) DeleteColumn(userID uuid.UUID, boardID uuid.UUID, columnID uuid.UUID) (*models.Board, error) {
+	board, err := s.Authorize(userID, boardID, authz.Manage)
+	if err != nil {
+		return nil, err
+	}
+
+	index := columnIndex(board.Columns, columnID)
+	if index < 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if len(board.Columns) == 1 {
+		return nil, apperrors.Conflict("board must keep at least one column")
+	}
+
+	count, err := s.Repository.CountCardsInColumn(columnID)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, apperrors.Conflict("column still has cards")
+	}
+
+	if err := s.Repository.DeleteColumn(columnID); err != nil {
+		return nil, err
+	}
+
+	board.Columns = append(board.Columns[:index], board.Columns[index+1:]...)
+	for i := range board.Columns {
+		board.Columns[i].Position = i
+	}
+	if err := s.Repository.SaveColumns(board.Columns); err != nil {
+		return nil, err
+	}
+
+	return board, nil
+}
+
+func newBoard(userID uuid.UUID, name string, columnNames []string) models.Board {
+	board := models.Board{Name: name, UserID: userID}
+	for i, columnName := range columnNames {
+		board.Columns = append(board.Columns, models.Column{Name: columnName, Position: i})
+	}
+	return board
+}
+
+func columnIndex(columns []models.Column, columnID uuid.UUID) int {
+	for i, column := range columns {
+		if column.ID == columnID {
+			return i
+		}
+	}
+	return -1
+}
+
+func moveColumn(columns []models.Column, columnID uuid.UUID, position int) []models.Column {
+	index := columnIndex(columns, columnID)
+	if index < 0 {
+		return columns
+	}
+
+	column := columns[index]
+	rest := append(append([]models.Column{}, columns[:index]...), columns[index+1:]...)
+	if position > len(rest) {
+		position = len(rest)
+	}
+
+	ordered := append(append(append([]models.Column{}, rest[:position]...), column), rest[position:]...)
+	for i := range ordered {
+		ordered[i].Position = i
+	}
+	return ordered
+}
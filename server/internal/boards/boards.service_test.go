@@ -0,0 +1,394 @@
+package boards
+
+import (
+	"errors"
+	"testing"
+
+	"cards/internal/apperrors"
+	"cards/internal/authz"
+	"cards/internal/models"
+	"cards/internal/workspaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeBoardsRepository struct {
+	boards     map[uuid.UUID]models.Board
+	cardCounts map[uuid.UUID]int64
+
+	created      *models.Board
+	savedColumns []models.Column
+	deletedID    uuid.UUID
+}
+
+func newFakeBoardsRepository(boards ...models.Board) *fakeBoardsRepository {
+	r := &fakeBoardsRepository{boards: map[uuid.UUID]models.Board{}, cardCounts: map[uuid.UUID]int64{}}
+	for _, board := range boards {
+		r.boards[board.ID] = board
+	}
+	return r
+}
+
+func (r *fakeBoardsRepository) FindByID(id uuid.UUID) (models.Board, error) {
+	board, ok := r.boards[id]
+	if !ok {
+		return models.Board{}, gorm.ErrRecordNotFound
+	}
+	board.Columns = append([]models.Column{}, board.Columns...)
+	return board, nil
+}
+
+func (r *fakeBoardsRepository) FindDefaultByUserID(userID uuid.UUID) (models.Board, error) {
+	for _, board := range r.boards {
+		if board.UserID == userID && board.IsDefault {
+			return board, nil
+		}
+	}
+	return models.Board{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeBoardsRepository) ListByWorkspaceID(workspaceID uuid.UUID) ([]models.Board, error) {
+	var boards []models.Board
+	for _, board := range r.boards {
+		if board.WorkspaceID != nil && *board.WorkspaceID == workspaceID {
+			boards = append(boards, board)
+		}
+	}
+	return boards, nil
+}
+
+func (r *fakeBoardsRepository) Create(board *models.Board) error {
+	board.ID = uuid.New()
+	for i := range board.Columns {
+		board.Columns[i].ID = uuid.New()
+		board.Columns[i].BoardID = board.ID
+	}
+	r.created = board
+	r.boards[board.ID] = *board
+	return nil
+}
+
+func (r *fakeBoardsRepository) Update(board *models.Board) error {
+	r.boards[board.ID] = *board
+	return nil
+}
+
+func (r *fakeBoardsRepository) Delete(id uuid.UUID) error {
+	r.deletedID = id
+	delete(r.boards, id)
+	return nil
+}
+
+func (r *fakeBoardsRepository) FindColumnByID(id uuid.UUID) (models.Column, error) {
+	for _, board := range r.boards {
+		for _, column := range board.Columns {
+			if column.ID == id {
+				return column, nil
+			}
+		}
+	}
+	return models.Column{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeBoardsRepository) CountCardsInColumn(columnID uuid.UUID) (int64, error) {
+	return r.cardCounts[columnID], nil
+}
+
+func (r *fakeBoardsRepository) CreateColumn(column *models.Column) error {
+	column.ID = uuid.New()
+	return nil
+}
+
+func (r *fakeBoardsRepository) SaveColumns(columns []models.Column) error {
+	r.savedColumns = columns
+	return nil
+}
+
+func (r *fakeBoardsRepository) DeleteColumn(id uuid.UUID) error {
+	return nil
+}
+
+// fakeWorkspacesService makes every user the owner of the shared personal
+// workspace; other memberships are added with join.
+type fakeWorkspacesService struct {
+	workspaces.WorkspacesService
+	personalID  uuid.UUID
+	memberships []models.Membership
+}
+
+func newFakeWorkspacesService() *fakeWorkspacesService {
+	return &fakeWorkspacesService{personalID: uuid.New()}
+}
+
+func (f *fakeWorkspacesService) join(workspaceID uuid.UUID, userID uuid.UUID, role models.Role) {
+	f.memberships = append(f.memberships, models.Membership{WorkspaceID: workspaceID, UserID: userID, Role: role})
+}
+
+func (f *fakeWorkspacesService) EnsurePersonal(userID uuid.UUID) (*models.Workspace, error) {
+	return &models.Workspace{Base: models.Base{ID: f.personalID}, OwnerID: userID, IsPersonal: true}, nil
+}
+
+func (f *fakeWorkspacesService) Subject(userID uuid.UUID) (authz.Subject, error) {
+	memberships := []models.Membership{{WorkspaceID: f.personalID, UserID: userID, Role: models.RoleOwner}}
+	for _, membership := range f.memberships {
+		if membership.UserID == userID {
+			memberships = append(memberships, membership)
+		}
+	}
+	return authz.NewSubject(userID, memberships), nil
+}
+
+func (f *fakeWorkspacesService) Authorize(userID uuid.UUID, workspaceID uuid.UUID, action authz.Action) (authz.Subject, error) {
+	subject, _ := f.Subject(userID)
+	if err := authz.Can(subject, action, authz.Workspace(workspaceID)); err != nil {
+		return authz.Subject{}, err
+	}
+	return subject, nil
+}
+
+func (f *fakeWorkspacesService) Resolve(userID uuid.UUID, workspaceID string, action authz.Action) (uuid.UUID, error) {
+	if workspaceID == "" {
+		return f.personalID, nil
+	}
+	id := uuid.MustParse(workspaceID)
+	if _, err := f.Authorize(userID, id, action); err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+func testBoard(userID uuid.UUID, isDefault bool, columnNames ...string) models.Board {
+	board := newBoard(userID, "Board", columnNames)
+	board.ID = uuid.New()
+	board.IsDefault = isDefault
+	for i := range board.Columns {
+		board.Columns[i].ID = uuid.New()
+		board.Columns[i].BoardID = board.ID
+	}
+	return board
+}
+
+func columnNames(columns []models.Column) []string {
+	var names []string
+	for i, column := range columns {
+		if column.Position != i {
+			return nil
+		}
+		names = append(names, column.Name)
+	}
+	return names
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBoardsService_EnsureDefaultBoard(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("creates default board with status columns", func(t *testing.T) {
+		repo := newFakeBoardsRepository()
+		svc := NewBoardsService(repo, newFakeWorkspacesService())
+
+		board, err := svc.EnsureDefaultBoard(userID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.created == nil || !board.IsDefault || board.UserID != userID {
+			t.Fatalf("unexpected board: %+v", board)
+		}
+		if got := columnNames(board.Columns); !equalNames(got, models.DefaultBoardColumns) {
+			t.Fatalf("expected columns %v, got %v", models.DefaultBoardColumns, got)
+		}
+	})
+
+	t.Run("reuses existing default board", func(t *testing.T) {
+		existing := testBoard(userID, true, "todo")
+		repo := newFakeBoardsRepository(existing)
+		svc := NewBoardsService(repo, newFakeWorkspacesService())
+
+		board, err := svc.EnsureDefaultBoard(userID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.created != nil || board.ID != existing.ID {
+			t.Fatalf("expected existing board to be reused")
+		}
+	})
+}
+
+func TestBoardsService_GetByID(t *testing.T) {
+	board := testBoard(uuid.New(), false, "todo")
+	svc := NewBoardsService(newFakeBoardsRepository(board), newFakeWorkspacesService())
+
+	_, err := svc.GetByID(uuid.New(), board.ID)
+	if !errors.Is(err, apperrors.ErrNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestBoardsService_Delete(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("refuses to delete the default board", func(t *testing.T) {
+		board := testBoard(userID, true, "todo")
+		repo := newFakeBoardsRepository(board)
+		svc := NewBoardsService(repo, newFakeWorkspacesService())
+
+		if _, err := svc.Delete(userID, board.ID); err == nil {
+			t.Fatalf("expected error")
+		}
+		if repo.deletedID != uuid.Nil {
+			t.Fatalf("did not expect Delete call")
+		}
+	})
+
+	t.Run("deletes other boards", func(t *testing.T) {
+		board := testBoard(userID, false, "todo")
+		repo := newFakeBoardsRepository(board)
+		svc := NewBoardsService(repo, newFakeWorkspacesService())
+
+		if _, err := svc.Delete(userID, board.ID); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.deletedID != board.ID {
+			t.Fatalf("expected Delete called with %s", board.ID)
+		}
+	})
+}
+
+func TestBoardsService_Columns(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("inserts a column at the requested position", func(t *testing.T) {
+		board := testBoard(userID, false, "a", "b", "c")
+		repo := newFakeBoardsRepository(board)
+		svc := NewBoardsService(repo, newFakeWorkspacesService())
+
+		position := 1
+		got, err := svc.AddColumn(userID, board.ID, CreateColumnDTO{Name: "new", Position: &position})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if names := columnNames(got.Columns); !equalNames(names, []string{"a", "new", "b", "c"}) {
+			t.Fatalf("unexpected column order: %v", names)
+		}
+	})
+
+	t.Run("reorders columns", func(t *testing.T) {
+		board := testBoard(userID, false, "a", "b", "c")
+		repo := newFakeBoardsRepository(board)
+		svc := NewBoardsService(repo, newFakeWorkspacesService())
+
+		position := 5
+		got, err := svc.UpdateColumn(userID, board.ID, board.Columns[0].ID, UpdateColumnDTO{Position: &position})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if names := columnNames(repo.savedColumns); !equalNames(names, []string{"b", "c", "a"}) {
+			t.Fatalf("unexpected column order: %v", names)
+		}
+		if len(got.Columns) != 3 {
+			t.Fatalf("expected 3 columns, got %d", len(got.Columns))
+		}
+	})
+
+	t.Run("refuses to delete a column with cards", func(t *testing.T) {
+		board := testBoard(userID, false, "a", "b")
+		repo := newFakeBoardsRepository(board)
+		repo.cardCounts[board.Columns[0].ID] = 2
+		svc := NewBoardsService(repo, newFakeWorkspacesService())
+
+		if _, err := svc.DeleteColumn(userID, board.ID, board.Columns[0].ID); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("refuses to delete the last column", func(t *testing.T) {
+		board := testBoard(userID, false, "a")
+		svc := NewBoardsService(newFakeBoardsRepository(board), newFakeWorkspacesService())
+
+		if _, err := svc.DeleteColumn(userID, board.ID, board.Columns[0].ID); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("returns not found for unknown columns", func(t *testing.T) {
+		board := testBoard(userID, false, "a")
+		svc := NewBoardsService(newFakeBoardsRepository(board), newFakeWorkspacesService())
+
+		_, err := svc.UpdateColumn(userID, board.ID, uuid.New(), UpdateColumnDTO{})
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestBoardsService_Workspaces(t *testing.T) {
+	ownerID := uuid.New()
+	workspaceID := uuid.New()
+	board := testBoard(ownerID, false, "todo")
+	board.WorkspaceID = &workspaceID
+
+	t.Run("default board lives in the personal workspace", func(t *testing.T) {
+		workspacesSvc := newFakeWorkspacesService()
+		svc := NewBoardsService(newFakeBoardsRepository(), workspacesSvc)
+
+		got, err := svc.EnsureDefaultBoard(ownerID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got.WorkspaceID == nil || *got.WorkspaceID != workspacesSvc.personalID {
+			t.Fatalf("expected board in the personal workspace, got %v", got.WorkspaceID)
+		}
+	})
+
+	t.Run("members see shared boards but only admins change them", func(t *testing.T) {
+		memberID := uuid.New()
+		workspacesSvc := newFakeWorkspacesService()
+		workspacesSvc.join(workspaceID, memberID, models.RoleMember)
+		repo := newFakeBoardsRepository(board)
+		svc := NewBoardsService(repo, workspacesSvc)
+
+		if _, err := svc.GetByID(memberID, board.ID); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		listed, err := svc.List(memberID, ListBoardsQuery{WorkspaceID: workspaceID.String()})
+		if err != nil || len(listed) != 1 {
+			t.Fatalf("expected the shared board, got %v (%v)", listed, err)
+		}
+		name := "renamed"
+		if _, err := svc.Update(memberID, board.ID, UpdateBoardDTO{Name: &name}); !errors.Is(err, apperrors.ErrForbidden) {
+			t.Fatalf("expected forbidden error, got %v", err)
+		}
+
+		workspacesSvc.join(workspaceID, memberID, models.RoleAdmin)
+		if _, err := svc.Update(memberID, board.ID, UpdateBoardDTO{Name: &name}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+	})
+
+	t.Run("creates boards in a workspace for admins", func(t *testing.T) {
+		adminID := uuid.New()
+		workspacesSvc := newFakeWorkspacesService()
+		workspacesSvc.join(workspaceID, adminID, models.RoleAdmin)
+		svc := NewBoardsService(newFakeBoardsRepository(), workspacesSvc)
+
+		got, err := svc.Create(adminID, CreateBoardDTO{Name: "Team", WorkspaceID: &workspaceID})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got.WorkspaceID == nil || *got.WorkspaceID != workspaceID {
+			t.Fatalf("expected board in workspace %s, got %v", workspaceID, got.WorkspaceID)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package cards
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"cards/internal/apperrors"
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	dueDateLast  = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	dueDateFirst = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+var errInvalidCursor = apperrors.Validation("invalid cursor")
+
+// cardsCursor holds the sort key of the last card of a page. It is handed to
+// clients as an opaque base64 string and only valid for the same sort.
+type cardsCursor struct {
+	Sort      cardSort  `json:"s"`
+	Desc      bool      `json:"d"`
+	Rank      string    `json:"r,omitempty"`
+	Priority  int16     `json:"p,omitempty"`
+	Time      time.Time `json:"t"`
+	CreatedAt time.Time `json:"c"`
+	ID        uuid.UUID `json:"i"`
+}
+
+func newCardsCursor(card models.Card, sort cardSort, desc bool) cardsCursor {
+	cursor := cardsCursor{
+		Sort:      sort,
+		Desc:      desc,
+		Rank:      card.Rank,
+		Priority:  int16(card.Priority),
+		CreatedAt: card.CreatedAt,
+		ID:        card.ID,
+	}
+
+	switch sort {
+	case CardSortUpdatedAt:
+		cursor.Time = card.UpdatedAt
+	case CardSortDueDate:
+		cursor.Time = dueDateSortValue(card.DueAt, desc)
+	}
+
+	return cursor
+}
+
+// dueDateSortValue mirrors the COALESCE used when sorting by due date, which
+// keeps cards without a due date at the end in both directions.
+func dueDateSortValue(dueAt *time.Time, desc bool) time.Time {
+	if dueAt != nil {
+		return *dueAt
+	}
+	if desc {
+		return dueDateFirst
+	}
+	return dueDateLast
+}
+
+func (c cardsCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCardsCursor(value string) (*cardsCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var cursor cardsCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, errInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// searchCursor pages through relevance-ordered search results by offset, since
+// ranks are floats that change as cards are edited.
+type searchCursor struct {
+	Offset int `json:"o"`
+}
+
+func (c searchCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSearchCursor(value string) (*searchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var cursor searchCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Offset < 0 {
+		return nil, errInvalidCursor
+	}
+
+	return &cursor, nil
+}
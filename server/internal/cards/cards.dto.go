@@ -1,5 +1,13 @@
 package cards
 
+import (
+	"time"
+
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+)
+
 type cardStatus string
 
 const (
@@ -8,21 +16,226 @@ const (
 	CardStatusDone   cardStatus = "done"
 )
 
+func (s cardStatus) valid() bool {
+	switch s {
+	case CardStatusUndone, CardStatusDoing, CardStatusDone:
+		return true
+	}
+	return false
+}
+
 type SimpleCardResponseDTO struct {
-	Title   string     `json:"title" binding:"required"`
-	Content string     `json:"content" binding:"required"`
-	Status  cardStatus `json:"status" binding:"oneof=undone doing done"`
+	Title             string                   `json:"title" binding:"required"`
+	Content           string                   `json:"content" binding:"required"`
+	Status            cardStatus               `json:"status" binding:"oneof=undone doing done"`
+	Tags              []string                 `json:"tags,omitempty"`
+	DueAt             *time.Time               `json:"due_at,omitempty"`
+	Priority          models.Priority          `json:"priority"`
+	Checklist         []string                 `json:"checklist,omitempty"`
+	ChecklistProgress models.ChecklistProgress `json:"checklist_progress"`
+}
+
+// CardResponseDTO is a card as the API returns it. Handlers never send
+// models.Card itself, so relations on the model, like its User, stay out of
+// responses.
+type CardResponseDTO struct {
+	ID                uuid.UUID                `json:"id"`
+	CreatedAt         time.Time                `json:"created_at"`
+	UpdatedAt         time.Time                `json:"updated_at"`
+	Title             string                   `json:"title"`
+	Content           string                   `json:"content"`
+	Status            string                   `json:"status"`
+	UserID            uuid.UUID                `json:"user_id"`
+	WorkspaceID       *uuid.UUID               `json:"workspace_id"`
+	BoardID           *uuid.UUID               `json:"board_id"`
+	ColumnID          *uuid.UUID               `json:"column_id"`
+	Rank              string                   `json:"rank"`
+	DueAt             *time.Time               `json:"due_at"`
+	Priority          models.Priority          `json:"priority"`
+	Tags              []models.Tag             `json:"tags"`
+	ArchivedAt        *time.Time               `json:"archived_at,omitempty"`
+	DeletedAt         *time.Time               `json:"deleted_at,omitempty"`
+	Checklist         []models.ChecklistItem   `json:"checklist"`
+	ChecklistProgress models.ChecklistProgress `json:"checklist_progress"`
+}
+
+func newCardResponse(card models.Card) CardResponseDTO {
+	res := CardResponseDTO{
+		ID:                card.ID,
+		CreatedAt:         card.CreatedAt,
+		UpdatedAt:         card.UpdatedAt,
+		Title:             card.Title,
+		Content:           card.Content,
+		Status:            card.Status,
+		UserID:            card.UserID,
+		WorkspaceID:       card.WorkspaceID,
+		BoardID:           card.BoardID,
+		ColumnID:          card.ColumnID,
+		Rank:              card.Rank,
+		DueAt:             card.DueAt,
+		Priority:          card.Priority,
+		Tags:              card.Tags,
+		ArchivedAt:        card.ArchivedAt,
+		Checklist:         card.Checklist,
+		ChecklistProgress: card.ChecklistProgress,
+	}
+	if card.DeletedAt.Valid {
+		deletedAt := card.DeletedAt.Time
+		res.DeletedAt = &deletedAt
+	}
+	return res
+}
+
+func newCardResponses(cards []models.Card) []CardResponseDTO {
+	res := make([]CardResponseDTO, len(cards))
+	for i, card := range cards {
+		res[i] = newCardResponse(card)
+	}
+	return res
 }
 
 type CreateCardDTO struct {
-	Title   string `json:"title" binding:"required"`
-	Content string `json:"content" binding:"required"`
+	Title     string           `json:"title" binding:"required"`
+	Content   string           `json:"content" binding:"required"`
+	BoardID   *uuid.UUID       `json:"board_id"`
+	ColumnID  *uuid.UUID       `json:"column_id"`
+	Tags      []string         `json:"tags"`
+	DueAt     *time.Time       `json:"due_at"`
+	Priority  *models.Priority `json:"priority"`
+	Checklist []string         `json:"checklist" binding:"omitempty,dive,required"`
 }
 
 type UpdateCardDTO struct {
-	Title   *string     `json:"title"`
-	Content *string     `json:"content"`
-	Status  *cardStatus `json:"status" binding:"oneof=undone doing done"`
+	Title      *string          `json:"title"`
+	Content    *string          `json:"content"`
+	Status     *cardStatus      `json:"status" binding:"omitempty,oneof=undone doing done"`
+	ColumnID   *uuid.UUID       `json:"column_id"`
+	Tags       *[]string        `json:"tags"`
+	DueAt      *time.Time       `json:"due_at"`
+	ClearDueAt bool             `json:"clear_due_at"`
+	Priority   *models.Priority `json:"priority"`
+}
+
+type CardTagsDTO struct {
+	Tags []string `json:"tags" binding:"required,min=1"`
+}
+
+type CreateChecklistItemDTO struct {
+	Text     string `json:"text" binding:"required"`
+	Position *int   `json:"position" binding:"omitempty,min=0"`
+}
+
+type UpdateChecklistItemDTO struct {
+	Text     *string `json:"text" binding:"omitempty,min=1"`
+	Done     *bool   `json:"done"`
+	Position *int    `json:"position" binding:"omitempty,min=0"`
+}
+
+type tagMatch string
+
+const (
+	TagMatchAny tagMatch = "any"
+	TagMatchAll tagMatch = "all"
+)
+
+type archivedFilter string
+
+const (
+	ArchivedExclude archivedFilter = "exclude"
+	ArchivedInclude archivedFilter = "include"
+	ArchivedOnly    archivedFilter = "only"
+)
+
+type cardSort string
+
+const (
+	CardSortRank      cardSort = "rank"
+	CardSortPriority  cardSort = "priority"
+	CardSortDueDate   cardSort = "due_date"
+	CardSortCreatedAt cardSort = "created_at"
+	CardSortUpdatedAt cardSort = "updated_at"
+)
+
+const (
+	defaultCardsPageSize = 50
+	maxCardsPageSize     = 200
+)
+
+type sortOrder string
+
+const (
+	SortOrderAsc  sortOrder = "asc"
+	SortOrderDesc sortOrder = "desc"
+)
+
+type ListCardsQuery struct {
+	Status        string         `form:"status"`
+	Q             string         `form:"q"`
+	Tags          string         `form:"tags"`
+	TagMatch      tagMatch       `form:"tag_match" binding:"omitempty,oneof=any all"`
+	Overdue       bool           `form:"overdue"`
+	DueBefore     *time.Time     `form:"due_before"`
+	DueAfter      *time.Time     `form:"due_after"`
+	CreatedBefore *time.Time     `form:"created_before"`
+	CreatedAfter  *time.Time     `form:"created_after"`
+	UpdatedBefore *time.Time     `form:"updated_before"`
+	UpdatedAfter  *time.Time     `form:"updated_after"`
+	WorkspaceID   string         `form:"workspace_id" binding:"omitempty,uuid"`
+	Archived      archivedFilter `form:"archived" binding:"omitempty,oneof=exclude include only"`
+	Sort          cardSort       `form:"sort" binding:"omitempty,oneof=rank priority due_date created_at updated_at"`
+	Order         sortOrder      `form:"order" binding:"omitempty,oneof=asc desc"`
+	Cursor        string         `form:"cursor"`
+	Limit         int            `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+type SearchCardsQuery struct {
+	Q           string `form:"q" binding:"required"`
+	WorkspaceID string `form:"workspace_id" binding:"omitempty,uuid"`
+	Cursor      string `form:"cursor"`
+	Limit       int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+type ListTrashQuery struct {
+	WorkspaceID string `form:"workspace_id" binding:"omitempty,uuid"`
+}
+
+// CardSearchResultDTO pairs a matched card with highlighted fragments of its
+// title and content. The highlights are HTML: all card text is escaped and the
+// only markup is <mark>...</mark> around matched terms, so clients may render
+// them as HTML directly.
+type CardSearchResultDTO struct {
+	Card             CardResponseDTO `json:"card"`
+	Rank             float64         `json:"rank"`
+	TitleHighlight   string          `json:"title_highlight"`
+	ContentHighlight string          `json:"content_highlight"`
+}
+
+type CardsSearchPage struct {
+	Results    []CardSearchResultDTO
+	NextCursor *string
+	Total      int64
+}
+
+type CardsPage struct {
+	Cards      []models.Card
+	NextCursor *string
+	Total      int64
+}
+
+type MoveCardDTO struct {
+	Status   *cardStatus `json:"status" binding:"omitempty,oneof=undone doing done"`
+	ColumnID *uuid.UUID  `json:"column_id"`
+	BeforeID *uuid.UUID  `json:"before_id"`
+	AfterID  *uuid.UUID  `json:"after_id"`
+}
+
+type ArchiveDoneCardsDTO struct {
+	OlderThanDays int    `json:"older_than_days" binding:"required,min=1"`
+	WorkspaceID   string `json:"workspace_id" binding:"omitempty,uuid"`
+}
+
+type ArchiveDoneCardsResultDTO struct {
+	Archived int64 `json:"archived"`
 }
 
 type GenerateMultipleCardsDTO struct {
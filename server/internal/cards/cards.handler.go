@@ -1,6 +1,9 @@
 package cards
 
 import (
+	"cards/internal/apperrors"
+	"cards/internal/models"
+	"cards/internal/params"
 	"cards/internal/types"
 	"net/http"
 
@@ -10,12 +13,29 @@ import (
 
 type CardsHandler interface {
 	List(c *gin.Context)
+	ListByBoard(c *gin.Context)
+	Search(c *gin.Context)
 	GetByID(c *gin.Context)
 	Create(c *gin.Context)
 	CreateMultiple(c *gin.Context)
 	GenerateMultipleCards(c *gin.Context)
 	Update(c *gin.Context)
+	Move(c *gin.Context)
+	ListRevisions(c *gin.Context)
+	RestoreRevision(c *gin.Context)
+	AttachTags(c *gin.Context)
+	DetachTags(c *gin.Context)
+	AddChecklistItem(c *gin.Context)
+	UpdateChecklistItem(c *gin.Context)
+	ToggleChecklistItem(c *gin.Context)
+	DeleteChecklistItem(c *gin.Context)
 	Delete(c *gin.Context)
+	Trash(c *gin.Context)
+	Restore(c *gin.Context)
+	Purge(c *gin.Context)
+	Archive(c *gin.Context)
+	Unarchive(c *gin.Context)
+	ArchiveDone(c *gin.Context)
 }
 
 type cardsHandler struct {
@@ -27,120 +47,166 @@ func NewCardsHandler(service CardsService) CardsHandler {
 }
 
 func (h *cardsHandler) List(c *gin.Context) {
-	userID := c.GetString("userID")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(http.StatusBadRequest, "User ID is required", nil, "User ID is empty"))
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var query ListCardsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apperrors.Respond(c, "Invalid query parameters", apperrors.Binding(err))
+		return
+	}
+
+	page, err := h.Service.List(userID, query)
+	if err != nil {
+		apperrors.Respond(c, "Failed to list cards", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewPaginatedApiResponse(
+		http.StatusOK,
+		"Cards listed successfully",
+		newCardResponses(page.Cards),
+		types.PageMeta{NextCursor: page.NextCursor, Total: page.Total},
+	))
+}
+
+func (h *cardsHandler) Search(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var query SearchCardsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apperrors.Respond(c, "Invalid query parameters", apperrors.Binding(err))
+		return
+	}
+
+	page, err := h.Service.Search(userID, query)
+	if err != nil {
+		apperrors.Respond(c, "Failed to search cards", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewPaginatedApiResponse(
+		http.StatusOK,
+		"Cards found successfully",
+		page.Results,
+		types.PageMeta{NextCursor: page.NextCursor, Total: page.Total},
+	))
+}
+
+func (h *cardsHandler) ListByBoard(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	boardID, err := params.UUID(c, "boardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid board ID", err)
 		return
 	}
 
-	cards, err := h.Service.List(uuid.MustParse(userID))
+	cards, err := h.Service.ListByBoard(userID, boardID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, types.NewApiResponse(http.StatusInternalServerError, "Failed to list cards", nil, err.Error()))
+		apperrors.Respond(c, "Failed to list cards", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Cards listed successfully", cards, nil))
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Cards listed successfully", newCardResponses(cards), nil))
 }
 
 func (h *cardsHandler) GetByID(c *gin.Context) {
-	cardID := c.Param("cardID")
-	if cardID == "" {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(http.StatusBadRequest, "Card ID is required", nil, "Card ID is empty"))
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
 		return
 	}
 
-	card, err := h.Service.GetByID(uuid.MustParse(cardID))
+	cardID, err := params.UUID(c, "cardID")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, types.NewApiResponse(http.StatusInternalServerError, "Failed to get card", nil, err.Error()))
+		apperrors.Respond(c, "Invalid card ID", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Card retrieved successfully", card, nil))
+	card, err := h.Service.GetByID(userID, cardID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to get card", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Card retrieved successfully", newCardResponse(*card), nil))
 }
 
 func (h *cardsHandler) Create(c *gin.Context) {
-	userID := c.GetString("userID")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(http.StatusBadRequest, "User ID is required", nil, "User ID is empty"))
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
 		return
 	}
 
 	var dto CreateCardDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(http.StatusBadRequest, "Invalid request payload", nil, err.Error()))
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
 		return
 	}
 
-	card, err := h.Service.Create(uuid.MustParse(userID), dto)
+	card, err := h.Service.Create(userID, dto)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, types.NewApiResponse(
-			http.StatusInternalServerError,
-			"Failed to create card",
-			nil,
-			err.Error(),
-		))
+		apperrors.Respond(c, "Failed to create card", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusCreated, "Card created successfully", card, nil))
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusCreated, "Card created successfully", newCardResponse(*card), nil))
 }
 
 func (h *cardsHandler) CreateMultiple(c *gin.Context) {
-	userID := c.GetString("userID")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(http.StatusBadRequest, "User ID is required", nil, "User ID is empty"))
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
 		return
 	}
 
 	var dto []CreateCardDTO
-	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(http.StatusBadRequest, "Invalid request payload", nil, err.Error()))
+	if err := apperrors.BindJSONList(c, &dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", err)
 		return
 	}
 
-	cards, err := h.Service.CreateMultiple(uuid.MustParse(userID), dto)
+	cards, err := h.Service.CreateMultiple(userID, dto)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, types.NewApiResponse(http.StatusInternalServerError, "Failed to create cards", nil, err.Error()))
+		apperrors.Respond(c, "Failed to create cards", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusCreated, "Cards created successfully", cards, nil))
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusCreated, "Cards created successfully", newCardResponses(cards), nil))
 }
 
 func (h *cardsHandler) GenerateMultipleCards(c *gin.Context) {
-	userID := c.GetString("userID")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(
-			http.StatusBadRequest,
-			"User ID is required",
-			nil,
-			"User ID is empty",
-		))
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
 		return
 	}
 
 	var dto GenerateMultipleCardsDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(
-			http.StatusBadRequest,
-			"Invalid request payload",
-			nil,
-			err.Error(),
-		))
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
 		return
 	}
 
 	cards, err := h.Service.GenerateMultipleCards(
-		uuid.MustParse(userID),
+		userID,
 		dto.UserPrompt,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, types.NewApiResponse(
-			http.StatusInternalServerError,
-			"Failed to generate cards",
-			nil,
-			err.Error(),
-		))
+		apperrors.Respond(c, "Failed to generate cards", err)
 		return
 	}
 
@@ -153,51 +219,31 @@ func (h *cardsHandler) GenerateMultipleCards(c *gin.Context) {
 }
 
 func (h *cardsHandler) Update(c *gin.Context) {
-	userID := c.GetString("userID")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(
-			http.StatusBadRequest,
-			"User ID is required",
-			nil,
-			"User ID is empty",
-		))
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
 		return
 	}
 
 	var dto UpdateCardDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(
-			http.StatusBadRequest,
-			"Invalid request payload",
-			nil,
-			err.Error(),
-		))
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
 		return
 	}
 
-	cardID := c.Param("cardID")
-	if cardID == "" {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(
-			http.StatusBadRequest,
-			"Card ID is required",
-			nil,
-			"Card ID is empty",
-		))
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
 		return
 	}
 
 	card, err := h.Service.Update(
-		uuid.MustParse(userID),
-		uuid.MustParse(cardID),
+		userID,
+		cardID,
 		dto,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, types.NewApiResponse(
-			http.StatusInternalServerError,
-			"Failed to update card",
-			nil,
-			err.Error(),
-		))
+		apperrors.Respond(c, "Failed to update card", err)
 		return
 	}
 
@@ -209,47 +255,341 @@ func (h *cardsHandler) Update(c *gin.Context) {
 	))
 }
 
+func (h *cardsHandler) Move(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto MoveCardDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	card, err := h.Service.Move(userID, cardID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to move card", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Card moved successfully", newCardResponse(*card), nil))
+}
+
+func (h *cardsHandler) AttachTags(c *gin.Context) {
+	h.changeTags(c, h.Service.AttachTags, "Failed to attach tags", "Tags attached successfully")
+}
+
+func (h *cardsHandler) DetachTags(c *gin.Context) {
+	h.changeTags(c, h.Service.DetachTags, "Failed to detach tags", "Tags detached successfully")
+}
+
+func (h *cardsHandler) changeTags(
+	c *gin.Context,
+	change func(userID uuid.UUID, cardID uuid.UUID, dto CardTagsDTO) (*models.Card, error),
+	failureMessage string,
+	successMessage string,
+) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto CardTagsDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	card, err := change(userID, cardID, dto)
+	if err != nil {
+		apperrors.Respond(c, failureMessage, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, successMessage, newCardResponse(*card), nil))
+}
+
 func (h *cardsHandler) Delete(c *gin.Context) {
-	userID := c.GetString("userID")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(
-			http.StatusBadRequest,
-			"User ID is required",
-			nil,
-			"User ID is empty",
-		))
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
 		return
 	}
 
-	cardID := c.Param("cardID")
-	if cardID == "" {
-		c.JSON(http.StatusBadRequest, types.NewApiResponse(
-			http.StatusBadRequest,
-			"Card ID is required",
-			nil,
-			"Card ID is empty",
-		))
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
 		return
 	}
 
 	card, err := h.Service.Delete(
-		uuid.MustParse(userID),
-		uuid.MustParse(cardID),
+		userID,
+		cardID,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, types.NewApiResponse(
-			http.StatusInternalServerError,
-			"Failed to update card",
-			nil,
-			err.Error(),
-		))
+		apperrors.Respond(c, "Failed to delete card", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, types.NewApiResponse(
 		http.StatusOK,
-		"Card updated successfully",
+		"Card moved to trash",
 		card,
 		nil,
 	))
 }
+
+func (h *cardsHandler) AddChecklistItem(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto CreateChecklistItemDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	card, err := h.Service.AddChecklistItem(userID, cardID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to create checklist item", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewApiResponse(http.StatusCreated, "Checklist item created successfully", newCardResponse(*card), nil))
+}
+
+func (h *cardsHandler) UpdateChecklistItem(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto UpdateChecklistItemDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	itemID, err := params.UUID(c, "itemID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid item ID", err)
+		return
+	}
+
+	card, err := h.Service.UpdateChecklistItem(userID, cardID, itemID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to update checklist item", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Checklist item updated successfully", newCardResponse(*card), nil))
+}
+
+func (h *cardsHandler) ToggleChecklistItem(c *gin.Context) {
+	h.changeChecklistItem(c, h.Service.ToggleChecklistItem, "Failed to toggle checklist item", "Checklist item toggled successfully")
+}
+
+func (h *cardsHandler) DeleteChecklistItem(c *gin.Context) {
+	h.changeChecklistItem(c, h.Service.DeleteChecklistItem, "Failed to delete checklist item", "Checklist item deleted successfully")
+}
+
+func (h *cardsHandler) changeChecklistItem(
+	c *gin.Context,
+	change func(userID uuid.UUID, cardID uuid.UUID, itemID uuid.UUID) (*models.Card, error),
+	failureMessage string,
+	successMessage string,
+) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	itemID, err := params.UUID(c, "itemID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid item ID", err)
+		return
+	}
+
+	card, err := change(userID, cardID, itemID)
+	if err != nil {
+		apperrors.Respond(c, failureMessage, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, successMessage, newCardResponse(*card), nil))
+}
+
+func (h *cardsHandler) ListRevisions(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	revisions, err := h.Service.ListRevisions(userID, cardID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to list revisions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Revisions listed successfully", revisions, nil))
+}
+
+func (h *cardsHandler) RestoreRevision(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	revisionID, err := params.UUID(c, "revisionID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid revision ID", err)
+		return
+	}
+
+	card, err := h.Service.RestoreRevision(userID, cardID, revisionID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to restore revision", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Revision restored successfully", newCardResponse(*card), nil))
+}
+
+func (h *cardsHandler) Trash(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var query ListTrashQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apperrors.Respond(c, "Invalid query parameters", apperrors.Binding(err))
+		return
+	}
+
+	cards, err := h.Service.ListTrash(userID, query)
+	if err != nil {
+		apperrors.Respond(c, "Failed to list trashed cards", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Trashed cards listed successfully", newCardResponses(cards), nil))
+}
+
+func (h *cardsHandler) Restore(c *gin.Context) {
+	h.changeCard(c, h.Service.Restore, "Failed to restore card", "Card restored successfully")
+}
+
+func (h *cardsHandler) Purge(c *gin.Context) {
+	h.changeCard(c, h.Service.Purge, "Failed to purge card", "Card purged successfully")
+}
+
+func (h *cardsHandler) changeCard(
+	c *gin.Context,
+	change func(userID uuid.UUID, cardID uuid.UUID) (*models.Card, error),
+	failureMessage string,
+	successMessage string,
+) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	card, err := change(userID, cardID)
+	if err != nil {
+		apperrors.Respond(c, failureMessage, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, successMessage, newCardResponse(*card), nil))
+}
+
+func (h *cardsHandler) Archive(c *gin.Context) {
+	h.changeCard(c, h.Service.Archive, "Failed to archive card", "Card archived successfully")
+}
+
+func (h *cardsHandler) Unarchive(c *gin.Context) {
+	h.changeCard(c, h.Service.Unarchive, "Failed to unarchive card", "Card unarchived successfully")
+}
+
+func (h *cardsHandler) ArchiveDone(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto ArchiveDoneCardsDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	result, err := h.Service.ArchiveDone(userID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to archive done cards", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Done cards archived successfully", result, nil))
+}
@@ -0,0 +1,102 @@
+package cards
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultTrashRetentionDays   = 30
+	defaultArchiveDoneAfterDays = 30
+	jobInterval                 = time.Hour
+	purgeBatchSize              = 100
+)
+
+// TrashRetention reads TRASH_RETENTION_DAYS, falling back to 30 days.
+func TrashRetention() time.Duration {
+	return envDays("TRASH_RETENTION_DAYS", defaultTrashRetentionDays)
+}
+
+// ArchiveDoneAfter reads ARCHIVE_DONE_AFTER_DAYS, falling back to 30 days.
+func ArchiveDoneAfter() time.Duration {
+	return envDays("ARCHIVE_DONE_AFTER_DAYS", defaultArchiveDoneAfterDays)
+}
+
+func envDays(name string, fallback int) time.Duration {
+	days, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || days <= 0 {
+		days = fallback
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// runEvery calls run immediately and then on every tick until ctx ends.
+func runEvery(ctx context.Context, interval time.Duration, run func(now time.Time)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		run(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// TrashPurger permanently removes cards that have been in the trash for
+// longer than Retention, checking every Interval until its context ends.
+type TrashPurger struct {
+	Service   CardsService
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+func NewTrashPurger(service CardsService, retention time.Duration) *TrashPurger {
+	return &TrashPurger{Service: service, Retention: retention, Interval: jobInterval}
+}
+
+func (p *TrashPurger) Run(ctx context.Context) {
+	runEvery(ctx, p.Interval, func(now time.Time) { p.PurgeOnce(now) })
+}
+
+func (p *TrashPurger) PurgeOnce(now time.Time) int {
+	purged, err := p.Service.PurgeTrashedBefore(now.Add(-p.Retention))
+	if err != nil {
+		log.Printf("trash purge failed after %d cards: %v", purged, err)
+	} else if purged > 0 {
+		log.Printf("purged %d cards from the trash", purged)
+	}
+	return purged
+}
+
+// DoneArchiver archives done cards that have not been updated for longer
+// than After, checking every Interval until its context ends.
+type DoneArchiver struct {
+	Service  CardsService
+	After    time.Duration
+	Interval time.Duration
+}
+
+func NewDoneArchiver(service CardsService, after time.Duration) *DoneArchiver {
+	return &DoneArchiver{Service: service, After: after, Interval: jobInterval}
+}
+
+func (a *DoneArchiver) Run(ctx context.Context) {
+	runEvery(ctx, a.Interval, func(now time.Time) { a.ArchiveOnce(now) })
+}
+
+func (a *DoneArchiver) ArchiveOnce(now time.Time) int64 {
+	archived, err := a.Service.ArchiveDoneBefore(now.Add(-a.After))
+	if err != nil {
+		log.Printf("archiving done cards failed: %v", err)
+	} else if archived > 0 {
+		log.Printf("archived %d done cards", archived)
+	}
+	return archived
+}
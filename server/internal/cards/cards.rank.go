@@ -0,0 +1,108 @@
+package cards
+
+import "strings"
+
+// Cards are ordered inside a column by a base-36 string rank compared
+// byte-wise. New ranks are generated between two neighbours without touching
+// any other card; once ranks grow past maxRankLength the whole column is
+// rebalanced.
+const (
+	rankDigits    = "0123456789abcdefghijklmnopqrstuvwxyz"
+	rankBase      = len(rankDigits)
+	maxRankLength = 10
+)
+
+func rankDigit(rank string, i int) int {
+	if i >= len(rank) {
+		return 0
+	}
+	return strings.IndexByte(rankDigits, rank[i])
+}
+
+// rankBetween returns a rank strictly between prev and next, where an empty
+// prev means "first" and an empty next means "last". The second result is
+// false when no usable rank exists and the column must be rebalanced.
+func rankBetween(prev, next string) (string, bool) {
+	if next == "" {
+		return rankAfter(prev)
+	}
+	if prev >= next {
+		return "", false
+	}
+
+	rank := midpoint(prev, next)
+	return rank, len(rank) <= maxRankLength
+}
+
+// rankAfter bumps the first digit that still has room so that appending to
+// the end of a column only grows ranks by one digit every rankBase-1 cards.
+func rankAfter(prev string) (string, bool) {
+	if prev == "" {
+		return string(rankDigits[rankBase/2]), true
+	}
+
+	for i := 0; i < len(prev); i++ {
+		if d := rankDigit(prev, i); d < rankBase-1 {
+			return prev[:i] + string(rankDigits[d+1]), true
+		}
+	}
+
+	rank := prev + string(rankDigits[1])
+	return rank, len(rank) <= maxRankLength
+}
+
+// midpoint assumes prev < next (next == "" meaning +infinity) and never
+// returns a rank ending in the zero digit, so there is always room before it.
+func midpoint(prev, next string) string {
+	n := 0
+	for n < len(next) && rankDigit(prev, n) == rankDigit(next, n) {
+		n++
+	}
+	if n > 0 {
+		rest := ""
+		if n < len(prev) {
+			rest = prev[n:]
+		}
+		return next[:n] + midpoint(rest, next[n:])
+	}
+
+	lo := rankDigit(prev, 0)
+	hi := rankBase
+	if next != "" {
+		hi = rankDigit(next, 0)
+	}
+	if hi-lo > 1 {
+		return string(rankDigits[(lo+hi)/2])
+	}
+	if len(next) > 1 {
+		return next[:1]
+	}
+
+	rest := ""
+	if len(prev) > 1 {
+		rest = prev[1:]
+	}
+	return string(rankDigits[lo]) + midpoint(rest, "")
+}
+
+// spreadRanks returns count evenly spaced ranks in ascending order.
+func spreadRanks(count int) []string {
+	width := 1
+	slots := rankBase
+	for slots < 4*(count+1) {
+		width++
+		slots *= rankBase
+	}
+
+	ranks := make([]string, count)
+	for i := range ranks {
+		value := (i + 1) * slots / (count + 1)
+		digits := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			digits[j] = rankDigits[value%rankBase]
+			value /= rankBase
+		}
+		ranks[i] = strings.TrimRight(string(digits), rankDigits[:1])
+	}
+	return ranks
+}
@@ -0,0 +1,94 @@
+package cards
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	cases := []struct {
+		prev, next string
+	}{
+		{"", ""},
+		{"", "i"},
+		{"i", ""},
+		{"a", "b"},
+		{"a", "a1"},
+		{"az", "b"},
+		{"z", ""},
+		{"zz", ""},
+		{"", "01"},
+		{"i", "i01"},
+	}
+
+	for _, tc := range cases {
+		rank, ok := rankBetween(tc.prev, tc.next)
+		if !ok {
+			t.Fatalf("rankBetween(%q, %q): expected a rank", tc.prev, tc.next)
+		}
+		if rank <= tc.prev || (tc.next != "" && rank >= tc.next) {
+			t.Fatalf("rankBetween(%q, %q) = %q is out of order", tc.prev, tc.next, rank)
+		}
+		if rank[len(rank)-1] == '0' {
+			t.Fatalf("rankBetween(%q, %q) = %q ends in zero digit", tc.prev, tc.next, rank)
+		}
+	}
+}
+
+func TestRankBetween_RequiresRebalance(t *testing.T) {
+	if _, ok := rankBetween("a", "a"); ok {
+		t.Fatalf("expected equal neighbours to require rebalance")
+	}
+	if _, ok := rankBetween("", ""); !ok {
+		t.Fatalf("expected empty column to accept a rank")
+	}
+
+	prev, next := "a", "b"
+	for i := 0; ; i++ {
+		rank, ok := rankBetween(prev, next)
+		if !ok {
+			break
+		}
+		if i > 100 {
+			t.Fatalf("expected ranks to become too dense")
+		}
+		next = rank
+	}
+}
+
+func TestRankAfter_StaysShortWhenAppending(t *testing.T) {
+	rank := ""
+	for i := 0; i < 200; i++ {
+		next, ok := rankBetween(rank, "")
+		if !ok {
+			t.Fatalf("append %d required rebalance", i)
+		}
+		if next <= rank {
+			t.Fatalf("append %d: %q is not after %q", i, next, rank)
+		}
+		rank = next
+	}
+	if len(rank) > 8 {
+		t.Fatalf("expected appended ranks to stay short, got %q", rank)
+	}
+}
+
+func TestSpreadRanks(t *testing.T) {
+	for _, count := range []int{1, 5, 40, 1000} {
+		ranks := spreadRanks(count)
+		if len(ranks) != count {
+			t.Fatalf("expected %d ranks, got %d", count, len(ranks))
+		}
+		if !sort.StringsAreSorted(ranks) {
+			t.Fatalf("expected sorted ranks for %d cards", count)
+		}
+		for i := 1; i < len(ranks); i++ {
+			if ranks[i] == ranks[i-1] {
+				t.Fatalf("duplicate rank %q", ranks[i])
+			}
+			if _, ok := rankBetween(ranks[i-1], ranks[i]); !ok {
+				t.Fatalf("no room between %q and %q", ranks[i-1], ranks[i])
+			}
+		}
+	}
+}
@@ -1,45 +1,343 @@
 package cards
 
 import (
+	"html"
+	"strings"
+	"time"
+
+	"cards/internal/database"
 	"cards/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CardsRepository interface {
 	FindByID(uuid.UUID) (models.Card, error)
-	ListByUserID(uuid.UUID) ([]models.Card, error)
+	List(CardsQuery) ([]models.Card, error)
+	Count(CardsQuery) (int64, error)
+	Search(CardsSearchQuery) ([]CardSearchResultDTO, int64, error)
+	ListByBoardID(uuid.UUID) ([]models.Card, error)
+	ListByColumnID(uuid.UUID) ([]models.Card, error)
+	LastRankInColumn(uuid.UUID) (string, error)
 	Create(*models.Card) error
 	CreateMultiple([]models.Card) error
-	Update(*models.Card) error
+	Update(card *models.Card, edit cardEdit) error
+	UpdateRanks([]models.Card) error
+	Move(card *models.Card, edit cardEdit, rebalanced []models.Card) error
+	ListRevisions(cardID uuid.UUID) ([]models.CardRevision, error)
+	CreateChecklistItem(*models.ChecklistItem) error
+	SaveChecklist([]models.ChecklistItem) error
+	DeleteChecklistItem(uuid.UUID) error
 	Delete(uuid.UUID) error
+	FindTrashedByID(uuid.UUID) (models.Card, error)
+	ListTrash(workspaceID uuid.UUID) ([]models.Card, error)
+	ListTrashedBefore(before time.Time, limit int) ([]models.Card, error)
+	Restore(*models.Card) error
+	Purge(uuid.UUID) ([]string, error)
+	SetArchivedAt(id uuid.UUID, archivedAt *time.Time) error
+	ArchiveDone(workspaceID *uuid.UUID, doneBefore time.Time, archivedAt time.Time) (int64, error)
+	RecordLLMUsage(*models.LLMUsage) error
+}
+
+const rankOrder = `rank COLLATE "C", created_at, id`
+
+type CardsQuery struct {
+	WorkspaceID   uuid.UUID
+	Statuses      []cardStatus
+	Text          string
+	TagIDs        []uuid.UUID
+	MatchAllTags  bool
+	DueBefore     *time.Time
+	DueAfter      *time.Time
+	OverdueAt     *time.Time
+	CreatedBefore *time.Time
+	CreatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	Archived      archivedFilter
+	Sort          cardSort
+	Descending    bool
+	After         *cardsCursor
+	Limit         int
+}
+
+type CardsSearchQuery struct {
+	WorkspaceID uuid.UUID
+	Text        string
+	Offset      int
+	Limit       int
 }
 
 type cardsRepository struct {
-	db *gorm.DB
+	db             *gorm.DB
+	searchLanguage string
 }
 
 func NewCardsRepository(db *gorm.DB) CardsRepository {
-	return &cardsRepository{db: db}
+	return &cardsRepository{db: db, searchLanguage: database.SearchLanguage()}
+}
+
+func orderedChecklist(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+func preloadCardAssociations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Tags").Preload("Checklist", orderedChecklist)
 }
 
 func (r *cardsRepository) FindByID(id uuid.UUID) (models.Card, error) {
 	var card models.Card
-	if err := r.db.Where("id = ?", id).First(&card).Error; err != nil {
+	if err := preloadCardAssociations(r.db).Where("id = ?", id).First(&card).Error; err != nil {
 		return models.Card{}, err
 	}
 	return card, nil
 }
 
-func (r *cardsRepository) ListByUserID(userID uuid.UUID) ([]models.Card, error) {
+func (r *cardsRepository) List(query CardsQuery) ([]models.Card, error) {
+	db := r.filter(preloadCardAssociations(r.db), query)
+
+	columns := cardsSortColumns(query.Sort, query.Descending)
+	if query.After != nil {
+		comparison := ">"
+		if query.Descending {
+			comparison = "<"
+		}
+		values := cursorValues(*query.After)
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		db = db.Where("("+strings.Join(columns, ", ")+") "+comparison+" ("+placeholders+")", values...)
+	}
+
+	direction := " ASC"
+	if query.Descending {
+		direction = " DESC"
+	}
+	for _, column := range columns {
+		db = db.Order(column + direction)
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	var cards []models.Card
+	if err := db.Find(&cards).Error; err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+func (r *cardsRepository) Count(query CardsQuery) (int64, error) {
+	var count int64
+	if err := r.filter(r.db.Model(&models.Card{}), query).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ts_headline copies card text verbatim, so matches are delimited with
+// private-use sentinels rather than markup. renderHighlight escapes the
+// fragment and only then turns the sentinels into <mark> tags.
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+
+	searchHeadlineOptions = "StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", MaxFragments=2, MaxWords=30, MinWords=10"
+)
+
+var highlightMarkup = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// renderHighlight returns a ts_headline fragment as HTML-escaped text in which
+// only the <mark> tags around matched terms are markup.
+func renderHighlight(headline string) string {
+	return highlightMarkup.Replace(html.EscapeString(headline))
+}
+
+type cardSearchRow struct {
+	ID               uuid.UUID
+	SearchRank       float64
+	TitleHighlight   string
+	ContentHighlight string
+}
+
+// Search matches the generated search_vector column against a web-search style
+// query and returns the page ordered by relevance, along with the total number
+// of matches.
+func (r *cardsRepository) Search(query CardsSearchQuery) ([]CardSearchResultDTO, int64, error) {
+	matches := func() *gorm.DB {
+		return r.db.
+			Table("cards, websearch_to_tsquery(?::regconfig, ?) AS query", r.searchLanguage, query.Text).
+			Where("cards.workspace_id = ? AND cards.deleted_at IS NULL AND cards.search_vector @@ query", query.WorkspaceID)
+	}
+
+	var total int64
+	if err := matches().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []cardSearchRow
+	err := matches().
+		Select(
+			`cards.id,
+			ts_rank(cards.search_vector, query) AS search_rank,
+			ts_headline(?::regconfig, cards.title, query, ?) AS title_highlight,
+			ts_headline(?::regconfig, cards.content, query, ?) AS content_highlight`,
+			r.searchLanguage, searchHeadlineOptions,
+			r.searchLanguage, searchHeadlineOptions,
+		).
+		Order("search_rank DESC, cards.id").
+		Offset(query.Offset).
+		Limit(query.Limit).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return []CardSearchResultDTO{}, total, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+
+	var cards []models.Card
+	if err := preloadCardAssociations(r.db).Where("id IN ?", ids).Find(&cards).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uuid.UUID]models.Card, len(cards))
+	for _, card := range cards {
+		byID[card.ID] = card
+	}
+
+	results := make([]CardSearchResultDTO, 0, len(rows))
+	for _, row := range rows {
+		card, ok := byID[row.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, CardSearchResultDTO{
+			Card:             newCardResponse(card),
+			Rank:             row.SearchRank,
+			TitleHighlight:   renderHighlight(row.TitleHighlight),
+			ContentHighlight: renderHighlight(row.ContentHighlight),
+		})
+	}
+	return results, total, nil
+}
+
+func (r *cardsRepository) filter(db *gorm.DB, query CardsQuery) *gorm.DB {
+	db = db.Where("workspace_id = ?", query.WorkspaceID)
+
+	if len(query.Statuses) > 0 {
+		db = db.Where("status IN ?", query.Statuses)
+	}
+	if query.Text != "" {
+		pattern := "%" + likeEscaper.Replace(query.Text) + "%"
+		db = db.Where("(title ILIKE ? OR content ILIKE ?)", pattern, pattern)
+	}
+	if len(query.TagIDs) > 0 {
+		tagged := r.db.Table("card_tags").Select("card_id").Where("tag_id IN ?", query.TagIDs)
+		if query.MatchAllTags {
+			tagged = tagged.Group("card_id").Having("COUNT(DISTINCT tag_id) = ?", len(query.TagIDs))
+		}
+		db = db.Where("id IN (?)", tagged)
+	}
+	if query.DueBefore != nil {
+		db = db.Where("due_at < ?", *query.DueBefore)
+	}
+	if query.DueAfter != nil {
+		db = db.Where("due_at > ?", *query.DueAfter)
+	}
+	if query.OverdueAt != nil {
+		db = db.Where("due_at < ? AND status <> ?", *query.OverdueAt, CardStatusDone)
+	}
+	if query.CreatedBefore != nil {
+		db = db.Where("created_at < ?", *query.CreatedBefore)
+	}
+	if query.CreatedAfter != nil {
+		db = db.Where("created_at > ?", *query.CreatedAfter)
+	}
+	if query.UpdatedBefore != nil {
+		db = db.Where("updated_at < ?", *query.UpdatedBefore)
+	}
+	if query.UpdatedAfter != nil {
+		db = db.Where("updated_at > ?", *query.UpdatedAfter)
+	}
+	switch query.Archived {
+	case ArchivedInclude:
+	case ArchivedOnly:
+		db = db.Where("archived_at IS NOT NULL")
+	default:
+		db = db.Where("archived_at IS NULL")
+	}
+
+	return db
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// cardsSortColumns lists the sort key for each sort option. Every key ends in
+// created_at, id so that it is unique and can be compared as a row against a
+// cursor; all columns share one direction for the same reason.
+func cardsSortColumns(sort cardSort, descending bool) []string {
+	switch sort {
+	case CardSortPriority:
+		return []string{"priority", "created_at", "id"}
+	case CardSortDueDate:
+		sentinel := dueDateLast
+		if descending {
+			sentinel = dueDateFirst
+		}
+		return []string{"COALESCE(due_at, '" + sentinel.Format(time.RFC3339) + "'::timestamptz)", "created_at", "id"}
+	case CardSortCreatedAt:
+		return []string{"created_at", "id"}
+	case CardSortUpdatedAt:
+		return []string{"updated_at", "id"}
+	}
+	return []string{`rank COLLATE "C"`, "created_at", "id"}
+}
+
+func cursorValues(cursor cardsCursor) []any {
+	switch cursor.Sort {
+	case CardSortPriority:
+		return []any{cursor.Priority, cursor.CreatedAt, cursor.ID}
+	case CardSortDueDate:
+		return []any{cursor.Time, cursor.CreatedAt, cursor.ID}
+	case CardSortCreatedAt:
+		return []any{cursor.CreatedAt, cursor.ID}
+	case CardSortUpdatedAt:
+		return []any{cursor.Time, cursor.ID}
+	}
+	return []any{cursor.Rank, cursor.CreatedAt, cursor.ID}
+}
+
+func (r *cardsRepository) ListByBoardID(boardID uuid.UUID) ([]models.Card, error) {
+	var cards []models.Card
+	if err := preloadCardAssociations(r.db).Where("board_id = ? AND archived_at IS NULL", boardID).Order(rankOrder).Find(&cards).Error; err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+func (r *cardsRepository) ListByColumnID(columnID uuid.UUID) ([]models.Card, error) {
 	var cards []models.Card
-	if err := r.db.Where("user_id = ?", userID).Find(&cards).Error; err != nil {
+	if err := r.db.Where("column_id = ?", columnID).Order(rankOrder).Find(&cards).Error; err != nil {
 		return nil, err
 	}
 	return cards, nil
 }
 
+func (r *cardsRepository) LastRankInColumn(columnID uuid.UUID) (string, error) {
+	var ranks []string
+	err := r.db.Model(&models.Card{}).
+		Where("column_id = ?", columnID).
+		Order(`rank COLLATE "C" DESC`).
+		Limit(1).
+		Pluck("rank", &ranks).Error
+	if err != nil || len(ranks) == 0 {
+		return "", err
+	}
+	return ranks[0], nil
+}
+
 func (r *cardsRepository) Create(card *models.Card) error {
 	return r.db.Create(card).Error
 }
@@ -48,8 +346,82 @@ func (r *cardsRepository) CreateMultiple(cards []models.Card) error {
 	return r.db.Create(&cards).Error
 }
 
-func (r *cardsRepository) Update(card *models.Card) error {
-	return r.db.Save(card).Error
+// Update writes the columns the edit changed and, when its tags changed,
+// swaps them for card.Tags, recording the revision in the same transaction.
+func (r *cardsRepository) Update(card *models.Card, edit cardEdit) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return saveEdit(tx, card, edit)
+	})
+}
+
+// saveEdit writes only the edited columns, so a concurrent move, archive or
+// rebalance is not overwritten with the values read at the start.
+func saveEdit(tx *gorm.DB, card *models.Card, edit cardEdit) error {
+	if len(edit.Columns) > 0 {
+		if err := tx.Model(card).Select(edit.Columns).Updates(card).Error; err != nil {
+			return err
+		}
+	}
+	if edit.ReplaceTags {
+		if err := tx.Model(card).Association("Tags").Replace(card.Tags); err != nil {
+			return err
+		}
+	}
+	if edit.Revision == nil {
+		return nil
+	}
+	return tx.Create(edit.Revision).Error
+}
+
+func updateRanks(tx *gorm.DB, cards []models.Card) error {
+	for _, card := range cards {
+		if err := tx.Model(&models.Card{}).Where("id = ?", card.ID).UpdateColumn("rank", card.Rank).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *cardsRepository) UpdateRanks(cards []models.Card) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return updateRanks(tx, cards)
+	})
+}
+
+func (r *cardsRepository) Move(card *models.Card, edit cardEdit, rebalanced []models.Card) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateRanks(tx, rebalanced); err != nil {
+			return err
+		}
+		return saveEdit(tx, card, edit)
+	})
+}
+
+func (r *cardsRepository) ListRevisions(cardID uuid.UUID) ([]models.CardRevision, error) {
+	var revisions []models.CardRevision
+	if err := r.db.Where("card_id = ?", cardID).Order("created_at DESC, id DESC").Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *cardsRepository) CreateChecklistItem(item *models.ChecklistItem) error {
+	return r.db.Create(item).Error
+}
+
+func (r *cardsRepository) SaveChecklist(items []models.ChecklistItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range items {
+			if err := tx.Save(&items[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *cardsRepository) DeleteChecklistItem(id uuid.UUID) error {
+	return r.db.Delete(&models.ChecklistItem{Base: models.Base{ID: id}}).Error
 }
 
 func (r *cardsRepository) Delete(id uuid.UUID) error {
@@ -59,3 +431,90 @@ func (r *cardsRepository) Delete(id uuid.UUID) error {
 		},
 	}).Error
 }
+
+func trashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where("deleted_at IS NOT NULL")
+}
+
+func (r *cardsRepository) FindTrashedByID(id uuid.UUID) (models.Card, error) {
+	var card models.Card
+	if err := preloadCardAssociations(trashed(r.db)).Where("id = ?", id).First(&card).Error; err != nil {
+		return models.Card{}, err
+	}
+	return card, nil
+}
+
+func (r *cardsRepository) ListTrash(workspaceID uuid.UUID) ([]models.Card, error) {
+	var cards []models.Card
+	if err := preloadCardAssociations(trashed(r.db)).Where("workspace_id = ?", workspaceID).Order("deleted_at DESC, id").Find(&cards).Error; err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+func (r *cardsRepository) ListTrashedBefore(before time.Time, limit int) ([]models.Card, error) {
+	var cards []models.Card
+	if err := trashed(r.db).Where("deleted_at < ?", before).Order("deleted_at, id").Limit(limit).Find(&cards).Error; err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+func (r *cardsRepository) Restore(card *models.Card) error {
+	card.DeletedAt = gorm.DeletedAt{}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Omit(clause.Associations).Save(card).Error; err != nil {
+			return err
+		}
+		// The card may have been restored into another workspace, whose
+		// tags placeCard swapped in.
+		return tx.Model(card).Association("Tags").Replace(card.Tags)
+	})
+}
+
+// Purge permanently removes a card with everything attached to it and
+// returns the storage keys of its attachments, whose blobs the caller still
+// has to delete.
+func (r *cardsRepository) Purge(id uuid.UUID) ([]string, error) {
+	var storageKeys []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Attachment{}).Where("card_id = ?", id).Pluck("storage_key", &storageKeys).Error; err != nil {
+			return err
+		}
+		for _, child := range []any{&models.ChecklistItem{}, &models.Comment{}, &models.Attachment{}, &models.CardRevision{}} {
+			if err := tx.Where("card_id = ?", id).Delete(child).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("DELETE FROM card_tags WHERE card_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Card{Base: models.Base{ID: id}}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return storageKeys, nil
+}
+
+// SetArchivedAt archives or unarchives a card without bumping updated_at, so
+// archiving does not count as an edit.
+func (r *cardsRepository) SetArchivedAt(id uuid.UUID, archivedAt *time.Time) error {
+	return r.db.Model(&models.Card{Base: models.Base{ID: id}}).UpdateColumn("archived_at", archivedAt).Error
+}
+
+// ArchiveDone archives every done card last updated before doneBefore, in a
+// single workspace or, when workspaceID is nil, everywhere.
+func (r *cardsRepository) ArchiveDone(workspaceID *uuid.UUID, doneBefore time.Time, archivedAt time.Time) (int64, error) {
+	db := r.db.Model(&models.Card{}).Where("status = ? AND archived_at IS NULL AND updated_at < ?", CardStatusDone, doneBefore)
+	if workspaceID != nil {
+		db = db.Where("workspace_id = ?", *workspaceID)
+	}
+
+	result := db.UpdateColumn("archived_at", archivedAt)
+	return result.RowsAffected, result.Error
+}
+
+func (r *cardsRepository) RecordLLMUsage(usage *models.LLMUsage) error {
+	return r.db.Create(usage).Error
+}
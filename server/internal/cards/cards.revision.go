@@ -0,0 +1,168 @@
+package cards
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	revisionFieldTitle    = "title"
+	revisionFieldContent  = "content"
+	revisionFieldStatus   = "status"
+	revisionFieldColumnID = "column_id"
+	revisionFieldDueAt    = "due_at"
+	revisionFieldPriority = "priority"
+	revisionFieldTags     = "tags"
+)
+
+// revisionFields snapshots the user-editable fields of a card. Rank is left
+// out on purpose: it changes whenever neighbours move and means nothing on
+// its own.
+func revisionFields(card models.Card) map[string]json.RawMessage {
+	tagNames := make([]string, 0, len(card.Tags))
+	for _, tag := range card.Tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+	sort.Strings(tagNames)
+
+	fields := map[string]any{
+		revisionFieldTitle:    card.Title,
+		revisionFieldContent:  card.Content,
+		revisionFieldStatus:   card.Status,
+		revisionFieldColumnID: card.ColumnID,
+		revisionFieldDueAt:    card.DueAt,
+		revisionFieldPriority: card.Priority,
+		revisionFieldTags:     tagNames,
+	}
+
+	snapshot := make(map[string]json.RawMessage, len(fields))
+	for name, value := range fields {
+		snapshot[name], _ = json.Marshal(value)
+	}
+	return snapshot
+}
+
+// cardEdit is what saving an edited card takes: the columns to write, whether
+// the tags changed, and the revision recording the change (nil if no tracked
+// field changed).
+type cardEdit struct {
+	Columns     []string
+	ReplaceTags bool
+	Revision    *models.CardRevision
+}
+
+// newCardEdit diffs the card once for both the revision and the columns to
+// write. Revision field names double as column names; tags live in a join
+// table, and placement (rank, board, workspace) is written without being
+// recorded. A card changing workspace keeps its tag names but gets the new
+// workspace's tags, so the join table is rewritten even though the revision
+// sees no change.
+func newCardEdit(userID uuid.UUID, before models.Card, after models.Card, restoredFromID *uuid.UUID) cardEdit {
+	changes := diffCard(before, after)
+
+	var edit cardEdit
+	for name := range changes {
+		if name == revisionFieldTags {
+			edit.ReplaceTags = true
+			continue
+		}
+		edit.Columns = append(edit.Columns, name)
+	}
+	if before.Rank != after.Rank {
+		edit.Columns = append(edit.Columns, "rank")
+	}
+	if !sameID(before.BoardID, after.BoardID) {
+		edit.Columns = append(edit.Columns, "board_id")
+	}
+	if !sameID(before.WorkspaceID, after.WorkspaceID) {
+		edit.Columns = append(edit.Columns, "workspace_id")
+		edit.ReplaceTags = edit.ReplaceTags || len(after.Tags) > 0
+	}
+	if len(edit.Columns) > 0 || edit.ReplaceTags {
+		edit.Columns = append(edit.Columns, "updated_at")
+	}
+	sort.Strings(edit.Columns)
+
+	if len(changes) > 0 {
+		edit.Revision = &models.CardRevision{CardID: after.ID, UserID: userID, Changes: changes, RestoredFromID: restoredFromID}
+	}
+	return edit
+}
+
+func sameID(a *uuid.UUID, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func diffCard(before models.Card, after models.Card) models.RevisionChanges {
+	from := revisionFields(before)
+	to := revisionFields(after)
+
+	changes := models.RevisionChanges{}
+	for name := range from {
+		if !bytes.Equal(from[name], to[name]) {
+			changes[name] = models.FieldChange{From: from[name], To: to[name]}
+		}
+	}
+	return changes
+}
+
+// revertDTO builds the update that undoes revisions, given newest first: each
+// field ends up with the value it had before the oldest revision touching it.
+func revertDTO(revisions []models.CardRevision) (UpdateCardDTO, error) {
+	values := map[string]json.RawMessage{}
+	for _, revision := range revisions {
+		for name, change := range revision.Changes {
+			values[name] = change.From
+		}
+	}
+
+	var dto UpdateCardDTO
+	for name, value := range values {
+		var err error
+		switch name {
+		case revisionFieldTitle:
+			err = json.Unmarshal(value, &dto.Title)
+		case revisionFieldContent:
+			err = json.Unmarshal(value, &dto.Content)
+		case revisionFieldStatus:
+			var status cardStatus
+			if err = json.Unmarshal(value, &status); err == nil && status.valid() {
+				dto.Status = &status
+			}
+		case revisionFieldColumnID:
+			var columnID *uuid.UUID
+			if err = json.Unmarshal(value, &columnID); err == nil {
+				dto.ColumnID = columnID
+			}
+		case revisionFieldDueAt:
+			var dueAt *time.Time
+			if err = json.Unmarshal(value, &dueAt); err == nil {
+				dto.DueAt = dueAt
+				dto.ClearDueAt = dueAt == nil
+			}
+		case revisionFieldPriority:
+			var priority models.Priority
+			if err = json.Unmarshal(value, &priority); err == nil {
+				dto.Priority = &priority
+			}
+		case revisionFieldTags:
+			var tagNames []string
+			if err = json.Unmarshal(value, &tagNames); err == nil {
+				dto.Tags = &tagNames
+			}
+		}
+		if err != nil {
+			return UpdateCardDTO{}, err
+		}
+	}
+	return dto, nil
+}
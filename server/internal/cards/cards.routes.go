@@ -2,22 +2,64 @@ package cards
 
 import (
 	"cards/internal/auth"
+	"cards/internal/boards"
+	"cards/internal/storage"
+	"cards/internal/tags"
+	"cards/internal/workspaces"
+
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func RegisterCardsRoutes(appGroup *gin.RouterGroup, db *gorm.DB) {
-	repository := NewCardsRepository(db)
-	service := NewCardsService(repository)
-	handler := NewCardsHandler(service)
+// NewCardsServiceFromDB wires the cards service with its default
+// dependencies. main builds it once and shares it with the packages that
+// need card access checks.
+func NewCardsServiceFromDB(db *gorm.DB, store storage.BlobStore) CardsService {
+	workspacesService := workspaces.NewWorkspacesService(workspaces.NewWorkspacesRepository(db), nil)
+	return NewCardsService(
+		NewCardsRepository(db),
+		boards.NewBoardsService(boards.NewBoardsRepository(db), workspacesService),
+		tags.NewTagsService(tags.NewTagsRepository(db), workspacesService),
+		workspacesService,
+		store,
+	)
+}
+
+func RegisterCardsRoutes(appGroup *gin.RouterGroup, cardsService CardsService, authMiddleware auth.Middleware) {
+	registerCardsRoutes(appGroup, NewCardsHandler(cardsService), authMiddleware)
+}
 
+func registerCardsRoutes(appGroup *gin.RouterGroup, handler CardsHandler, authMiddleware auth.Middleware) {
 	cardsGroup := appGroup.Group("/cards")
-	cardsGroup.Use(auth.AuthMiddleware())
-	cardsGroup.GET("/list", handler.List)
-	cardsGroup.GET("/by_id/:cardID", handler.GetByID)
-	cardsGroup.POST("/create", handler.Create)
-	cardsGroup.POST("/generate_multiple_cards", handler.GenerateMultipleCards)
-	cardsGroup.POST("/create_multiple_cards", handler.CreateMultiple)
-	cardsGroup.PATCH("/update/:cardID", handler.Update)
-	cardsGroup.DELETE("/delete/:cardID", handler.Delete)
+
+	// Cards are the one API scripts can use, so each route names the scope a
+	// personal access token needs for it.
+	read := cardsGroup.Group("", authMiddleware.Authenticate(auth.ScopeCardsRead))
+	read.GET("/list", handler.List)
+	read.GET("/search", handler.Search)
+	read.GET("/trash", handler.Trash)
+	read.GET("/by_id/:cardID", handler.GetByID)
+	read.GET("/by_board/:boardID", handler.ListByBoard)
+	read.GET("/:cardID/revisions", handler.ListRevisions)
+
+	cardsGroup.POST("/generate_multiple_cards", authMiddleware.Authenticate(auth.ScopeLLMGenerate), authMiddleware.RequireVerifiedEmail(), handler.GenerateMultipleCards)
+
+	write := cardsGroup.Group("", authMiddleware.Authenticate(auth.ScopeCardsWrite))
+	write.POST("/archive_done", handler.ArchiveDone)
+	write.POST("/create", handler.Create)
+	write.POST("/create_multiple_cards", handler.CreateMultiple)
+	write.PATCH("/update/:cardID", handler.Update)
+	write.POST("/move/:cardID", handler.Move)
+	write.POST("/attach_tags/:cardID", handler.AttachTags)
+	write.POST("/detach_tags/:cardID", handler.DetachTags)
+	write.DELETE("/delete/:cardID", handler.Delete)
+	write.POST("/:cardID/restore", handler.Restore)
+	write.POST("/:cardID/archive", handler.Archive)
+	write.POST("/:cardID/unarchive", handler.Unarchive)
+	write.DELETE("/:cardID/purge", handler.Purge)
+	write.POST("/:cardID/revisions/:revisionID/restore", handler.RestoreRevision)
+	write.POST("/:cardID/checklist/create", handler.AddChecklistItem)
+	write.PATCH("/:cardID/checklist/update/:itemID", handler.UpdateChecklistItem)
+	write.POST("/:cardID/checklist/toggle/:itemID", handler.ToggleChecklistItem)
+	write.DELETE("/:cardID/checklist/delete/:itemID", handler.DeleteChecklistItem)
 }
@@ -0,0 +1,305 @@
+package cards
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cards/internal/apperrors"
+	"cards/internal/auth"
+	"cards/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TestCardsRoutes_Authorization calls every cards route as each workspace
+// role and as an outsider. Outsiders must not learn that a card exists, and
+// roles below the one an action needs are refused.
+func TestCardsRoutes_Authorization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "secret")
+
+	adminID, memberID, viewerID, outsiderID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	workspaceID := uuid.New()
+
+	workspacesSvc := newFakeWorkspacesService()
+	workspacesSvc.join(workspaceID, adminID, models.RoleAdmin)
+	workspacesSvc.join(workspaceID, memberID, models.RoleMember)
+	workspacesSvc.join(workspaceID, viewerID, models.RoleViewer)
+
+	boardsSvc := newFakeBoardsService(adminID)
+	boardsSvc.board.WorkspaceID = &workspaceID
+	boardsSvc.workspaces = workspacesSvc
+	column := boardsSvc.column("undone")
+
+	// The owner is loaded with the card so a leaked password hash would show.
+	const passwordHash = "$2a$10$owner.password.hash"
+	owner := &models.User{Base: models.Base{ID: adminID}, Email: "owner@example.com", Password: passwordHash}
+	card := models.Card{
+		Base:        models.Base{ID: uuid.New()},
+		User:        owner,
+		Title:       "shared",
+		Content:     "body",
+		Status:      string(CardStatusUndone),
+		UserID:      adminID,
+		WorkspaceID: &workspaceID,
+		BoardID:     &boardsSvc.board.ID,
+		ColumnID:    &column.ID,
+	}
+	item := models.ChecklistItem{Base: models.Base{ID: uuid.New()}, CardID: card.ID, Text: "item"}
+	revision := models.CardRevision{Base: models.Base{ID: uuid.New()}, CardID: card.ID, Changes: models.RevisionChanges{
+		revisionFieldTitle: {From: rawJSON("before"), To: rawJSON("shared")},
+	}}
+	trashed := card
+	trashed.ID = uuid.New()
+	trashed.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+
+	newRouter := func() *gin.Engine {
+		repo := &fakeCardsRepository{
+			findByID: func(id uuid.UUID) (models.Card, error) {
+				if id != card.ID {
+					return models.Card{}, gorm.ErrRecordNotFound
+				}
+				found := card
+				found.Checklist = []models.ChecklistItem{item}
+				return found, nil
+			},
+			list:          func(query CardsQuery) ([]models.Card, error) { return []models.Card{card}, nil },
+			search:        func(query CardsSearchQuery) ([]CardSearchResultDTO, int64, error) { return nil, 0, nil },
+			listByBoardID: func(boardID uuid.UUID) ([]models.Card, error) { return []models.Card{card}, nil },
+			revisions:     []models.CardRevision{revision},
+			trash:         map[uuid.UUID]models.Card{trashed.ID: trashed},
+		}
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService().with(workspaceID, "bug"), workspacesSvc, newFakeBlobStore())
+
+		router := gin.New()
+		router.Use(apperrors.Middleware())
+		registerCardsRoutes(router.Group("/api/v1"), NewCardsHandler(svc), newTestMiddleware())
+		return router
+	}
+
+	const (
+		ok        = http.StatusOK
+		created   = http.StatusCreated
+		invalid   = http.StatusBadRequest
+		forbidden = http.StatusForbidden
+		notFound  = http.StatusNotFound
+	)
+	ws := "workspace_id=" + workspaceID.String()
+	tests := []struct {
+		method  string
+		route   string
+		query   string
+		body    string
+		trashed bool
+
+		admin, member, viewer, outsider int
+	}{
+		{method: "GET", route: "/cards/list", query: ws, admin: ok, member: ok, viewer: ok, outsider: notFound},
+		{method: "GET", route: "/cards/search", query: "q=shared&" + ws, admin: ok, member: ok, viewer: ok, outsider: notFound},
+		{method: "GET", route: "/cards/trash", query: ws, admin: ok, member: ok, viewer: ok, outsider: notFound},
+		{method: "POST", route: "/cards/archive_done", body: `{"older_than_days":30,"workspace_id":"` + workspaceID.String() + `"}`, admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "GET", route: "/cards/by_id/:cardID", admin: ok, member: ok, viewer: ok, outsider: notFound},
+		{method: "GET", route: "/cards/by_board/:boardID", admin: ok, member: ok, viewer: ok, outsider: notFound},
+		{method: "POST", route: "/cards/create", body: `{"title":"new","content":"body","board_id":":boardID"}`, admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		// Generation only drafts cards; nothing is read or stored, so an
+		// invalid payload is rejected the same way for everyone.
+		{method: "POST", route: "/cards/generate_multiple_cards", body: `{}`, admin: invalid, member: invalid, viewer: invalid, outsider: invalid},
+		{method: "POST", route: "/cards/create_multiple_cards", body: `[{"title":"new","content":"body","board_id":":boardID"}]`, admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "PATCH", route: "/cards/update/:cardID", body: `{"title":"renamed"}`, admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "POST", route: "/cards/move/:cardID", body: `{}`, admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "POST", route: "/cards/attach_tags/:cardID", body: `{"tags":["bug"]}`, admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "POST", route: "/cards/detach_tags/:cardID", body: `{"tags":["bug"]}`, admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "DELETE", route: "/cards/delete/:cardID", admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "POST", route: "/cards/:cardID/restore", trashed: true, admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "POST", route: "/cards/:cardID/archive", admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "POST", route: "/cards/:cardID/unarchive", admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "DELETE", route: "/cards/:cardID/purge", trashed: true, admin: ok, member: forbidden, viewer: forbidden, outsider: notFound},
+		{method: "GET", route: "/cards/:cardID/revisions", admin: ok, member: ok, viewer: ok, outsider: notFound},
+		{method: "POST", route: "/cards/:cardID/revisions/:revisionID/restore", admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "POST", route: "/cards/:cardID/checklist/create", body: `{"text":"new"}`, admin: created, member: created, viewer: forbidden, outsider: notFound},
+		{method: "PATCH", route: "/cards/:cardID/checklist/update/:itemID", body: `{"text":"renamed"}`, admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "POST", route: "/cards/:cardID/checklist/toggle/:itemID", admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+		{method: "DELETE", route: "/cards/:cardID/checklist/delete/:itemID", admin: ok, member: ok, viewer: forbidden, outsider: notFound},
+	}
+
+	t.Run("covers every route", func(t *testing.T) {
+		covered := map[string]bool{}
+		for _, tt := range tests {
+			covered[tt.method+" /api/v1"+tt.route] = true
+		}
+		for _, route := range newRouter().Routes() {
+			if !covered[route.Method+" "+route.Path] {
+				t.Errorf("route %s %s has no authorization test", route.Method, route.Path)
+			}
+		}
+	})
+
+	for _, tt := range tests {
+		cardID := card.ID
+		if tt.trashed {
+			cardID = trashed.ID
+		}
+		ids := strings.NewReplacer(
+			":cardID", cardID.String(),
+			":boardID", boardsSvc.board.ID.String(),
+			":revisionID", revision.ID.String(),
+			":itemID", item.ID.String(),
+		)
+		path := "/api/v1" + ids.Replace(tt.route)
+		if tt.query != "" {
+			path += "?" + tt.query
+		}
+		body := ids.Replace(tt.body)
+
+		t.Run(tt.method+" "+tt.route, func(t *testing.T) {
+			serve := func(userID *uuid.UUID) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(tt.method, path, strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				if userID != nil {
+					req.Header.Set("Authorization", "Bearer "+testToken(t, *userID))
+				}
+				w := httptest.NewRecorder()
+				newRouter().ServeHTTP(w, req)
+				return w
+			}
+
+			if w := serve(nil); w.Code != http.StatusUnauthorized {
+				t.Fatalf("anonymous: expected status %d, got %d", http.StatusUnauthorized, w.Code)
+			}
+			for _, as := range []struct {
+				name   string
+				userID uuid.UUID
+				want   int
+			}{
+				{"admin", adminID, tt.admin},
+				{"member", memberID, tt.member},
+				{"viewer", viewerID, tt.viewer},
+				{"outsider", outsiderID, tt.outsider},
+			} {
+				w := serve(&as.userID)
+				if w.Code != as.want {
+					t.Errorf("%s: expected status %d, got %d: %s", as.name, as.want, w.Code, w.Body.String())
+				}
+				if body := w.Body.String(); strings.Contains(body, passwordHash) || strings.Contains(body, `"password"`) {
+					t.Errorf("%s: response contains password material: %s", as.name, body)
+				}
+			}
+		})
+	}
+}
+
+// unusedAuthRepository backs the auth middleware in route tests. Their tokens
+// carry no session and are not personal access tokens, so it is never asked.
+type unusedAuthRepository struct {
+	auth.MiddlewareRepository
+}
+
+func newTestMiddleware() auth.Middleware {
+	return auth.NewMiddleware(unusedAuthRepository{})
+}
+
+func testToken(t *testing.T, userID uuid.UUID) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": userID.String(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+// TestCardsRoutes_BadIDs replaces each ID in every cards route with a
+// malformed one, and sends a token whose subject is not a user ID. Both are
+// rejected before reaching the service.
+func TestCardsRoutes_BadIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "secret")
+
+	userID := uuid.New()
+	router := gin.New()
+	router.Use(apperrors.Middleware())
+	svc := NewCardsService(&fakeCardsRepository{}, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+	registerCardsRoutes(router.Group("/api/v1"), NewCardsHandler(svc), newTestMiddleware())
+
+	// Payloads that bind, so that only the IDs can be at fault.
+	bodies := map[string]string{
+		"/api/v1/cards/update/:cardID":                   `{"title":"renamed"}`,
+		"/api/v1/cards/move/:cardID":                     `{}`,
+		"/api/v1/cards/attach_tags/:cardID":              `{"tags":["bug"]}`,
+		"/api/v1/cards/detach_tags/:cardID":              `{"tags":["bug"]}`,
+		"/api/v1/cards/:cardID/checklist/create":         `{"text":"new"}`,
+		"/api/v1/cards/:cardID/checklist/update/:itemID": `{"text":"renamed"}`,
+		"/api/v1/cards/create":                           `{"title":"new","content":"body"}`,
+		"/api/v1/cards/create_multiple_cards":            `[{"title":"new","content":"body"}]`,
+		"/api/v1/cards/archive_done":                     `{"older_than_days":30}`,
+		"/api/v1/cards/generate_multiple_cards":          `{"user_prompt":"plan the week"}`,
+	}
+	serve := func(method, path, body, sub string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", apperrors.ProblemContentType)
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": sub,
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("secret"))
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, route := range router.Routes() {
+		var params []string
+		for _, segment := range strings.Split(route.Path, "/") {
+			if strings.HasPrefix(segment, ":") {
+				params = append(params, segment)
+			}
+		}
+		validPath := route.Path
+		for _, param := range params {
+			validPath = strings.Replace(validPath, param, uuid.NewString(), 1)
+		}
+		body := bodies[route.Path]
+
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			if w := serve(route.Method, validPath, body, "not-a-uuid"); w.Code != http.StatusUnauthorized {
+				t.Errorf("malformed user ID: expected status %d, got %d: %s", http.StatusUnauthorized, w.Code, w.Body.String())
+			}
+
+			for _, param := range params {
+				path := route.Path
+				for _, other := range params {
+					id := uuid.NewString()
+					if other == param {
+						id = "not-a-uuid"
+					}
+					path = strings.Replace(path, other, id, 1)
+				}
+
+				w := serve(route.Method, path, body, userID.String())
+				if w.Code != http.StatusBadRequest {
+					t.Fatalf("malformed %s: expected status %d, got %d: %s", param, http.StatusBadRequest, w.Code, w.Body.String())
+				}
+				var problem apperrors.Problem
+				if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(problem.Errors) != 1 || ":"+problem.Errors[0].Field != param {
+					t.Fatalf("malformed %s: expected a field error for it, got %s", param, w.Body.String())
+				}
+			}
+		})
+	}
+}
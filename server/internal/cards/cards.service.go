@@ -2,45 +2,242 @@ package cards
 
 import (
 	"context"
-	"errors"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"cards/internal/apperrors"
+	"cards/internal/authz"
+	"cards/internal/boards"
 	"cards/internal/llm"
 	"cards/internal/models"
+	"cards/internal/storage"
+	"cards/internal/tags"
+	"cards/internal/workspaces"
 )
 
 type CardsService interface {
-	List(userID uuid.UUID) ([]models.Card, error)
-	GetByID(cardID uuid.UUID) (*models.Card, error)
+	List(userID uuid.UUID, query ListCardsQuery) (*CardsPage, error)
+	ListByBoard(userID uuid.UUID, boardID uuid.UUID) ([]models.Card, error)
+	Search(userID uuid.UUID, query SearchCardsQuery) (*CardsSearchPage, error)
+	GetByID(userID uuid.UUID, cardID uuid.UUID) (*models.Card, error)
+	GetAuthorized(userID uuid.UUID, cardID uuid.UUID, action authz.Action) (*models.Card, error)
 	Create(userID uuid.UUID, dto CreateCardDTO) (*models.Card, error)
 	CreateMultiple(userID uuid.UUID, dto []CreateCardDTO) ([]models.Card, error)
 	GenerateMultipleCards(userID uuid.UUID, userPrompt string) ([]SimpleCardResponseDTO, error)
 	Update(userID uuid.UUID, cardID uuid.UUID, dto UpdateCardDTO) (*SimpleCardResponseDTO, error)
+	ListRevisions(userID uuid.UUID, cardID uuid.UUID) ([]models.CardRevision, error)
+	RestoreRevision(userID uuid.UUID, cardID uuid.UUID, revisionID uuid.UUID) (*models.Card, error)
+	Move(userID uuid.UUID, cardID uuid.UUID, dto MoveCardDTO) (*models.Card, error)
+	AttachTags(userID uuid.UUID, cardID uuid.UUID, dto CardTagsDTO) (*models.Card, error)
+	DetachTags(userID uuid.UUID, cardID uuid.UUID, dto CardTagsDTO) (*models.Card, error)
+	AddChecklistItem(userID uuid.UUID, cardID uuid.UUID, dto CreateChecklistItemDTO) (*models.Card, error)
+	UpdateChecklistItem(userID uuid.UUID, cardID uuid.UUID, itemID uuid.UUID, dto UpdateChecklistItemDTO) (*models.Card, error)
+	ToggleChecklistItem(userID uuid.UUID, cardID uuid.UUID, itemID uuid.UUID) (*models.Card, error)
+	DeleteChecklistItem(userID uuid.UUID, cardID uuid.UUID, itemID uuid.UUID) (*models.Card, error)
 	Delete(userID uuid.UUID, cardID uuid.UUID) (*SimpleCardResponseDTO, error)
+	ListTrash(userID uuid.UUID, query ListTrashQuery) ([]models.Card, error)
+	Restore(userID uuid.UUID, cardID uuid.UUID) (*models.Card, error)
+	Purge(userID uuid.UUID, cardID uuid.UUID) (*models.Card, error)
+	PurgeTrashedBefore(before time.Time) (int, error)
+	Archive(userID uuid.UUID, cardID uuid.UUID) (*models.Card, error)
+	Unarchive(userID uuid.UUID, cardID uuid.UUID) (*models.Card, error)
+	ArchiveDone(userID uuid.UUID, dto ArchiveDoneCardsDTO) (*ArchiveDoneCardsResultDTO, error)
+	ArchiveDoneBefore(before time.Time) (int64, error)
 }
 
 type cardsService struct {
 	DB         *gorm.DB
 	Repository CardsRepository
+	Boards     boards.BoardsService
+	Tags       tags.TagsService
+	Workspaces workspaces.WorkspacesService
+	Blobs      storage.BlobStore
 }
 
-func NewCardsService(repository CardsRepository) CardsService {
-	return &cardsService{Repository: repository}
+func NewCardsService(
+	repository CardsRepository,
+	boardsService boards.BoardsService,
+	tagsService tags.TagsService,
+	workspacesService workspaces.WorkspacesService,
+	blobStore storage.BlobStore,
+) CardsService {
+	return &cardsService{Repository: repository, Boards: boardsService, Tags: tagsService, Workspaces: workspacesService, Blobs: blobStore}
 }
 
-func (s *cardsService) List(userID uuid.UUID) ([]models.Card, error) {
-	cards, err := s.Repository.ListByUserID(userID)
+func (s *cardsService) List(userID uuid.UUID, query ListCardsQuery) (*CardsPage, error) {
+	workspaceID, err := s.Workspaces.Resolve(userID, query.WorkspaceID, authz.Read)
 	if err != nil {
 		return nil, err
 	}
 
-	return cards, nil
+	cardsQuery := CardsQuery{
+		WorkspaceID:   workspaceID,
+		Text:          strings.TrimSpace(query.Q),
+		DueBefore:     query.DueBefore,
+		DueAfter:      query.DueAfter,
+		CreatedBefore: query.CreatedBefore,
+		CreatedAfter:  query.CreatedAfter,
+		UpdatedBefore: query.UpdatedBefore,
+		UpdatedAfter:  query.UpdatedAfter,
+		Archived:      query.Archived,
+		Sort:          query.Sort,
+		Limit:         query.Limit,
+	}
+	if cardsQuery.Sort == "" {
+		cardsQuery.Sort = CardSortRank
+	}
+	if cardsQuery.Limit <= 0 {
+		cardsQuery.Limit = defaultCardsPageSize
+	}
+	if cardsQuery.Limit > maxCardsPageSize {
+		cardsQuery.Limit = maxCardsPageSize
+	}
+
+	switch query.Order {
+	case SortOrderAsc:
+		cardsQuery.Descending = false
+	case SortOrderDesc:
+		cardsQuery.Descending = true
+	default:
+		switch cardsQuery.Sort {
+		case CardSortPriority, CardSortCreatedAt, CardSortUpdatedAt:
+			cardsQuery.Descending = true
+		}
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeCardsCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Sort != cardsQuery.Sort || cursor.Desc != cardsQuery.Descending {
+			return nil, apperrors.Validation("cursor does not match the requested sort")
+		}
+		cardsQuery.After = cursor
+	}
+
+	for _, status := range strings.Split(query.Status, ",") {
+		status = strings.TrimSpace(status)
+		if status == "" {
+			continue
+		}
+		if !cardStatus(status).valid() {
+			return nil, apperrors.Validation("invalid status filter")
+		}
+		cardsQuery.Statuses = append(cardsQuery.Statuses, cardStatus(status))
+	}
+
+	if query.Overdue {
+		now := time.Now()
+		cardsQuery.OverdueAt = &now
+	}
+
+	if names := tags.NormalizeNames(strings.Split(query.Tags, ",")); len(names) > 0 {
+		found, err := s.Tags.FindByNames(workspaceID, names)
+		if err != nil {
+			return nil, err
+		}
+
+		cardsQuery.MatchAllTags = query.TagMatch == TagMatchAll
+		if len(found) == 0 || (cardsQuery.MatchAllTags && len(found) < len(names)) {
+			return &CardsPage{Cards: []models.Card{}}, nil
+		}
+
+		for _, tag := range found {
+			cardsQuery.TagIDs = append(cardsQuery.TagIDs, tag.ID)
+		}
+	}
+
+	total, err := s.Repository.Count(cardsQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := cardsQuery.Limit
+	cardsQuery.Limit = pageSize + 1
+	cards, err := s.Repository.List(cardsQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &CardsPage{Cards: cards, Total: total}
+	if page.Cards == nil {
+		page.Cards = []models.Card{}
+	}
+	if len(cards) > pageSize {
+		page.Cards = cards[:pageSize]
+		next := newCardsCursor(page.Cards[pageSize-1], cardsQuery.Sort, cardsQuery.Descending).encode()
+		page.NextCursor = &next
+	}
+
+	return page, nil
 }
 
-func (s *cardsService) GetByID(cardID uuid.UUID) (*models.Card, error) {
-	card, err := s.Repository.FindByID(cardID)
+func (s *cardsService) Search(userID uuid.UUID, query SearchCardsQuery) (*CardsSearchPage, error) {
+	text := strings.TrimSpace(query.Q)
+	if text == "" {
+		return nil, apperrors.Validation("search query is required")
+	}
+
+	workspaceID, err := s.Workspaces.Resolve(userID, query.WorkspaceID, authz.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultCardsPageSize
+	}
+	if limit > maxCardsPageSize {
+		limit = maxCardsPageSize
+	}
+
+	offset := 0
+	if query.Cursor != "" {
+		cursor, err := decodeSearchCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		offset = cursor.Offset
+	}
+
+	results, total, err := s.Repository.Search(CardsSearchQuery{
+		WorkspaceID: workspaceID,
+		Text:        text,
+		Offset:      offset,
+		Limit:       limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &CardsSearchPage{Results: results, Total: total}
+	if next := offset + len(results); len(results) == limit && int64(next) < total {
+		cursor := searchCursor{Offset: next}.encode()
+		page.NextCursor = &cursor
+	}
+
+	return page, nil
+}
+
+func (s *cardsService) ListByBoard(userID uuid.UUID, boardID uuid.UUID) ([]models.Card, error) {
+	if _, err := s.Boards.GetByID(userID, boardID); err != nil {
+		return nil, err
+	}
+
+	return s.Repository.ListByBoardID(boardID)
+}
+
+func (s *cardsService) GetByID(userID uuid.UUID, cardID uuid.UUID) (*models.Card, error) {
+	return s.GetAuthorized(userID, cardID, authz.Read)
+}
+
+func (s *cardsService) GetAuthorized(userID uuid.UUID, cardID uuid.UUID, action authz.Action) (*models.Card, error) {
+	card, err := s.getAuthorized(userID, cardID, action)
 	if err != nil {
 		return nil, err
 	}
@@ -48,28 +245,82 @@ func (s *cardsService) GetByID(cardID uuid.UUID) (*models.Card, error) {
 }
 
 func (s *cardsService) Create(userID uuid.UUID, dto CreateCardDTO) (*models.Card, error) {
+	column, err := s.resolveColumn(userID, dto.BoardID, dto.ColumnID)
+	if err != nil {
+		return nil, err
+	}
+
+	rank, err := s.appendRank(column.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	card := models.Card{
-		Title:   dto.Title,
-		Content: dto.Content,
-		Status:  string(CardStatusUndone),
-		UserID:  userID,
+		Title:     dto.Title,
+		Content:   dto.Content,
+		Status:    string(statusForColumn(column, CardStatusUndone)),
+		UserID:    userID,
+		Rank:      rank,
+		DueAt:     dto.DueAt,
+		Checklist: newChecklist(dto.Checklist),
+	}
+	if dto.Priority != nil {
+		card.Priority = *dto.Priority
+	}
+	if err := s.placeCard(userID, &card, column); err != nil {
+		return nil, err
+	}
+	if card.Tags, err = s.resolveTags(userID, card, dto.Tags); err != nil {
+		return nil, err
 	}
 
 	if err := s.Repository.Create(&card); err != nil {
 		return nil, err
 	}
 
+	card.ChecklistProgress = models.NewChecklistProgress(card.Checklist)
 	return &card, nil
 }
 
 func (s *cardsService) CreateMultiple(userID uuid.UUID, dto []CreateCardDTO) ([]models.Card, error) {
 	var cards []models.Card
+	// batched holds the indexes of the cards placed in each column so far, in
+	// rank order, so a rebalance can move them along with the stored cards.
+	batched := map[uuid.UUID][]int{}
 	for _, cardDTO := range dto {
+		column, err := s.resolveColumn(userID, cardDTO.BoardID, cardDTO.ColumnID)
+		if err != nil {
+			return nil, err
+		}
+
+		var rank string
+		if placed := batched[column.ID]; len(placed) > 0 {
+			rank, err = s.appendBatchRank(column.ID, cards, placed)
+		} else {
+			rank, err = s.appendRank(column.ID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		batched[column.ID] = append(batched[column.ID], len(cards))
+
 		card := models.Card{
-			Title:   cardDTO.Title,
-			Content: cardDTO.Content,
-			Status:  string(CardStatusUndone),
-			UserID:  userID,
+			Title:     cardDTO.Title,
+			Content:   cardDTO.Content,
+			Status:    string(statusForColumn(column, CardStatusUndone)),
+			UserID:    userID,
+			Rank:      rank,
+			DueAt:     cardDTO.DueAt,
+			Checklist: newChecklist(cardDTO.Checklist),
+		}
+		if cardDTO.Priority != nil {
+			card.Priority = *cardDTO.Priority
+		}
+		if err := s.placeCard(userID, &card, column); err != nil {
+			return nil, err
+		}
+		if card.Tags, err = s.resolveTags(userID, card, cardDTO.Tags); err != nil {
+			return nil, err
 		}
 		cards = append(cards, card)
 	}
@@ -77,6 +328,9 @@ func (s *cardsService) CreateMultiple(userID uuid.UUID, dto []CreateCardDTO) ([]
 	if err := s.Repository.CreateMultiple(cards); err != nil {
 		return nil, err
 	}
+	for i := range cards {
+		cards[i].ChecklistProgress = models.NewChecklistProgress(cards[i].Checklist)
+	}
 
 	return cards, nil
 }
@@ -91,70 +345,892 @@ func (s *cardsService) GenerateMultipleCards(userID uuid.UUID, userPrompt string
 		},
 	})
 
+	s.recordLLMUsage(userID, cardsResp, err)
 	if err != nil {
 		return nil, err
 	}
 
 	var simpleCards []SimpleCardResponseDTO
 	for _, card := range cardsResp.Cards {
-		simpleCards = append(simpleCards, SimpleCardResponseDTO{
-			Title:   card.Title,
-			Content: card.Content,
-			Status:  CardStatusUndone,
-		})
+		simpleCards = append(simpleCards, fromLLMCard(card))
 	}
 
 	return simpleCards, nil
 }
 
+// recordLLMUsage counts a generation request, failed or not, for admin usage
+// reports. A failure to record it does not fail the request.
+func (s *cardsService) recordLLMUsage(userID uuid.UUID, resp *llm.CardsResponse, err error) {
+	usage := models.LLMUsage{UserID: userID, Succeeded: err == nil}
+	if resp != nil {
+		usage.CardsGenerated = len(resp.Cards)
+	}
+	if err := s.Repository.RecordLLMUsage(&usage); err != nil {
+		log.Printf("recording LLM usage for user %s failed: %v", userID, err)
+	}
+}
+
 func (s *cardsService) Update(userID uuid.UUID, cardID uuid.UUID, dto UpdateCardDTO) (*SimpleCardResponseDTO, error) {
-	card, err := s.Repository.FindByID(cardID)
+	card, err := s.getAuthorized(userID, cardID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	card, err = s.update(userID, card, dto, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSimpleCardResponse(card), nil
+}
+
+func (s *cardsService) ListRevisions(userID uuid.UUID, cardID uuid.UUID) ([]models.CardRevision, error) {
+	if _, err := s.getAuthorized(userID, cardID, authz.Read); err != nil {
+		return nil, err
+	}
+
+	return s.Repository.ListRevisions(cardID)
+}
+
+// RestoreRevision rolls the card back to how it was before the given revision,
+// undoing it and every later change. The rollback is itself recorded as a new
+// revision.
+func (s *cardsService) RestoreRevision(userID uuid.UUID, cardID uuid.UUID, revisionID uuid.UUID) (*models.Card, error) {
+	card, err := s.getAuthorized(userID, cardID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.Repository.ListRevisions(cardID)
 	if err != nil {
 		return nil, err
 	}
 
-	if card.UserID != userID {
-		return nil, errors.New("unauthorized")
+	index := -1
+	for i := range revisions {
+		if revisions[i].ID == revisionID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	dto, err := revertDTO(revisions[:index+1])
+	if err != nil {
+		return nil, err
+	}
+	if dto.ColumnID != nil {
+		// The column may have been deleted since; the status still places the
+		// card in a matching column of its board.
+		if _, err := s.Boards.GetColumn(userID, *dto.ColumnID); err != nil {
+			dto.ColumnID = nil
+		}
+	}
+
+	card, err = s.update(userID, card, dto, &revisionID)
+	if err != nil {
+		return nil, err
 	}
 
+	return &card, nil
+}
+
+func (s *cardsService) update(userID uuid.UUID, card models.Card, dto UpdateCardDTO, restoredFromID *uuid.UUID) (models.Card, error) {
+	before := card
+
 	if dto.Title != nil {
 		card.Title = *dto.Title
 	}
 	if dto.Content != nil {
 		card.Content = *dto.Content
 	}
+	if dto.ClearDueAt {
+		card.DueAt = nil
+	} else if dto.DueAt != nil {
+		card.DueAt = dto.DueAt
+	}
+	if dto.Priority != nil {
+		card.Priority = *dto.Priority
+	}
+
+	var err error
+	previousColumnID := card.ColumnID
+	if dto.ColumnID != nil {
+		column, err := s.resolveColumn(userID, nil, dto.ColumnID)
+		if err != nil {
+			return models.Card{}, err
+		}
+		if err := s.placeCard(userID, &card, column); err != nil {
+			return models.Card{}, err
+		}
+		card.Status = string(statusForColumn(column, cardStatus(card.Status)))
+	}
 	if dto.Status != nil {
 		card.Status = string(*dto.Status)
+		if dto.ColumnID == nil && card.BoardID != nil {
+			board, err := s.Boards.GetByID(userID, *card.BoardID)
+			if err != nil {
+				return models.Card{}, err
+			}
+			for _, column := range board.Columns {
+				if column.Name == card.Status {
+					card.ColumnID = &column.ID
+					break
+				}
+			}
+		}
+	}
+	if card.ColumnID != nil && (previousColumnID == nil || *previousColumnID != *card.ColumnID) {
+		if card.Rank, err = s.appendRank(*card.ColumnID); err != nil {
+			return models.Card{}, err
+		}
 	}
 
-	if err := s.Repository.Update(&card); err != nil {
+	if dto.Tags != nil {
+		if card.Tags, err = s.resolveTags(userID, card, *dto.Tags); err != nil {
+			return models.Card{}, err
+		}
+	}
+
+	if err := s.Repository.Update(&card, newCardEdit(userID, before, card, restoredFromID)); err != nil {
+		return models.Card{}, err
+	}
+
+	return card, nil
+}
+
+func (s *cardsService) Move(userID uuid.UUID, cardID uuid.UUID, dto MoveCardDTO) (*models.Card, error) {
+	card, err := s.getAuthorized(userID, cardID, authz.Write)
+	if err != nil {
 		return nil, err
 	}
 
-	return &SimpleCardResponseDTO{
-		Title:   card.Title,
-		Content: card.Content,
-		Status:  cardStatus(card.Status),
-	}, nil
+	before := card
+	column, err := s.moveTarget(userID, card, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	siblings, err := s.Repository.ListByColumnID(column.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range siblings {
+		if siblings[i].ID == card.ID {
+			siblings = append(siblings[:i], siblings[i+1:]...)
+			break
+		}
+	}
+
+	position, err := movePosition(siblings, dto.BeforeID, dto.AfterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.placeCard(userID, &card, column); err != nil {
+		return nil, err
+	}
+	if dto.Status != nil {
+		card.Status = string(*dto.Status)
+	} else {
+		card.Status = string(statusForColumn(column, cardStatus(card.Status)))
+	}
+
+	var rebalanced []models.Card
+	rank, ok := rankAt(siblings, position)
+	if ok {
+		card.Rank = rank
+	} else {
+		rebalanced = append(append(append([]models.Card{}, siblings[:position]...), card), siblings[position:]...)
+		for i, rank := range spreadRanks(len(rebalanced)) {
+			rebalanced[i].Rank = rank
+		}
+		card.Rank = rebalanced[position].Rank
+	}
+
+	if err := s.Repository.Move(&card, newCardEdit(userID, before, card, nil), rebalanced); err != nil {
+		return nil, err
+	}
+
+	return &card, nil
 }
 
 func (s *cardsService) Delete(userID uuid.UUID, cardID uuid.UUID) (*SimpleCardResponseDTO, error) {
+	card, err := s.getAuthorized(userID, cardID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Repository.Delete(cardID); err != nil {
+		return nil, err
+	}
+
+	return toSimpleCardResponse(card), nil
+}
+
+func (s *cardsService) ListTrash(userID uuid.UUID, query ListTrashQuery) ([]models.Card, error) {
+	workspaceID, err := s.Workspaces.Resolve(userID, query.WorkspaceID, authz.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repository.ListTrash(workspaceID)
+}
+
+// Restore takes a card out of the trash. If its column was deleted meanwhile
+// (for instance together with its board) the card goes to the default board.
+func (s *cardsService) Restore(userID uuid.UUID, cardID uuid.UUID) (*models.Card, error) {
+	card, err := s.getTrashed(userID, cardID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	var column *models.Column
+	if card.ColumnID != nil {
+		column, err = s.Boards.GetColumn(userID, *card.ColumnID)
+	}
+	if column == nil || err != nil {
+		if column, err = s.resolveColumn(userID, nil, nil); err != nil {
+			return nil, err
+		}
+		card.Status = string(statusForColumn(column, cardStatus(card.Status)))
+	}
+
+	if err := s.placeCard(userID, &card, column); err != nil {
+		return nil, err
+	}
+	if card.Rank, err = s.appendRank(column.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repository.Restore(&card); err != nil {
+		return nil, err
+	}
+
+	return &card, nil
+}
+
+func (s *cardsService) Purge(userID uuid.UUID, cardID uuid.UUID) (*models.Card, error) {
+	card, err := s.getTrashed(userID, cardID, authz.Manage)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.purge(card.ID); err != nil {
+		return nil, err
+	}
+
+	return &card, nil
+}
+
+// PurgeTrashedBefore permanently removes every card trashed before the given
+// time and returns how many were removed.
+func (s *cardsService) PurgeTrashedBefore(before time.Time) (int, error) {
+	purged := 0
+	for {
+		cards, err := s.Repository.ListTrashedBefore(before, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		for _, card := range cards {
+			if err := s.purge(card.ID); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+
+		if len(cards) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+func (s *cardsService) purge(cardID uuid.UUID) error {
+	storageKeys, err := s.Repository.Purge(cardID)
+	if err != nil {
+		return err
+	}
+
+	// The rows are gone already; a blob that fails to delete is only wasted
+	// space, so it is logged rather than failing the purge.
+	for _, key := range storageKeys {
+		if err := s.Blobs.Delete(context.Background(), key); err != nil {
+			log.Printf("failed to remove attachment blob %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+func (s *cardsService) getTrashed(userID uuid.UUID, cardID uuid.UUID, action authz.Action) (models.Card, error) {
+	card, err := s.Repository.FindTrashedByID(cardID)
+	if err != nil {
+		return models.Card{}, err
+	}
+
+	if err := s.authorize(userID, card, action); err != nil {
+		return models.Card{}, err
+	}
+
+	return card, nil
+}
+
+func (s *cardsService) AttachTags(userID uuid.UUID, cardID uuid.UUID, dto CardTagsDTO) (*models.Card, error) {
+	card, err := s.getAuthorized(userID, cardID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	cardTags, err := s.resolveTags(userID, card, dto.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	attached := map[uuid.UUID]bool{}
+	for _, tag := range card.Tags {
+		attached[tag.ID] = true
+	}
+	tagsAfter := append([]models.Tag{}, card.Tags...)
+	for _, tag := range cardTags {
+		if !attached[tag.ID] {
+			attached[tag.ID] = true
+			tagsAfter = append(tagsAfter, tag)
+		}
+	}
+	if len(tagsAfter) == len(card.Tags) {
+		return &card, nil
+	}
+
+	if card, err = s.replaceTags(userID, card, tagsAfter); err != nil {
+		return nil, err
+	}
+
+	return &card, nil
+}
+
+func (s *cardsService) DetachTags(userID uuid.UUID, cardID uuid.UUID, dto CardTagsDTO) (*models.Card, error) {
+	card, err := s.getAuthorized(userID, cardID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceID, err := s.tagsWorkspaceID(card)
+	if err != nil {
+		return nil, err
+	}
+	cardTags, err := s.Tags.FindByNames(workspaceID, dto.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	detached := map[uuid.UUID]bool{}
+	for _, tag := range cardTags {
+		detached[tag.ID] = true
+	}
+	tagsAfter := []models.Tag{}
+	for _, tag := range card.Tags {
+		if !detached[tag.ID] {
+			tagsAfter = append(tagsAfter, tag)
+		}
+	}
+	if len(tagsAfter) == len(card.Tags) {
+		return &card, nil
+	}
+
+	if card, err = s.replaceTags(userID, card, tagsAfter); err != nil {
+		return nil, err
+	}
+
+	return &card, nil
+}
+
+// replaceTags gives the card exactly the given tags, recording the change as
+// a revision so it can be restored like any other edit.
+func (s *cardsService) replaceTags(userID uuid.UUID, card models.Card, tags []models.Tag) (models.Card, error) {
+	before := card
+	card.Tags = tags
+	if err := s.Repository.Update(&card, newCardEdit(userID, before, card, nil)); err != nil {
+		return models.Card{}, err
+	}
+	return card, nil
+}
+
+func (s *cardsService) AddChecklistItem(userID uuid.UUID, cardID uuid.UUID, dto CreateChecklistItemDTO) (*models.Card, error) {
+	card, err := s.getAuthorized(userID, cardID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	item := models.ChecklistItem{Text: dto.Text, Position: len(card.Checklist), CardID: card.ID}
+	if err := s.Repository.CreateChecklistItem(&item); err != nil {
+		return nil, err
+	}
+
+	position := item.Position
+	if dto.Position != nil {
+		position = *dto.Position
+	}
+	card.Checklist = moveChecklistItem(append(card.Checklist, item), item.ID, position)
+	if err := s.Repository.SaveChecklist(card.Checklist); err != nil {
+		return nil, err
+	}
+
+	card.ChecklistProgress = models.NewChecklistProgress(card.Checklist)
+	return &card, nil
+}
+
+func (s *cardsService) UpdateChecklistItem(userID uuid.UUID, cardID uuid.UUID, itemID uuid.UUID, dto UpdateChecklistItemDTO) (*models.Card, error) {
+	card, err := s.getAuthorized(userID, cardID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	index := checklistIndex(card.Checklist, itemID)
+	if index < 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if dto.Text != nil {
+		card.Checklist[index].Text = *dto.Text
+	}
+	if dto.Done != nil {
+		card.Checklist[index].Done = *dto.Done
+	}
+	if dto.Position != nil {
+		card.Checklist = moveChecklistItem(card.Checklist, itemID, *dto.Position)
+	}
+
+	if err := s.Repository.SaveChecklist(card.Checklist); err != nil {
+		return nil, err
+	}
+
+	card.ChecklistProgress = models.NewChecklistProgress(card.Checklist)
+	return &card, nil
+}
+
+func (s *cardsService) ToggleChecklistItem(userID uuid.UUID, cardID uuid.UUID, itemID uuid.UUID) (*models.Card, error) {
+	card, err := s.getAuthorized(userID, cardID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	index := checklistIndex(card.Checklist, itemID)
+	if index < 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	card.Checklist[index].Done = !card.Checklist[index].Done
+	if err := s.Repository.SaveChecklist(card.Checklist[index : index+1]); err != nil {
+		return nil, err
+	}
+
+	card.ChecklistProgress = models.NewChecklistProgress(card.Checklist)
+	return &card, nil
+}
+
+func (s *cardsService) DeleteChecklistItem(userID uuid.UUID, cardID uuid.UUID, itemID uuid.UUID) (*models.Card, error) {
+	card, err := s.getAuthorized(userID, cardID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	index := checklistIndex(card.Checklist, itemID)
+	if index < 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if err := s.Repository.DeleteChecklistItem(itemID); err != nil {
+		return nil, err
+	}
+
+	card.Checklist = append(card.Checklist[:index], card.Checklist[index+1:]...)
+	for i := range card.Checklist {
+		card.Checklist[i].Position = i
+	}
+	if err := s.Repository.SaveChecklist(card.Checklist); err != nil {
+		return nil, err
+	}
+
+	card.ChecklistProgress = models.NewChecklistProgress(card.Checklist)
+	return &card, nil
+}
+
+func (s *cardsService) getAuthorized(userID uuid.UUID, cardID uuid.UUID, action authz.Action) (models.Card, error) {
 	card, err := s.Repository.FindByID(cardID)
+	if err != nil {
+		return models.Card{}, err
+	}
+
+	if err := s.authorize(userID, card, action); err != nil {
+		return models.Card{}, err
+	}
+
+	return card, nil
+}
+
+func (s *cardsService) authorize(userID uuid.UUID, card models.Card, action authz.Action) error {
+	subject, err := s.Workspaces.Subject(userID)
+	if err != nil {
+		return err
+	}
+	return authz.Can(subject, action, authz.Card(card))
+}
+
+// placeCard puts the card in the given column. The user must be allowed to
+// add cards to the column's board, and the card joins that board's workspace.
+// Tags belong to a workspace, so a card changing workspace swaps its tags for
+// the new workspace's tags of the same names.
+func (s *cardsService) placeCard(userID uuid.UUID, card *models.Card, column *models.Column) error {
+	board, err := s.Boards.Authorize(userID, column.BoardID, authz.Write)
+	if err != nil {
+		return err
+	}
+
+	if len(card.Tags) > 0 && board.WorkspaceID != nil && !sameID(card.WorkspaceID, board.WorkspaceID) {
+		names := make([]string, 0, len(card.Tags))
+		for _, tag := range card.Tags {
+			names = append(names, tag.Name)
+		}
+		if card.Tags, err = s.Tags.Resolve(userID, *board.WorkspaceID, names); err != nil {
+			return err
+		}
+	}
+
+	card.WorkspaceID = board.WorkspaceID
+	card.BoardID = &column.BoardID
+	card.ColumnID = &column.ID
+	return nil
+}
+
+// resolveTags returns the tags with the given names from the card's
+// workspace, creating missing ones.
+func (s *cardsService) resolveTags(userID uuid.UUID, card models.Card, names []string) ([]models.Tag, error) {
+	workspaceID, err := s.tagsWorkspaceID(card)
 	if err != nil {
 		return nil, err
 	}
+	return s.Tags.Resolve(userID, workspaceID, names)
+}
 
-	if card.UserID != userID {
-		return nil, errors.New("unauthorized")
+// tagsWorkspaceID is the workspace the card's tags come from. Cards that
+// predate workspaces are migrated into their owner's personal workspace, so
+// that is where their tags live too.
+func (s *cardsService) tagsWorkspaceID(card models.Card) (uuid.UUID, error) {
+	if card.WorkspaceID != nil {
+		return *card.WorkspaceID, nil
+	}
+	workspace, err := s.Workspaces.EnsurePersonal(card.UserID)
+	if err != nil {
+		return uuid.Nil, err
 	}
+	return workspace.ID, nil
+}
 
-	if err := s.Repository.Delete(cardID); err != nil {
+// resolveColumn picks the column a card should live in: the explicit column if
+// given, otherwise the "undone" (or first) column of the given board, falling
+// back to the user's default board.
+func (s *cardsService) resolveColumn(userID uuid.UUID, boardID *uuid.UUID, columnID *uuid.UUID) (*models.Column, error) {
+	if columnID != nil {
+		column, err := s.Boards.GetColumn(userID, *columnID)
+		if err != nil {
+			return nil, err
+		}
+		if boardID != nil && *boardID != column.BoardID {
+			return nil, apperrors.Validation("column does not belong to board")
+		}
+		return column, nil
+	}
+
+	var board *models.Board
+	var err error
+	if boardID != nil {
+		board, err = s.Boards.GetByID(userID, *boardID)
+	} else {
+		board, err = s.Boards.EnsureDefaultBoard(userID)
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	return &SimpleCardResponseDTO{
+	if len(board.Columns) == 0 {
+		return nil, apperrors.Conflict("board has no columns")
+	}
+	for i := range board.Columns {
+		if board.Columns[i].Name == string(CardStatusUndone) {
+			return &board.Columns[i], nil
+		}
+	}
+	return &board.Columns[0], nil
+}
+
+func statusForColumn(column *models.Column, fallback cardStatus) cardStatus {
+	if status := cardStatus(column.Name); status.valid() {
+		return status
+	}
+	return fallback
+}
+
+func (s *cardsService) moveTarget(userID uuid.UUID, card models.Card, dto MoveCardDTO) (*models.Column, error) {
+	if dto.ColumnID != nil {
+		return s.resolveColumn(userID, nil, dto.ColumnID)
+	}
+	if card.BoardID == nil || card.ColumnID == nil {
+		return s.resolveColumn(userID, nil, nil)
+	}
+
+	board, err := s.Boards.GetByID(userID, *card.BoardID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range board.Columns {
+		if dto.Status != nil && board.Columns[i].Name == string(*dto.Status) {
+			return &board.Columns[i], nil
+		}
+		if dto.Status == nil && board.Columns[i].ID == *card.ColumnID {
+			return &board.Columns[i], nil
+		}
+	}
+	return nil, apperrors.Conflict("board has no column for status")
+}
+
+// movePosition returns the index in siblings the moved card should take,
+// given the neighbour it goes before and/or after.
+func movePosition(siblings []models.Card, beforeID *uuid.UUID, afterID *uuid.UUID) (int, error) {
+	indexOf := func(id uuid.UUID) int {
+		for i := range siblings {
+			if siblings[i].ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+
+	position := len(siblings)
+	if afterID != nil {
+		index := indexOf(*afterID)
+		if index < 0 {
+			return 0, apperrors.Conflict("after card is not in the target column")
+		}
+		position = index + 1
+	}
+	if beforeID != nil {
+		index := indexOf(*beforeID)
+		if index < 0 {
+			return 0, apperrors.Conflict("before card is not in the target column")
+		}
+		if afterID != nil && index != position {
+			return 0, apperrors.Conflict("before and after cards are not adjacent")
+		}
+		position = index
+	}
+	return position, nil
+}
+
+// rankAt computes a rank for a card inserted at position. Cards created before
+// ranks existed have an empty rank, which leaves no room and forces a rebalance.
+func rankAt(siblings []models.Card, position int) (string, bool) {
+	prev := ""
+	if position > 0 {
+		prev = siblings[position-1].Rank
+	}
+	if position == len(siblings) {
+		return rankAfter(prev)
+	}
+
+	next := siblings[position].Rank
+	if next == "" {
+		return "", false
+	}
+	return rankBetween(prev, next)
+}
+
+// appendRank returns a rank after every card of the column, rebalancing the
+// column first when the tail has run out of room.
+func (s *cardsService) appendRank(columnID uuid.UUID) (string, error) {
+	last, err := s.Repository.LastRankInColumn(columnID)
+	if err != nil {
+		return "", err
+	}
+	if rank, ok := rankAfter(last); ok {
+		return rank, nil
+	}
+
+	cards, err := s.Repository.ListByColumnID(columnID)
+	if err != nil {
+		return "", err
+	}
+	ranks := spreadRanks(len(cards) + 1)
+	for i := range cards {
+		cards[i].Rank = ranks[i]
+	}
+	if err := s.Repository.UpdateRanks(cards); err != nil {
+		return "", err
+	}
+	return ranks[len(cards)], nil
+}
+
+// appendBatchRank ranks a card after the cards at placed, which are not
+// stored yet. When no rank fits, it rebalances the column like appendRank,
+// re-ranking the stored cards and the placed ones together.
+func (s *cardsService) appendBatchRank(columnID uuid.UUID, cards []models.Card, placed []int) (string, error) {
+	if rank, ok := rankAfter(cards[placed[len(placed)-1]].Rank); ok {
+		return rank, nil
+	}
+
+	stored, err := s.Repository.ListByColumnID(columnID)
+	if err != nil {
+		return "", err
+	}
+	ranks := spreadRanks(len(stored) + len(placed) + 1)
+	for i := range stored {
+		stored[i].Rank = ranks[i]
+	}
+	if err := s.Repository.UpdateRanks(stored); err != nil {
+		return "", err
+	}
+	for i, index := range placed {
+		cards[index].Rank = ranks[len(stored)+i]
+	}
+	return ranks[len(ranks)-1], nil
+}
+
+func newChecklist(texts []string) []models.ChecklistItem {
+	var items []models.ChecklistItem
+	for _, text := range texts {
+		if text = strings.TrimSpace(text); text != "" {
+			items = append(items, models.ChecklistItem{Text: text, Position: len(items)})
+		}
+	}
+	return items
+}
+
+func checklistIndex(items []models.ChecklistItem, itemID uuid.UUID) int {
+	for i, item := range items {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+func moveChecklistItem(items []models.ChecklistItem, itemID uuid.UUID, position int) []models.ChecklistItem {
+	index := checklistIndex(items, itemID)
+	if index < 0 {
+		return items
+	}
+
+	item := items[index]
+	rest := append(append([]models.ChecklistItem{}, items[:index]...), items[index+1:]...)
+	if position > len(rest) {
+		position = len(rest)
+	}
+
+	ordered := append(append(append([]models.ChecklistItem{}, rest[:position]...), item), rest[position:]...)
+	for i := range ordered {
+		ordered[i].Position = i
+	}
+	return ordered
+}
+
+func toSimpleCardResponse(card models.Card) *SimpleCardResponseDTO {
+	resp := &SimpleCardResponseDTO{
+		Title:             card.Title,
+		Content:           card.Content,
+		Status:            cardStatus(card.Status),
+		DueAt:             card.DueAt,
+		Priority:          card.Priority,
+		ChecklistProgress: models.NewChecklistProgress(card.Checklist),
+	}
+	for _, tag := range card.Tags {
+		resp.Tags = append(resp.Tags, tag.Name)
+	}
+	for _, item := range card.Checklist {
+		resp.Checklist = append(resp.Checklist, item.Text)
+	}
+	return resp
+}
+
+// fromLLMCard maps a generated card, dropping a due date or priority the model
+// returned in an unexpected format rather than failing the whole batch.
+func fromLLMCard(card llm.Card) SimpleCardResponseDTO {
+	simpleCard := SimpleCardResponseDTO{
 		Title:   card.Title,
 		Content: card.Content,
-		Status:  cardStatus(card.Status),
-	}, nil
+		Status:  CardStatusUndone,
+	}
+
+	for _, item := range newChecklist(card.Checklist) {
+		simpleCard.Checklist = append(simpleCard.Checklist, item.Text)
+	}
+	simpleCard.ChecklistProgress = models.ChecklistProgress{Total: len(simpleCard.Checklist)}
+
+	if priority, err := models.ParsePriority(card.Priority); err == nil {
+		simpleCard.Priority = priority
+	}
+
+	if card.DueDate != nil {
+		for _, layout := range []string{"2006-01-02", time.RFC3339} {
+			if dueAt, err := time.Parse(layout, *card.DueDate); err == nil {
+				simpleCard.DueAt = &dueAt
+				break
+			}
+		}
+	}
+
+	return simpleCard
+}
+
+func (s *cardsService) Archive(userID uuid.UUID, cardID uuid.UUID) (*models.Card, error) {
+	now := time.Now()
+	return s.setArchivedAt(userID, cardID, &now)
+}
+
+func (s *cardsService) Unarchive(userID uuid.UUID, cardID uuid.UUID) (*models.Card, error) {
+	return s.setArchivedAt(userID, cardID, nil)
+}
+
+func (s *cardsService) setArchivedAt(userID uuid.UUID, cardID uuid.UUID, archivedAt *time.Time) (*models.Card, error) {
+	card, err := s.getAuthorized(userID, cardID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archiving twice keeps the original archive date.
+	if card.ArchivedAt != nil && archivedAt != nil {
+		return &card, nil
+	}
+
+	if err := s.Repository.SetArchivedAt(card.ID, archivedAt); err != nil {
+		return nil, err
+	}
+
+	card.ArchivedAt = archivedAt
+	return &card, nil
+}
+
+func (s *cardsService) ArchiveDone(userID uuid.UUID, dto ArchiveDoneCardsDTO) (*ArchiveDoneCardsResultDTO, error) {
+	if dto.OlderThanDays <= 0 {
+		return nil, apperrors.Validation("older_than_days must be positive")
+	}
+
+	workspaceID, err := s.Workspaces.Resolve(userID, dto.WorkspaceID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	archived, err := s.Repository.ArchiveDone(&workspaceID, now.AddDate(0, 0, -dto.OlderThanDays), now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArchiveDoneCardsResultDTO{Archived: archived}, nil
+}
+
+// ArchiveDoneBefore archives the done cards of every workspace that were last
+// updated before the given time.
+func (s *cardsService) ArchiveDoneBefore(before time.Time) (int64, error) {
+	return s.Repository.ArchiveDone(nil, before, time.Now())
 }
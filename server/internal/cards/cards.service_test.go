@@ -1,24 +1,56 @@
 package cards
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"cards/internal/apperrors"
+	"cards/internal/authz"
+	"cards/internal/boards"
+	"cards/internal/llm"
 	"cards/internal/models"
+	"cards/internal/storage"
+	"cards/internal/tags"
+	"cards/internal/workspaces"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type fakeCardsRepository struct {
-	findByID     func(id uuid.UUID) (models.Card, error)
-	listByUserID func(userID uuid.UUID) ([]models.Card, error)
-	create       func(card *models.Card) error
-	createMulti  func(cards []models.Card) error
-	update       func(card *models.Card) error
+	findByID      func(id uuid.UUID) (models.Card, error)
+	listByBoardID func(boardID uuid.UUID) ([]models.Card, error)
+	list          func(query CardsQuery) ([]models.Card, error)
+	search        func(query CardsSearchQuery) ([]CardSearchResultDTO, int64, error)
+	total         int64
+	columnCards   map[uuid.UUID][]models.Card
+	create        func(card *models.Card) error
+	createMulti   func(cards []models.Card) error
+	update        func(card *models.Card) error
 
-	createdCard  *models.Card
-	updatedCard  *models.Card
-	createdMulti []models.Card
+	createdCard    *models.Card
+	updatedCard    *models.Card
+	updatedColumns []string
+	createdMulti   []models.Card
+	movedCard      *models.Card
+	rebalanced     []models.Card
+	replacedTags   []models.Tag
+	revisions      []models.CardRevision
+	savedItems     []models.ChecklistItem
+	deletedItem    uuid.UUID
+	llmUsage       []models.LLMUsage
+	trash          map[uuid.UUID]models.Card
+	storageKeys    map[uuid.UUID][]string
+	deletedCard    uuid.UUID
+	restoredCard   *models.Card
+	purged         []uuid.UUID
+	archivedAt     map[uuid.UUID]*time.Time
+	archiveDone    func(workspaceID *uuid.UUID, doneBefore time.Time) int64
 }
 
 func (r *fakeCardsRepository) FindByID(id uuid.UUID) (models.Card, error) {
@@ -28,13 +60,43 @@ func (r *fakeCardsRepository) FindByID(id uuid.UUID) (models.Card, error) {
 	return models.Card{}, errors.New("not implemented")
 }
 
-func (r *fakeCardsRepository) ListByUserID(userID uuid.UUID) ([]models.Card, error) {
-	if r.listByUserID != nil {
-		return r.listByUserID(userID)
+func (r *fakeCardsRepository) Count(query CardsQuery) (int64, error) {
+	return r.total, nil
+}
+
+func (r *fakeCardsRepository) List(query CardsQuery) ([]models.Card, error) {
+	if r.list != nil {
+		return r.list(query)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeCardsRepository) Search(query CardsSearchQuery) ([]CardSearchResultDTO, int64, error) {
+	if r.search != nil {
+		return r.search(query)
+	}
+	return nil, 0, errors.New("not implemented")
+}
+
+func (r *fakeCardsRepository) ListByBoardID(boardID uuid.UUID) ([]models.Card, error) {
+	if r.listByBoardID != nil {
+		return r.listByBoardID(boardID)
 	}
 	return nil, errors.New("not implemented")
 }
 
+func (r *fakeCardsRepository) ListByColumnID(columnID uuid.UUID) ([]models.Card, error) {
+	return append([]models.Card{}, r.columnCards[columnID]...), nil
+}
+
+func (r *fakeCardsRepository) LastRankInColumn(columnID uuid.UUID) (string, error) {
+	cards := r.columnCards[columnID]
+	if len(cards) == 0 {
+		return "", nil
+	}
+	return cards[len(cards)-1].Rank, nil
+}
+
 func (r *fakeCardsRepository) Create(card *models.Card) error {
 	r.createdCard = card
 	if r.create != nil {
@@ -51,43 +113,325 @@ func (r *fakeCardsRepository) CreateMultiple(cards []models.Card) error {
 	return nil
 }
 
-func (r *fakeCardsRepository) Update(card *models.Card) error {
+func (r *fakeCardsRepository) Update(card *models.Card, edit cardEdit) error {
 	r.updatedCard = card
+	r.updatedColumns = edit.Columns
+	if edit.ReplaceTags {
+		r.replacedTags = card.Tags
+	}
+	if edit.Revision != nil {
+		edit.Revision.ID = uuid.New()
+		r.revisions = append([]models.CardRevision{*edit.Revision}, r.revisions...)
+	}
 	if r.update != nil {
 		return r.update(card)
 	}
 	return nil
 }
 
+func (r *fakeCardsRepository) UpdateRanks(cards []models.Card) error {
+	r.rebalanced = cards
+	return nil
+}
+
+func (r *fakeCardsRepository) Move(card *models.Card, edit cardEdit, rebalanced []models.Card) error {
+	r.movedCard = card
+	r.updatedColumns = edit.Columns
+	if edit.Revision != nil {
+		r.revisions = append([]models.CardRevision{*edit.Revision}, r.revisions...)
+	}
+	r.rebalanced = rebalanced
+	return nil
+}
+
+func (r *fakeCardsRepository) ListRevisions(cardID uuid.UUID) ([]models.CardRevision, error) {
+	return r.revisions, nil
+}
+
+func (r *fakeCardsRepository) CreateChecklistItem(item *models.ChecklistItem) error {
+	item.ID = uuid.New()
+	return nil
+}
+
+func (r *fakeCardsRepository) SaveChecklist(items []models.ChecklistItem) error {
+	r.savedItems = items
+	return nil
+}
+
+func (r *fakeCardsRepository) DeleteChecklistItem(id uuid.UUID) error {
+	r.deletedItem = id
+	return nil
+}
+
 func (r *fakeCardsRepository) Delete(id uuid.UUID) error {
+	r.deletedCard = id
+	return nil
+}
+
+func (r *fakeCardsRepository) FindTrashedByID(id uuid.UUID) (models.Card, error) {
+	card, ok := r.trash[id]
+	if !ok {
+		return models.Card{}, errors.New("record not found")
+	}
+	return card, nil
+}
+
+func (r *fakeCardsRepository) ListTrash(workspaceID uuid.UUID) ([]models.Card, error) {
+	var cards []models.Card
+	for _, card := range r.trash {
+		if card.WorkspaceID != nil && *card.WorkspaceID == workspaceID {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
+
+func (r *fakeCardsRepository) ListTrashedBefore(before time.Time, limit int) ([]models.Card, error) {
+	var cards []models.Card
+	for _, card := range r.trash {
+		if card.DeletedAt.Time.Before(before) && len(cards) < limit {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
+
+func (r *fakeCardsRepository) Restore(card *models.Card) error {
+	delete(r.trash, card.ID)
+	r.restoredCard = card
+	return nil
+}
+
+func (r *fakeCardsRepository) Purge(id uuid.UUID) ([]string, error) {
+	delete(r.trash, id)
+	r.purged = append(r.purged, id)
+	return r.storageKeys[id], nil
+}
+
+func (r *fakeCardsRepository) SetArchivedAt(id uuid.UUID, archivedAt *time.Time) error {
+	if r.archivedAt == nil {
+		r.archivedAt = map[uuid.UUID]*time.Time{}
+	}
+	r.archivedAt[id] = archivedAt
+	return nil
+}
+
+func (r *fakeCardsRepository) ArchiveDone(workspaceID *uuid.UUID, doneBefore time.Time, archivedAt time.Time) (int64, error) {
+	if r.archiveDone != nil {
+		return r.archiveDone(workspaceID, doneBefore), nil
+	}
+	return 0, nil
+}
+
+func (r *fakeCardsRepository) RecordLLMUsage(usage *models.LLMUsage) error {
+	r.llmUsage = append(r.llmUsage, *usage)
+	return nil
+}
+
+type fakeBlobStore struct {
+	storage.BlobStore
+	deleted []string
+}
+
+func newFakeBlobStore() *fakeBlobStore {
+	return &fakeBlobStore{}
+}
+
+func (f *fakeBlobStore) Delete(ctx context.Context, key string) error {
+	f.deleted = append(f.deleted, key)
 	return nil
 }
 
+// fakeBoardsService serves a single board. Boards in a workspace are checked
+// against the memberships of workspaces, others belong to their owner.
+type fakeBoardsService struct {
+	boards.BoardsService
+	board      *models.Board
+	workspaces *fakeWorkspacesService
+}
+
+func newFakeBoardsService(userID uuid.UUID) *fakeBoardsService {
+	board := &models.Board{Base: models.Base{ID: uuid.New()}, UserID: userID, IsDefault: true}
+	for i, name := range append([]string{"backlog"}, models.DefaultBoardColumns...) {
+		board.Columns = append(board.Columns, models.Column{Base: models.Base{ID: uuid.New()}, Name: name, Position: i, BoardID: board.ID})
+	}
+	return &fakeBoardsService{board: board}
+}
+
+func (b *fakeBoardsService) GetByID(userID uuid.UUID, boardID uuid.UUID) (*models.Board, error) {
+	return b.Authorize(userID, boardID, authz.Read)
+}
+
+func (b *fakeBoardsService) EnsureDefaultBoard(userID uuid.UUID) (*models.Board, error) {
+	return b.GetByID(userID, b.board.ID)
+}
+
+func (b *fakeBoardsService) GetColumn(userID uuid.UUID, columnID uuid.UUID) (*models.Column, error) {
+	if _, err := b.GetByID(userID, b.board.ID); err != nil {
+		return nil, err
+	}
+	for i := range b.board.Columns {
+		if b.board.Columns[i].ID == columnID {
+			return &b.board.Columns[i], nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (b *fakeBoardsService) Authorize(userID uuid.UUID, boardID uuid.UUID, action authz.Action) (*models.Board, error) {
+	if boardID != b.board.ID {
+		return nil, errors.New("not found")
+	}
+	subject := authz.Subject{UserID: userID}
+	if b.workspaces != nil {
+		subject, _ = b.workspaces.Subject(userID)
+	}
+	if err := authz.Can(subject, action, authz.Board(*b.board)); err != nil {
+		return nil, err
+	}
+	return b.board, nil
+}
+
+func (b *fakeBoardsService) column(name string) models.Column {
+	for _, column := range b.board.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+	return models.Column{}
+}
+
+type fakeWorkspacesService struct {
+	workspaces.WorkspacesService
+	personalID uuid.UUID
+	roles      map[uuid.UUID]map[uuid.UUID]models.Role
+}
+
+func newFakeWorkspacesService() *fakeWorkspacesService {
+	return &fakeWorkspacesService{personalID: uuid.New(), roles: map[uuid.UUID]map[uuid.UUID]models.Role{}}
+}
+
+func (f *fakeWorkspacesService) join(workspaceID uuid.UUID, userID uuid.UUID, role models.Role) {
+	if f.roles[workspaceID] == nil {
+		f.roles[workspaceID] = map[uuid.UUID]models.Role{}
+	}
+	f.roles[workspaceID][userID] = role
+}
+
+func (f *fakeWorkspacesService) EnsurePersonal(userID uuid.UUID) (*models.Workspace, error) {
+	return &models.Workspace{Base: models.Base{ID: f.personalID}, OwnerID: userID, IsPersonal: true}, nil
+}
+
+func (f *fakeWorkspacesService) Subject(userID uuid.UUID) (authz.Subject, error) {
+	subject := authz.Subject{UserID: userID, Roles: map[uuid.UUID]models.Role{}}
+	for workspaceID, roles := range f.roles {
+		if role, ok := roles[userID]; ok {
+			subject.Roles[workspaceID] = role
+		}
+	}
+	return subject, nil
+}
+
+func (f *fakeWorkspacesService) Authorize(userID uuid.UUID, workspaceID uuid.UUID, action authz.Action) (authz.Subject, error) {
+	subject, _ := f.Subject(userID)
+	if err := authz.Can(subject, action, authz.Workspace(workspaceID)); err != nil {
+		return authz.Subject{}, err
+	}
+	return subject, nil
+}
+
+func (f *fakeWorkspacesService) Resolve(userID uuid.UUID, workspaceID string, action authz.Action) (uuid.UUID, error) {
+	if workspaceID == "" {
+		return f.personalID, nil
+	}
+	id, err := uuid.Parse(workspaceID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := f.Authorize(userID, id, action); err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+// fakeTagsService keeps tags per workspace, like the real one.
+type fakeTagsService struct {
+	tags.TagsService
+	tags    []models.Tag
+	created []string
+}
+
+func newFakeTagsService() *fakeTagsService {
+	return &fakeTagsService{}
+}
+
+// with adds tags with the given names to the workspace.
+func (f *fakeTagsService) with(workspaceID uuid.UUID, names ...string) *fakeTagsService {
+	for _, name := range names {
+		f.tags = append(f.tags, models.Tag{Base: models.Base{ID: uuid.New()}, Name: name, WorkspaceID: &workspaceID})
+	}
+	return f
+}
+
+func (f *fakeTagsService) tag(workspaceID uuid.UUID, name string) models.Tag {
+	for _, tag := range f.tags {
+		if *tag.WorkspaceID == workspaceID && tag.Name == name {
+			return tag
+		}
+	}
+	return models.Tag{}
+}
+
+func (f *fakeTagsService) FindByNames(workspaceID uuid.UUID, names []string) ([]models.Tag, error) {
+	var found []models.Tag
+	for _, name := range tags.NormalizeNames(names) {
+		if tag := f.tag(workspaceID, name); tag.ID != uuid.Nil {
+			found = append(found, tag)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeTagsService) Resolve(userID uuid.UUID, workspaceID uuid.UUID, names []string) ([]models.Tag, error) {
+	var resolved []models.Tag
+	for _, name := range tags.NormalizeNames(names) {
+		tag := f.tag(workspaceID, name)
+		if tag.ID == uuid.Nil {
+			tag = models.Tag{Base: models.Base{ID: uuid.New()}, Name: name, UserID: userID, WorkspaceID: &workspaceID}
+			f.tags = append(f.tags, tag)
+			f.created = append(f.created, name)
+		}
+		resolved = append(resolved, tag)
+	}
+	return resolved, nil
+}
+
 func TestCardsService_List(t *testing.T) {
 	userID := uuid.New()
 	expected := []models.Card{{Title: "t1"}, {Title: "t2"}}
 
-	repo := &fakeCardsRepository{listByUserID: func(id uuid.UUID) ([]models.Card, error) {
-		if id != userID {
-			return nil, errors.New("unexpected user id")
+	workspacesSvc := newFakeWorkspacesService()
+	repo := &fakeCardsRepository{list: func(query CardsQuery) ([]models.Card, error) {
+		if query.WorkspaceID != workspacesSvc.personalID {
+			return nil, errors.New("unexpected workspace id")
 		}
 		return expected, nil
 	}}
-	svc := NewCardsService(repo)
+	svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), workspacesSvc, newFakeBlobStore())
 
-	got, err := svc.List(userID)
+	got, err := svc.List(userID, ListCardsQuery{})
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
-	if len(got) != len(expected) {
-		t.Fatalf("expected %d cards, got %d", len(expected), len(got))
+	if len(got.Cards) != len(expected) {
+		t.Fatalf("expected %d cards, got %d", len(expected), len(got.Cards))
 	}
 }
 
 func TestCardsService_Create(t *testing.T) {
 	userID := uuid.New()
 	repo := &fakeCardsRepository{}
-	svc := NewCardsService(repo)
+	svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
 
 	card, err := svc.Create(userID, CreateCardDTO{Title: "T", Content: "C"})
 	if err != nil {
@@ -110,7 +454,7 @@ func TestCardsService_Create(t *testing.T) {
 func TestCardsService_CreateMultiple(t *testing.T) {
 	userID := uuid.New()
 	repo := &fakeCardsRepository{}
-	svc := NewCardsService(repo)
+	svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
 
 	cards, err := svc.CreateMultiple(userID, []CreateCardDTO{{Title: "T1", Content: "C1"}, {Title: "T2", Content: "C2"}})
 	if err != nil {
@@ -137,15 +481,15 @@ func TestCardsService_Update(t *testing.T) {
 	otherUserID := uuid.New()
 	cardID := uuid.New()
 
-	t.Run("returns unauthorized when card is owned by different user", func(t *testing.T) {
+	t.Run("hides cards owned by other users", func(t *testing.T) {
 		repo := &fakeCardsRepository{findByID: func(id uuid.UUID) (models.Card, error) {
 			return models.Card{UserID: otherUserID}, nil
 		}}
-		svc := NewCardsService(repo)
+		svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
 
 		_, err := svc.Update(userID, cardID, UpdateCardDTO{})
-		if err == nil || err.Error() != "unauthorized" {
-			t.Fatalf("expected unauthorized error, got %v", err)
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
 		}
 	})
 
@@ -157,7 +501,7 @@ func TestCardsService_Update(t *testing.T) {
 			}
 			return existing, nil
 		}}
-		svc := NewCardsService(repo)
+		svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
 
 		title := "New"
 		content := "NewC"
@@ -177,3 +521,1326 @@ func TestCardsService_Update(t *testing.T) {
 		}
 	})
 }
+
+func TestCardsService_CreatePlacesCardOnBoard(t *testing.T) {
+	userID := uuid.New()
+	boardsSvc := newFakeBoardsService(userID)
+
+	t.Run("defaults to the undone column of the default board", func(t *testing.T) {
+		repo := &fakeCardsRepository{}
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+		if _, err := svc.Create(userID, CreateCardDTO{Title: "T", Content: "C"}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		undone := boardsSvc.column(string(CardStatusUndone))
+		if repo.createdCard.BoardID == nil || *repo.createdCard.BoardID != boardsSvc.board.ID {
+			t.Fatalf("expected board %s, got %v", boardsSvc.board.ID, repo.createdCard.BoardID)
+		}
+		if repo.createdCard.ColumnID == nil || *repo.createdCard.ColumnID != undone.ID {
+			t.Fatalf("expected column %s, got %v", undone.ID, repo.createdCard.ColumnID)
+		}
+	})
+
+	t.Run("derives status from an explicit column", func(t *testing.T) {
+		repo := &fakeCardsRepository{}
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+		doing := boardsSvc.column(string(CardStatusDoing))
+
+		if _, err := svc.Create(userID, CreateCardDTO{Title: "T", Content: "C", ColumnID: &doing.ID}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.createdCard.Status != string(CardStatusDoing) {
+			t.Fatalf("expected status %q, got %q", CardStatusDoing, repo.createdCard.Status)
+		}
+	})
+
+	t.Run("keeps undone status for custom columns", func(t *testing.T) {
+		repo := &fakeCardsRepository{}
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+		backlog := boardsSvc.column("backlog")
+
+		if _, err := svc.Create(userID, CreateCardDTO{Title: "T", Content: "C", ColumnID: &backlog.ID}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.createdCard.Status != string(CardStatusUndone) {
+			t.Fatalf("expected status %q, got %q", CardStatusUndone, repo.createdCard.Status)
+		}
+	})
+
+	t.Run("rejects a column from another board", func(t *testing.T) {
+		repo := &fakeCardsRepository{}
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+		otherBoardID := uuid.New()
+		doing := boardsSvc.column(string(CardStatusDoing))
+
+		_, err := svc.Create(userID, CreateCardDTO{Title: "T", Content: "C", BoardID: &otherBoardID, ColumnID: &doing.ID})
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if repo.createdCard != nil {
+			t.Fatalf("did not expect Create call")
+		}
+	})
+}
+
+func TestCardsService_UpdateSyncsStatusAndColumn(t *testing.T) {
+	userID := uuid.New()
+	cardID := uuid.New()
+	boardsSvc := newFakeBoardsService(userID)
+	undone := boardsSvc.column(string(CardStatusUndone))
+	existing := func() models.Card {
+		return models.Card{UserID: userID, Status: string(CardStatusUndone), BoardID: &boardsSvc.board.ID, ColumnID: &undone.ID}
+	}
+
+	t.Run("moving to a column updates status", func(t *testing.T) {
+		repo := &fakeCardsRepository{findByID: func(id uuid.UUID) (models.Card, error) { return existing(), nil }}
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+		done := boardsSvc.column(string(CardStatusDone))
+
+		resp, err := svc.Update(userID, cardID, UpdateCardDTO{ColumnID: &done.ID})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if *repo.updatedCard.ColumnID != done.ID || resp.Status != CardStatusDone {
+			t.Fatalf("unexpected updated card: %+v", repo.updatedCard)
+		}
+	})
+
+	t.Run("changing status moves to the matching column", func(t *testing.T) {
+		repo := &fakeCardsRepository{findByID: func(id uuid.UUID) (models.Card, error) { return existing(), nil }}
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+		doing := boardsSvc.column(string(CardStatusDoing))
+		status := CardStatusDoing
+
+		if _, err := svc.Update(userID, cardID, UpdateCardDTO{Status: &status}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if *repo.updatedCard.ColumnID != doing.ID {
+			t.Fatalf("expected column %s, got %s", doing.ID, *repo.updatedCard.ColumnID)
+		}
+	})
+}
+
+func TestCardsService_ListByBoard(t *testing.T) {
+	userID := uuid.New()
+	boardsSvc := newFakeBoardsService(userID)
+	repo := &fakeCardsRepository{listByBoardID: func(boardID uuid.UUID) ([]models.Card, error) {
+		return []models.Card{{Title: "t1"}}, nil
+	}}
+
+	t.Run("lists cards of an owned board", func(t *testing.T) {
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+		got, err := svc.ListByBoard(userID, boardsSvc.board.ID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("expected 1 card, got %d", len(got))
+		}
+	})
+
+	t.Run("rejects boards of other users", func(t *testing.T) {
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+		if _, err := svc.ListByBoard(uuid.New(), boardsSvc.board.ID); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+}
+
+func TestCardsService_CreateAppendsRank(t *testing.T) {
+	userID := uuid.New()
+	boardsSvc := newFakeBoardsService(userID)
+	undone := boardsSvc.column(string(CardStatusUndone))
+	repo := &fakeCardsRepository{columnCards: map[uuid.UUID][]models.Card{
+		undone.ID: {{Rank: "a"}, {Rank: "m"}},
+	}}
+	svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+	if _, err := svc.CreateMultiple(userID, []CreateCardDTO{{Title: "T1", Content: "C1"}, {Title: "T2", Content: "C2"}}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	first, second := repo.createdMulti[0].Rank, repo.createdMulti[1].Rank
+	if !("m" < first && first < second) {
+		t.Fatalf("expected ranks after %q in order, got %q and %q", "m", first, second)
+	}
+}
+
+func TestCardsService_CreateMultipleRebalancesFullColumn(t *testing.T) {
+	userID := uuid.New()
+	boardsSvc := newFakeBoardsService(userID)
+	undone := boardsSvc.column(string(CardStatusUndone))
+	stored := models.Card{Base: models.Base{ID: uuid.New()}, Rank: strings.Repeat("z", maxRankLength-1) + "y"}
+	repo := &fakeCardsRepository{columnCards: map[uuid.UUID][]models.Card{undone.ID: {stored}}}
+	svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+	dtos := []CreateCardDTO{{Title: "T1", Content: "C1"}, {Title: "T2", Content: "C2"}, {Title: "T3", Content: "C3"}}
+	if _, err := svc.CreateMultiple(userID, dtos); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if len(repo.rebalanced) != 1 || repo.rebalanced[0].ID != stored.ID {
+		t.Fatalf("expected the stored card rebalanced, got %+v", repo.rebalanced)
+	}
+	prev := repo.rebalanced[0].Rank
+	for i, card := range repo.createdMulti {
+		if card.Rank <= prev || len(card.Rank) > maxRankLength {
+			t.Fatalf("card %d: expected a valid rank after %q, got %q", i, prev, card.Rank)
+		}
+		prev = card.Rank
+	}
+}
+
+func TestCardsService_Move(t *testing.T) {
+	userID := uuid.New()
+	boardsSvc := newFakeBoardsService(userID)
+	undone := boardsSvc.column(string(CardStatusUndone))
+	done := boardsSvc.column(string(CardStatusDone))
+
+	moving := models.Card{Base: models.Base{ID: uuid.New()}, UserID: userID, Status: string(CardStatusUndone), BoardID: &boardsSvc.board.ID, ColumnID: &undone.ID, Rank: "i"}
+	a := models.Card{Base: models.Base{ID: uuid.New()}, Rank: "b"}
+	b := models.Card{Base: models.Base{ID: uuid.New()}, Rank: "d"}
+	newRepo := func(doneCards ...models.Card) *fakeCardsRepository {
+		return &fakeCardsRepository{
+			findByID: func(id uuid.UUID) (models.Card, error) {
+				return moving, nil
+			},
+			columnCards: map[uuid.UUID][]models.Card{
+				undone.ID: {moving},
+				done.ID:   doneCards,
+			},
+		}
+	}
+
+	t.Run("places card between neighbours in the status column", func(t *testing.T) {
+		repo := newRepo(a, b)
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+		status := CardStatusDone
+
+		card, err := svc.Move(userID, moving.ID, MoveCardDTO{Status: &status, AfterID: &a.ID, BeforeID: &b.ID})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if *card.ColumnID != done.ID || card.Status != string(CardStatusDone) {
+			t.Fatalf("unexpected card placement: %+v", card)
+		}
+		if !(a.Rank < card.Rank && card.Rank < b.Rank) {
+			t.Fatalf("expected rank between %q and %q, got %q", a.Rank, b.Rank, card.Rank)
+		}
+		if repo.rebalanced != nil {
+			t.Fatalf("did not expect a rebalance")
+		}
+		for _, column := range []string{"column_id", "rank", "status"} {
+			if !slices.Contains(repo.updatedColumns, column) {
+				t.Fatalf("expected %s to be written, got %v", column, repo.updatedColumns)
+			}
+		}
+		if slices.Contains(repo.updatedColumns, "title") || slices.Contains(repo.updatedColumns, "archived_at") {
+			t.Fatalf("expected untouched fields not to be written, got %v", repo.updatedColumns)
+		}
+	})
+
+	t.Run("moves to the top of a column", func(t *testing.T) {
+		repo := newRepo(a, b)
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+		card, err := svc.Move(userID, moving.ID, MoveCardDTO{ColumnID: &done.ID, BeforeID: &a.ID})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if card.Rank >= a.Rank {
+			t.Fatalf("expected rank before %q, got %q", a.Rank, card.Rank)
+		}
+	})
+
+	t.Run("rebalances when neighbours leave no room", func(t *testing.T) {
+		legacy := models.Card{Base: models.Base{ID: uuid.New()}, Rank: ""}
+		dense := models.Card{Base: models.Base{ID: uuid.New()}, Rank: ""}
+		repo := newRepo(legacy, dense)
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+		card, err := svc.Move(userID, moving.ID, MoveCardDTO{ColumnID: &done.ID, AfterID: &legacy.ID})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(repo.rebalanced) != 3 {
+			t.Fatalf("expected 3 rebalanced cards, got %d", len(repo.rebalanced))
+		}
+		ids := []uuid.UUID{legacy.ID, moving.ID, dense.ID}
+		for i, rebalanced := range repo.rebalanced {
+			if rebalanced.ID != ids[i] {
+				t.Fatalf("unexpected order after rebalance at %d", i)
+			}
+			if i > 0 && rebalanced.Rank <= repo.rebalanced[i-1].Rank {
+				t.Fatalf("expected increasing ranks, got %q after %q", rebalanced.Rank, repo.rebalanced[i-1].Rank)
+			}
+		}
+		if card.Rank != repo.rebalanced[1].Rank {
+			t.Fatalf("expected moved card rank %q, got %q", repo.rebalanced[1].Rank, card.Rank)
+		}
+	})
+
+	t.Run("rejects neighbours outside the target column", func(t *testing.T) {
+		repo := newRepo(a)
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+		if _, err := svc.Move(userID, moving.ID, MoveCardDTO{ColumnID: &done.ID, AfterID: &b.ID}); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("rejects cards owned by other users", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+		_, err := svc.Move(uuid.New(), moving.ID, MoveCardDTO{})
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestCardsService_ListByTags(t *testing.T) {
+	userID := uuid.New()
+	workspacesSvc := newFakeWorkspacesService()
+	tagsSvc := newFakeTagsService().with(workspacesSvc.personalID, "bug", "ui")
+
+	var gotIDs []uuid.UUID
+	var gotMatchAll bool
+	repo := &fakeCardsRepository{list: func(query CardsQuery) ([]models.Card, error) {
+		gotIDs, gotMatchAll = query.TagIDs, query.MatchAllTags
+		return []models.Card{{Title: "t1"}}, nil
+	}}
+	svc := NewCardsService(repo, newFakeBoardsService(userID), tagsSvc, workspacesSvc, newFakeBlobStore())
+
+	t.Run("filters by any of the known tags", func(t *testing.T) {
+		got, err := svc.List(userID, ListCardsQuery{Tags: "bug, missing"})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(got.Cards) != 1 || len(gotIDs) != 1 || gotIDs[0] != tagsSvc.tag(workspacesSvc.personalID, "bug").ID || gotMatchAll {
+			t.Fatalf("unexpected filter: ids=%v matchAll=%v", gotIDs, gotMatchAll)
+		}
+	})
+
+	t.Run("requires every tag in all mode", func(t *testing.T) {
+		got, err := svc.List(userID, ListCardsQuery{Tags: "bug,ui", TagMatch: TagMatchAll})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(got.Cards) != 1 || len(gotIDs) != 2 || !gotMatchAll {
+			t.Fatalf("unexpected filter: ids=%v matchAll=%v", gotIDs, gotMatchAll)
+		}
+	})
+
+	t.Run("returns nothing when an all-mode tag does not exist", func(t *testing.T) {
+		gotIDs = nil
+		got, err := svc.List(userID, ListCardsQuery{Tags: "bug,missing", TagMatch: TagMatchAll})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(got.Cards) != 0 || gotIDs != nil {
+			t.Fatalf("expected empty result without querying, got %d cards", len(got.Cards))
+		}
+	})
+}
+
+func TestCardsService_Tags(t *testing.T) {
+	userID := uuid.New()
+	cardID := uuid.New()
+	existing := func(id uuid.UUID) (models.Card, error) {
+		return models.Card{Base: models.Base{ID: cardID}, UserID: userID, Status: string(CardStatusUndone)}, nil
+	}
+
+	t.Run("create resolves tag names, creating missing ones", func(t *testing.T) {
+		workspacesSvc := newFakeWorkspacesService()
+		tagsSvc := newFakeTagsService().with(workspacesSvc.personalID, "bug")
+		repo := &fakeCardsRepository{}
+		svc := NewCardsService(repo, newFakeBoardsService(userID), tagsSvc, workspacesSvc, newFakeBlobStore())
+
+		card, err := svc.Create(userID, CreateCardDTO{Title: "T", Content: "C", Tags: []string{"bug", "new", "bug"}})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(card.Tags) != 2 || card.Tags[0].Name != "bug" || card.Tags[1].Name != "new" {
+			t.Fatalf("unexpected tags: %+v", card.Tags)
+		}
+		if len(tagsSvc.created) != 1 || tagsSvc.created[0] != "new" {
+			t.Fatalf("expected only %q to be created, got %v", "new", tagsSvc.created)
+		}
+	})
+
+	t.Run("update replaces tags when provided", func(t *testing.T) {
+		repo := &fakeCardsRepository{findByID: existing}
+		svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+		names := []string{"a", "b"}
+		resp, err := svc.Update(userID, cardID, UpdateCardDTO{Tags: &names})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(repo.replacedTags) != 2 || len(resp.Tags) != 2 {
+			t.Fatalf("expected tags to be replaced, got %+v", repo.replacedTags)
+		}
+	})
+
+	t.Run("update leaves tags alone when omitted", func(t *testing.T) {
+		repo := &fakeCardsRepository{findByID: existing}
+		svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+		if _, err := svc.Update(userID, cardID, UpdateCardDTO{}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.replacedTags != nil {
+			t.Fatalf("did not expect ReplaceTags call")
+		}
+	})
+
+	t.Run("detach only removes existing tags and records a revision", func(t *testing.T) {
+		workspacesSvc := newFakeWorkspacesService()
+		tagsSvc := newFakeTagsService().with(workspacesSvc.personalID, "bug", "keep")
+		repo := &fakeCardsRepository{findByID: func(id uuid.UUID) (models.Card, error) {
+			card, _ := existing(id)
+			card.Tags = []models.Tag{tagsSvc.tag(workspacesSvc.personalID, "bug"), tagsSvc.tag(workspacesSvc.personalID, "keep")}
+			return card, nil
+		}}
+		svc := NewCardsService(repo, newFakeBoardsService(userID), tagsSvc, workspacesSvc, newFakeBlobStore())
+
+		if _, err := svc.DetachTags(userID, cardID, CardTagsDTO{Tags: []string{"bug", "missing"}}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(repo.replacedTags) != 1 || repo.replacedTags[0].Name != "keep" {
+			t.Fatalf("unexpected remaining tags: %+v", repo.replacedTags)
+		}
+		if len(repo.revisions) != 1 || string(repo.revisions[0].Changes[revisionFieldTags].From) != `["bug","keep"]` {
+			t.Fatalf("expected the tag change to be recorded, got %+v", repo.revisions)
+		}
+	})
+
+	t.Run("attach skips the update when nothing changes", func(t *testing.T) {
+		workspacesSvc := newFakeWorkspacesService()
+		tagsSvc := newFakeTagsService().with(workspacesSvc.personalID, "bug")
+		repo := &fakeCardsRepository{findByID: func(id uuid.UUID) (models.Card, error) {
+			card, _ := existing(id)
+			card.Tags = []models.Tag{tagsSvc.tag(workspacesSvc.personalID, "bug")}
+			return card, nil
+		}}
+		svc := NewCardsService(repo, newFakeBoardsService(userID), tagsSvc, workspacesSvc, newFakeBlobStore())
+
+		if _, err := svc.AttachTags(userID, cardID, CardTagsDTO{Tags: []string{"bug"}}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.updatedCard != nil {
+			t.Fatalf("did not expect an update for tags already attached")
+		}
+	})
+
+	t.Run("attach rejects cards owned by other users", func(t *testing.T) {
+		repo := &fakeCardsRepository{findByID: existing}
+		svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+		_, err := svc.AttachTags(uuid.New(), cardID, CardTagsDTO{Tags: []string{"bug"}})
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+		if repo.updatedCard != nil {
+			t.Fatalf("did not expect an update")
+		}
+	})
+}
+
+func TestCardsService_WorkspaceTags(t *testing.T) {
+	ownerID, memberID := uuid.New(), uuid.New()
+	workspaceID := uuid.New()
+
+	workspacesSvc := newFakeWorkspacesService()
+	workspacesSvc.join(workspaceID, ownerID, models.RoleOwner)
+	workspacesSvc.join(workspaceID, memberID, models.RoleMember)
+
+	boardsSvc := newFakeBoardsService(ownerID)
+	boardsSvc.board.WorkspaceID = &workspaceID
+	boardsSvc.workspaces = workspacesSvc
+	column := boardsSvc.column("undone")
+
+	// The owner's "bug" tag is the only one; the member never created theirs.
+	tagsSvc := newFakeTagsService()
+	bug, _ := tagsSvc.Resolve(ownerID, workspaceID, []string{"bug"})
+	card := models.Card{
+		Base:        models.Base{ID: uuid.New()},
+		Status:      string(CardStatusUndone),
+		UserID:      ownerID,
+		WorkspaceID: &workspaceID,
+		BoardID:     &boardsSvc.board.ID,
+		ColumnID:    &column.ID,
+		Tags:        bug,
+	}
+
+	t.Run("members filter by tags other members created", func(t *testing.T) {
+		var gotIDs []uuid.UUID
+		repo := &fakeCardsRepository{list: func(query CardsQuery) ([]models.Card, error) {
+			gotIDs = query.TagIDs
+			return []models.Card{card}, nil
+		}}
+		svc := NewCardsService(repo, boardsSvc, tagsSvc, workspacesSvc, newFakeBlobStore())
+
+		got, err := svc.List(memberID, ListCardsQuery{WorkspaceID: workspaceID.String(), Tags: "bug"})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(got.Cards) != 1 || len(gotIDs) != 1 || gotIDs[0] != bug[0].ID {
+			t.Fatalf("expected the owner's tag to be used, got %v", gotIDs)
+		}
+	})
+
+	t.Run("members detach tags other members created", func(t *testing.T) {
+		repo := &fakeCardsRepository{findByID: func(id uuid.UUID) (models.Card, error) { return card, nil }}
+		svc := NewCardsService(repo, boardsSvc, tagsSvc, workspacesSvc, newFakeBlobStore())
+
+		got, err := svc.DetachTags(memberID, card.ID, CardTagsDTO{Tags: []string{"bug"}})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(got.Tags) != 0 || repo.replacedTags == nil || len(repo.replacedTags) != 0 {
+			t.Fatalf("expected the tag to be detached, got %+v", repo.replacedTags)
+		}
+	})
+
+	t.Run("members attach the existing tag instead of a copy", func(t *testing.T) {
+		untagged := card
+		untagged.Tags = nil
+		repo := &fakeCardsRepository{findByID: func(id uuid.UUID) (models.Card, error) { return untagged, nil }}
+		svc := NewCardsService(repo, boardsSvc, tagsSvc, workspacesSvc, newFakeBlobStore())
+
+		if _, err := svc.AttachTags(memberID, card.ID, CardTagsDTO{Tags: []string{"bug"}}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(repo.replacedTags) != 1 || repo.replacedTags[0].ID != bug[0].ID || len(tagsSvc.created) != 1 {
+			t.Fatalf("expected the owner's tag to be attached, got %+v", repo.replacedTags)
+		}
+	})
+}
+
+func TestCardsService_ListDueQueries(t *testing.T) {
+	userID := uuid.New()
+
+	var got CardsQuery
+	repo := &fakeCardsRepository{list: func(query CardsQuery) ([]models.Card, error) {
+		got = query
+		return nil, nil
+	}}
+	svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+	t.Run("overdue filters on the current time", func(t *testing.T) {
+		before := time.Now()
+		if _, err := svc.List(userID, ListCardsQuery{Overdue: true}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got.OverdueAt == nil || got.OverdueAt.Before(before) {
+			t.Fatalf("expected overdue cutoff at now, got %v", got.OverdueAt)
+		}
+	})
+
+	t.Run("passes due date range through", func(t *testing.T) {
+		after := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		before := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+		if _, err := svc.List(userID, ListCardsQuery{DueAfter: &after, DueBefore: &before}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got.DueAfter != &after || got.DueBefore != &before || got.OverdueAt != nil {
+			t.Fatalf("unexpected query: %+v", got)
+		}
+	})
+
+	t.Run("sorts priority descending and due date ascending by default", func(t *testing.T) {
+		if _, err := svc.List(userID, ListCardsQuery{Sort: CardSortPriority}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got.Sort != CardSortPriority || !got.Descending {
+			t.Fatalf("unexpected priority sort: %+v", got)
+		}
+
+		if _, err := svc.List(userID, ListCardsQuery{Sort: CardSortDueDate}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got.Sort != CardSortDueDate || got.Descending {
+			t.Fatalf("unexpected due date sort: %+v", got)
+		}
+
+		if _, err := svc.List(userID, ListCardsQuery{Sort: CardSortPriority, Order: SortOrderAsc}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got.Descending {
+			t.Fatalf("expected explicit ascending order to win")
+		}
+	})
+}
+
+func TestCardsService_DueDateAndPriority(t *testing.T) {
+	userID := uuid.New()
+	cardID := uuid.New()
+	dueAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("create stores due date and priority", func(t *testing.T) {
+		repo := &fakeCardsRepository{}
+		svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+		priority := models.PriorityHigh
+
+		if _, err := svc.Create(userID, CreateCardDTO{Title: "T", Content: "C", DueAt: &dueAt, Priority: &priority}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.createdCard.DueAt != &dueAt || repo.createdCard.Priority != models.PriorityHigh {
+			t.Fatalf("unexpected card: %+v", repo.createdCard)
+		}
+	})
+
+	t.Run("update can clear the due date", func(t *testing.T) {
+		repo := &fakeCardsRepository{findByID: func(id uuid.UUID) (models.Card, error) {
+			return models.Card{UserID: userID, Status: string(CardStatusUndone), DueAt: &dueAt, Priority: models.PriorityLow}, nil
+		}}
+		svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+		resp, err := svc.Update(userID, cardID, UpdateCardDTO{ClearDueAt: true})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.updatedCard.DueAt != nil || resp.DueAt != nil {
+			t.Fatalf("expected due date to be cleared")
+		}
+		if resp.Priority != models.PriorityLow {
+			t.Fatalf("expected priority to be kept, got %v", resp.Priority)
+		}
+	})
+}
+
+func TestFromLLMCard(t *testing.T) {
+	date := "2026-03-01"
+	card := fromLLMCard(llm.Card{Title: "T", Content: "C", DueDate: &date, Priority: "urgent"})
+	if card.Priority != models.PriorityUrgent {
+		t.Fatalf("expected urgent priority, got %v", card.Priority)
+	}
+	if card.DueAt == nil || !card.DueAt.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected due date: %v", card.DueAt)
+	}
+
+	invalid := "next week"
+	card = fromLLMCard(llm.Card{Title: "T", Content: "C", DueDate: &invalid, Priority: "whenever"})
+	if card.DueAt != nil || card.Priority != models.PriorityNone || card.Status != CardStatusUndone {
+		t.Fatalf("expected unparseable fields to be dropped, got %+v", card)
+	}
+
+	card = fromLLMCard(llm.Card{Title: "T", Content: "C", Checklist: []string{"draft", "", "review"}})
+	if len(card.Checklist) != 2 || card.ChecklistProgress.Total != 2 {
+		t.Fatalf("unexpected checklist: %+v", card)
+	}
+}
+
+func TestCardsService_ListPagination(t *testing.T) {
+	userID := uuid.New()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var stored []models.Card
+	for i := 0; i < 5; i++ {
+		stored = append(stored, models.Card{Base: models.Base{ID: uuid.New(), CreatedAt: base.Add(time.Duration(i) * time.Hour)}, Rank: string(rune('a' + i))})
+	}
+
+	var got CardsQuery
+	repo := &fakeCardsRepository{total: int64(len(stored)), list: func(query CardsQuery) ([]models.Card, error) {
+		got = query
+		start := 0
+		if query.After != nil {
+			for i, card := range stored {
+				if card.ID == query.After.ID {
+					start = i + 1
+				}
+			}
+		}
+		end := start + query.Limit
+		if end > len(stored) {
+			end = len(stored)
+		}
+		return stored[start:end], nil
+	}}
+	svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+	page, err := svc.List(userID, ListCardsQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(page.Cards) != 2 || page.Total != 5 || page.NextCursor == nil {
+		t.Fatalf("unexpected first page: %d cards, total %d, cursor %v", len(page.Cards), page.Total, page.NextCursor)
+	}
+
+	var seen []uuid.UUID
+	for page.NextCursor != nil {
+		for _, card := range page.Cards {
+			seen = append(seen, card.ID)
+		}
+		if page, err = svc.List(userID, ListCardsQuery{Limit: 2, Cursor: *page.NextCursor}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got.After == nil || got.After.Sort != CardSortRank {
+			t.Fatalf("expected rank cursor, got %+v", got.After)
+		}
+	}
+	for _, card := range page.Cards {
+		seen = append(seen, card.ID)
+	}
+	if len(seen) != len(stored) {
+		t.Fatalf("expected to page through %d cards, saw %d", len(stored), len(seen))
+	}
+	for i := range stored {
+		if seen[i] != stored[i].ID {
+			t.Fatalf("unexpected card at %d", i)
+		}
+	}
+}
+
+func TestCardsService_ListValidation(t *testing.T) {
+	userID := uuid.New()
+	repo := &fakeCardsRepository{list: func(query CardsQuery) ([]models.Card, error) { return nil, nil }}
+	svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+	t.Run("rejects malformed cursors", func(t *testing.T) {
+		if _, err := svc.List(userID, ListCardsQuery{Cursor: "not a cursor"}); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("rejects cursors from another sort", func(t *testing.T) {
+		cursor := newCardsCursor(models.Card{Base: models.Base{ID: uuid.New()}}, CardSortCreatedAt, true).encode()
+		if _, err := svc.List(userID, ListCardsQuery{Cursor: cursor, Sort: CardSortPriority}); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("rejects unknown statuses", func(t *testing.T) {
+		if _, err := svc.List(userID, ListCardsQuery{Status: "doing,later"}); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("passes filters through", func(t *testing.T) {
+		var got CardsQuery
+		repo.list = func(query CardsQuery) ([]models.Card, error) {
+			got = query
+			return nil, nil
+		}
+		created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		page, err := svc.List(userID, ListCardsQuery{Status: "doing, done", Q: " report ", CreatedAfter: &created, Limit: 500})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(got.Statuses) != 2 || got.Text != "report" || got.CreatedAfter != &created {
+			t.Fatalf("unexpected query: %+v", got)
+		}
+		if got.Limit != maxCardsPageSize+1 {
+			t.Fatalf("expected limit to be capped, got %d", got.Limit)
+		}
+		if page.Cards == nil || page.NextCursor != nil {
+			t.Fatalf("expected empty last page, got %+v", page)
+		}
+	})
+}
+
+func TestCardsService_Search(t *testing.T) {
+	userID := uuid.New()
+	var results []CardSearchResultDTO
+	for i := 0; i < 5; i++ {
+		results = append(results, CardSearchResultDTO{Card: CardResponseDTO{ID: uuid.New()}, Rank: float64(5 - i)})
+	}
+
+	var got []CardsSearchQuery
+	repo := &fakeCardsRepository{search: func(query CardsSearchQuery) ([]CardSearchResultDTO, int64, error) {
+		got = append(got, query)
+		end := query.Offset + query.Limit
+		if end > len(results) {
+			end = len(results)
+		}
+		return results[query.Offset:end], int64(len(results)), nil
+	}}
+	workspacesSvc := newFakeWorkspacesService()
+	svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), workspacesSvc, newFakeBlobStore())
+
+	t.Run("requires a query", func(t *testing.T) {
+		if _, err := svc.Search(userID, SearchCardsQuery{Q: "   "}); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("rejects malformed cursors", func(t *testing.T) {
+		if _, err := svc.Search(userID, SearchCardsQuery{Q: "report", Cursor: "not a cursor"}); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("pages through results in rank order", func(t *testing.T) {
+		got = nil
+		page, err := svc.Search(userID, SearchCardsQuery{Q: " quarterly report ", Limit: 2})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got[0].WorkspaceID != workspacesSvc.personalID || got[0].Text != "quarterly report" {
+			t.Fatalf("unexpected query: %+v", got[0])
+		}
+
+		var seen []uuid.UUID
+		for {
+			if page.Total != int64(len(results)) {
+				t.Fatalf("expected total %d, got %d", len(results), page.Total)
+			}
+			for _, result := range page.Results {
+				seen = append(seen, result.Card.ID)
+			}
+			if page.NextCursor == nil {
+				break
+			}
+			if page, err = svc.Search(userID, SearchCardsQuery{Q: "quarterly report", Limit: 2, Cursor: *page.NextCursor}); err != nil {
+				t.Fatalf("expected nil error, got %v", err)
+			}
+		}
+		if len(seen) != len(results) || len(got) != 3 {
+			t.Fatalf("expected %d results in 3 pages, saw %d in %d", len(results), len(seen), len(got))
+		}
+		for i := range results {
+			if seen[i] != results[i].Card.ID {
+				t.Fatalf("unexpected result at %d", i)
+			}
+		}
+	})
+}
+
+func TestRenderHighlight(t *testing.T) {
+	headline := `<img src=x onerror="alert(1)"> ` + highlightStart + "report" + highlightStop + " & notes"
+	want := `&lt;img src=x onerror=&#34;alert(1)&#34;&gt; <mark>report</mark> &amp; notes`
+	if got := renderHighlight(headline); got != want {
+		t.Fatalf("renderHighlight = %q, want %q", got, want)
+	}
+}
+
+func checklistCard(userID uuid.UUID, texts ...string) models.Card {
+	card := models.Card{Base: models.Base{ID: uuid.New()}, UserID: userID}
+	for i, text := range texts {
+		card.Checklist = append(card.Checklist, models.ChecklistItem{Base: models.Base{ID: uuid.New()}, Text: text, Position: i, CardID: card.ID})
+	}
+	return card
+}
+
+func checklistTexts(items []models.ChecklistItem) []string {
+	var texts []string
+	for i, item := range items {
+		if item.Position != i {
+			return nil
+		}
+		texts = append(texts, item.Text)
+	}
+	return texts
+}
+
+func TestCardsService_Checklist(t *testing.T) {
+	userID := uuid.New()
+
+	newService := func(card models.Card) (CardsService, *fakeCardsRepository) {
+		repo := &fakeCardsRepository{findByID: func(id uuid.UUID) (models.Card, error) {
+			card.Checklist = append([]models.ChecklistItem{}, card.Checklist...)
+			return card, nil
+		}}
+		return NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore()), repo
+	}
+
+	t.Run("adds an item at the requested position", func(t *testing.T) {
+		svc, repo := newService(checklistCard(userID, "a", "b"))
+
+		position := 0
+		got, err := svc.AddChecklistItem(userID, uuid.New(), CreateChecklistItemDTO{Text: "new", Position: &position})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if texts := checklistTexts(repo.savedItems); len(texts) != 3 || texts[0] != "new" {
+			t.Fatalf("unexpected checklist order: %v", texts)
+		}
+		if got.ChecklistProgress != (models.ChecklistProgress{Done: 0, Total: 3}) {
+			t.Fatalf("unexpected progress: %+v", got.ChecklistProgress)
+		}
+	})
+
+	t.Run("toggles an item and reports progress", func(t *testing.T) {
+		card := checklistCard(userID, "a", "b", "c")
+		card.Checklist[0].Done = true
+		svc, repo := newService(card)
+
+		got, err := svc.ToggleChecklistItem(userID, card.ID, card.Checklist[1].ID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(repo.savedItems) != 1 || !repo.savedItems[0].Done {
+			t.Fatalf("expected only the toggled item to be saved, got %+v", repo.savedItems)
+		}
+		if got.ChecklistProgress != (models.ChecklistProgress{Done: 2, Total: 3}) {
+			t.Fatalf("unexpected progress: %+v", got.ChecklistProgress)
+		}
+	})
+
+	t.Run("reorders items", func(t *testing.T) {
+		card := checklistCard(userID, "a", "b", "c")
+		svc, repo := newService(card)
+
+		position := 9
+		if _, err := svc.UpdateChecklistItem(userID, card.ID, card.Checklist[0].ID, UpdateChecklistItemDTO{Position: &position}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if texts := checklistTexts(repo.savedItems); len(texts) != 3 || texts[0] != "b" || texts[2] != "a" {
+			t.Fatalf("unexpected checklist order: %v", texts)
+		}
+	})
+
+	t.Run("deletes an item and closes the gap", func(t *testing.T) {
+		card := checklistCard(userID, "a", "b", "c")
+		svc, repo := newService(card)
+
+		got, err := svc.DeleteChecklistItem(userID, card.ID, card.Checklist[1].ID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.deletedItem != card.Checklist[1].ID {
+			t.Fatalf("expected DeleteChecklistItem called with %s", card.Checklist[1].ID)
+		}
+		if texts := checklistTexts(got.Checklist); len(texts) != 2 || texts[1] != "c" {
+			t.Fatalf("unexpected checklist order: %v", texts)
+		}
+	})
+
+	t.Run("rejects other users and unknown items", func(t *testing.T) {
+		card := checklistCard(userID, "a")
+		svc, _ := newService(card)
+
+		if _, err := svc.ToggleChecklistItem(uuid.New(), card.ID, card.Checklist[0].ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+		if _, err := svc.ToggleChecklistItem(userID, card.ID, uuid.New()); err == nil {
+			t.Fatalf("expected error for unknown item")
+		}
+	})
+}
+
+func TestCardsService_CreateWithChecklist(t *testing.T) {
+	userID := uuid.New()
+	repo := &fakeCardsRepository{}
+	svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+	card, err := svc.Create(userID, CreateCardDTO{Title: "t", Content: "c", Checklist: []string{"one", " ", "two"}})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if texts := checklistTexts(card.Checklist); len(texts) != 2 || texts[1] != "two" {
+		t.Fatalf("unexpected checklist: %v", texts)
+	}
+	if card.ChecklistProgress.Total != 2 {
+		t.Fatalf("unexpected progress: %+v", card.ChecklistProgress)
+	}
+}
+
+func rawJSON(value any) json.RawMessage {
+	data, _ := json.Marshal(value)
+	return data
+}
+
+func TestCardsService_UpdateRecordsRevision(t *testing.T) {
+	userID := uuid.New()
+	card := models.Card{Base: models.Base{ID: uuid.New()}, UserID: userID, Title: "old", Content: "same", Status: string(CardStatusUndone)}
+	repo := &fakeCardsRepository{findByID: func(id uuid.UUID) (models.Card, error) { return card, nil }}
+	svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore())
+
+	title, content := "new", "same"
+	if _, err := svc.Update(userID, card.ID, UpdateCardDTO{Title: &title, Content: &content}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(repo.revisions) != 1 {
+		t.Fatalf("expected one revision, got %d", len(repo.revisions))
+	}
+	revision := repo.revisions[0]
+	if revision.UserID != userID || revision.CardID != card.ID || len(revision.Changes) != 1 {
+		t.Fatalf("unexpected revision: %+v", revision)
+	}
+	if change := revision.Changes[revisionFieldTitle]; string(change.From) != `"old"` || string(change.To) != `"new"` {
+		t.Fatalf("unexpected title change: %s -> %s", change.From, change.To)
+	}
+	if !slices.Equal(repo.updatedColumns, []string{"title", "updated_at"}) {
+		t.Fatalf("expected only the title to be written, got %v", repo.updatedColumns)
+	}
+
+	if _, err := svc.Update(userID, card.ID, UpdateCardDTO{Content: &content}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(repo.revisions) != 1 || len(repo.updatedColumns) != 0 {
+		t.Fatalf("did not expect a revision or any write for a no-op update")
+	}
+}
+
+func TestCardsService_RestoreRevision(t *testing.T) {
+	userID := uuid.New()
+	card := models.Card{Base: models.Base{ID: uuid.New()}, UserID: userID, Title: "C", Content: "body", Status: string(CardStatusUndone), Priority: models.PriorityHigh}
+
+	older := models.CardRevision{Base: models.Base{ID: uuid.New()}, CardID: card.ID, Changes: models.RevisionChanges{
+		revisionFieldTitle:    {From: rawJSON("A"), To: rawJSON("B")},
+		revisionFieldPriority: {From: rawJSON(models.PriorityNone), To: rawJSON(models.PriorityHigh)},
+	}}
+	newer := models.CardRevision{Base: models.Base{ID: uuid.New()}, CardID: card.ID, Changes: models.RevisionChanges{
+		revisionFieldTitle: {From: rawJSON("B"), To: rawJSON("C")},
+	}}
+
+	newService := func() (CardsService, *fakeCardsRepository) {
+		repo := &fakeCardsRepository{
+			findByID:  func(id uuid.UUID) (models.Card, error) { return card, nil },
+			revisions: []models.CardRevision{newer, older},
+		}
+		return NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore()), repo
+	}
+
+	t.Run("undoes only the latest revision", func(t *testing.T) {
+		svc, repo := newService()
+
+		got, err := svc.RestoreRevision(userID, card.ID, newer.ID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got.Title != "B" || got.Priority != models.PriorityHigh {
+			t.Fatalf("unexpected card: %+v", got)
+		}
+		restore := repo.revisions[0]
+		if restore.RestoredFromID == nil || *restore.RestoredFromID != newer.ID {
+			t.Fatalf("expected restore to be recorded, got %+v", restore)
+		}
+	})
+
+	t.Run("undoes every change since an older revision", func(t *testing.T) {
+		svc, _ := newService()
+
+		got, err := svc.RestoreRevision(userID, card.ID, older.ID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got.Title != "A" || got.Priority != models.PriorityNone || got.Content != "body" {
+			t.Fatalf("unexpected card: %+v", got)
+		}
+	})
+
+	t.Run("restores the tags of the card", func(t *testing.T) {
+		tagged := models.CardRevision{Base: models.Base{ID: uuid.New()}, CardID: card.ID, Changes: models.RevisionChanges{
+			revisionFieldTags: {From: rawJSON([]string{"bug"}), To: rawJSON([]string{"bug", "feature"})},
+		}}
+		repo := &fakeCardsRepository{
+			findByID:  func(id uuid.UUID) (models.Card, error) { return card, nil },
+			revisions: []models.CardRevision{tagged},
+		}
+		workspacesSvc := newFakeWorkspacesService()
+		tagsSvc := newFakeTagsService().with(workspacesSvc.personalID, "bug", "feature")
+		svc := NewCardsService(repo, newFakeBoardsService(userID), tagsSvc, workspacesSvc, newFakeBlobStore())
+
+		if _, err := svc.RestoreRevision(userID, card.ID, tagged.ID); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(repo.replacedTags) != 1 || repo.replacedTags[0].Name != "bug" {
+			t.Fatalf("expected the earlier tags to be restored, got %+v", repo.replacedTags)
+		}
+	})
+
+	t.Run("rejects unknown revisions and other users", func(t *testing.T) {
+		svc, _ := newService()
+
+		if _, err := svc.RestoreRevision(userID, card.ID, uuid.New()); err == nil {
+			t.Fatalf("expected error for unknown revision")
+		}
+		if _, err := svc.RestoreRevision(uuid.New(), card.ID, newer.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestCardsCursor_RoundTrip(t *testing.T) {
+	dueAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	card := models.Card{Base: models.Base{ID: uuid.New(), CreatedAt: time.Now().UTC()}, Rank: "i", Priority: models.PriorityHigh, DueAt: &dueAt}
+
+	decoded, err := decodeCardsCursor(newCardsCursor(card, CardSortDueDate, false).encode())
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if decoded.ID != card.ID || !decoded.Time.Equal(dueAt) || !decoded.CreatedAt.Equal(card.CreatedAt) || decoded.Priority != int16(models.PriorityHigh) {
+		t.Fatalf("unexpected cursor: %+v", decoded)
+	}
+
+	card.DueAt = nil
+	if cursor := newCardsCursor(card, CardSortDueDate, true); !cursor.Time.Equal(dueDateFirst) {
+		t.Fatalf("expected cards without due date to sort last when descending, got %v", cursor.Time)
+	}
+}
+
+func TestCardsService_Trash(t *testing.T) {
+	userID := uuid.New()
+	deletedAt := gorm.DeletedAt{Time: time.Now().Add(-48 * time.Hour), Valid: true}
+
+	newService := func() (CardsService, *fakeCardsRepository, *fakeBoardsService, *fakeBlobStore) {
+		boardsSvc := newFakeBoardsService(userID)
+		doing := boardsSvc.column("doing")
+		trashed := models.Card{Base: models.Base{ID: uuid.New()}, Title: "old", Status: string(CardStatusDoing), UserID: userID, BoardID: &doing.BoardID, ColumnID: &doing.ID, DeletedAt: deletedAt}
+		repo := &fakeCardsRepository{
+			trash:       map[uuid.UUID]models.Card{trashed.ID: trashed},
+			storageKeys: map[uuid.UUID][]string{trashed.ID: {"cards/" + trashed.ID.String() + "/a"}},
+		}
+		blobs := newFakeBlobStore()
+		return NewCardsService(repo, boardsSvc, newFakeTagsService(), newFakeWorkspacesService(), blobs), repo, boardsSvc, blobs
+	}
+	trashedID := func(repo *fakeCardsRepository) uuid.UUID {
+		for id := range repo.trash {
+			return id
+		}
+		return uuid.Nil
+	}
+
+	t.Run("delete moves the card to the trash", func(t *testing.T) {
+		svc, repo, _, _ := newService()
+		card := models.Card{Base: models.Base{ID: uuid.New()}, UserID: userID}
+		repo.findByID = func(id uuid.UUID) (models.Card, error) { return card, nil }
+
+		if _, err := svc.Delete(userID, card.ID); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.deletedCard != card.ID || len(repo.purged) != 0 {
+			t.Fatalf("expected a soft delete, got deleted %v purged %v", repo.deletedCard, repo.purged)
+		}
+	})
+
+	t.Run("restore keeps the original column", func(t *testing.T) {
+		svc, repo, boardsSvc, _ := newService()
+
+		got, err := svc.Restore(userID, trashedID(repo))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if *got.ColumnID != boardsSvc.column("doing").ID || got.Status != string(CardStatusDoing) || got.Rank == "" {
+			t.Fatalf("unexpected restored card: %+v", got)
+		}
+		if repo.restoredCard == nil {
+			t.Fatalf("expected the card to be restored")
+		}
+	})
+
+	t.Run("restore falls back to the default board when the column is gone", func(t *testing.T) {
+		svc, repo, boardsSvc, _ := newService()
+		id := trashedID(repo)
+		card := repo.trash[id]
+		missing := uuid.New()
+		card.ColumnID = &missing
+		repo.trash[id] = card
+
+		got, err := svc.Restore(userID, id)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if *got.BoardID != boardsSvc.board.ID || *got.ColumnID == missing {
+			t.Fatalf("expected card in the default board, got %+v", got)
+		}
+	})
+
+	t.Run("purge removes the card and its blobs", func(t *testing.T) {
+		svc, repo, _, blobs := newService()
+		id := trashedID(repo)
+
+		if _, err := svc.Purge(userID, id); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(repo.purged) != 1 || repo.purged[0] != id || len(blobs.deleted) != 1 {
+			t.Fatalf("unexpected purge: purged %v, blobs %v", repo.purged, blobs.deleted)
+		}
+	})
+
+	t.Run("rejects cards outside the trash and other users", func(t *testing.T) {
+		svc, repo, _, _ := newService()
+
+		if _, err := svc.Purge(userID, uuid.New()); err == nil {
+			t.Fatalf("expected error for a card that is not trashed")
+		}
+		if _, err := svc.Restore(uuid.New(), trashedID(repo)); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("purges only cards past the retention period", func(t *testing.T) {
+		svc, repo, _, _ := newService()
+		recent := models.Card{Base: models.Base{ID: uuid.New()}, UserID: userID, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}
+		repo.trash[recent.ID] = recent
+
+		purged := NewTrashPurger(svc, 24*time.Hour).PurgeOnce(time.Now())
+		if purged != 1 || len(repo.trash) != 1 {
+			t.Fatalf("expected one purged card, got %d with %d left", purged, len(repo.trash))
+		}
+		if _, ok := repo.trash[recent.ID]; !ok {
+			t.Fatalf("expected the recently trashed card to stay")
+		}
+	})
+}
+
+func TestCardsService_Archive(t *testing.T) {
+	userID := uuid.New()
+	card := models.Card{Base: models.Base{ID: uuid.New()}, UserID: userID, Status: string(CardStatusDone)}
+
+	newService := func(card models.Card) (CardsService, *fakeCardsRepository) {
+		repo := &fakeCardsRepository{findByID: func(id uuid.UUID) (models.Card, error) { return card, nil }}
+		return NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), newFakeWorkspacesService(), newFakeBlobStore()), repo
+	}
+
+	t.Run("archives and unarchives without touching the status", func(t *testing.T) {
+		svc, repo := newService(card)
+
+		got, err := svc.Archive(userID, card.ID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got.ArchivedAt == nil || repo.archivedAt[card.ID] == nil || got.Status != string(CardStatusDone) {
+			t.Fatalf("unexpected archived card: %+v", got)
+		}
+
+		got, err = svc.Unarchive(userID, card.ID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got.ArchivedAt != nil || repo.archivedAt[card.ID] != nil {
+			t.Fatalf("expected card to be unarchived, got %+v", got)
+		}
+	})
+
+	t.Run("keeps the original archive date", func(t *testing.T) {
+		archivedAt := time.Now().Add(-time.Hour)
+		archived := card
+		archived.ArchivedAt = &archivedAt
+		svc, repo := newService(archived)
+
+		got, err := svc.Archive(userID, card.ID)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !got.ArchivedAt.Equal(archivedAt) || len(repo.archivedAt) != 0 {
+			t.Fatalf("expected archive date to be kept, got %v", got.ArchivedAt)
+		}
+	})
+
+	t.Run("rejects other users", func(t *testing.T) {
+		svc, _ := newService(card)
+
+		if _, err := svc.Archive(uuid.New(), card.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("bulk archives done cards of the workspace", func(t *testing.T) {
+		repo := &fakeCardsRepository{}
+		workspacesSvc := newFakeWorkspacesService()
+		svc := NewCardsService(repo, newFakeBoardsService(userID), newFakeTagsService(), workspacesSvc, newFakeBlobStore())
+		var gotWorkspace *uuid.UUID
+		var gotBefore time.Time
+		repo.archiveDone = func(workspaceID *uuid.UUID, doneBefore time.Time) int64 {
+			gotWorkspace, gotBefore = workspaceID, doneBefore
+			return 3
+		}
+
+		result, err := svc.ArchiveDone(userID, ArchiveDoneCardsDTO{OlderThanDays: 7})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if result.Archived != 3 || gotWorkspace == nil || *gotWorkspace != workspacesSvc.personalID {
+			t.Fatalf("unexpected result %+v for workspace %v", result, gotWorkspace)
+		}
+		if age := time.Since(gotBefore); age < 7*24*time.Hour-time.Minute || age > 7*24*time.Hour+time.Minute {
+			t.Fatalf("expected cutoff seven days ago, got %v", gotBefore)
+		}
+	})
+
+	t.Run("job archives done cards of every workspace", func(t *testing.T) {
+		svc, repo := newService(card)
+		var gotWorkspace *uuid.UUID
+		called := false
+		repo.archiveDone = func(workspaceID *uuid.UUID, doneBefore time.Time) int64 {
+			gotWorkspace, called = workspaceID, true
+			return 2
+		}
+
+		if archived := NewDoneArchiver(svc, 24*time.Hour).ArchiveOnce(time.Now()); archived != 2 || !called || gotWorkspace != nil {
+			t.Fatalf("expected archiving across workspaces, got %d for %v", archived, gotWorkspace)
+		}
+	})
+}
+
+func TestCardsService_WorkspaceRoles(t *testing.T) {
+	authorID := uuid.New()
+	workspaceID := uuid.New()
+	card := models.Card{Base: models.Base{ID: uuid.New()}, Title: "shared", UserID: authorID, WorkspaceID: &workspaceID}
+
+	newService := func(userID uuid.UUID, role models.Role) (CardsService, *fakeCardsRepository) {
+		repo := &fakeCardsRepository{findByID: func(id uuid.UUID) (models.Card, error) { return card, nil }}
+		workspacesSvc := newFakeWorkspacesService()
+		workspacesSvc.join(workspaceID, userID, role)
+		return NewCardsService(repo, newFakeBoardsService(authorID), newFakeTagsService(), workspacesSvc, newFakeBlobStore()), repo
+	}
+
+	t.Run("members can edit and delete cards of others", func(t *testing.T) {
+		memberID := uuid.New()
+		svc, repo := newService(memberID, models.RoleMember)
+		title := "renamed"
+
+		if _, err := svc.Update(memberID, card.ID, UpdateCardDTO{Title: &title}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if _, err := svc.Delete(memberID, card.ID); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.deletedCard != card.ID {
+			t.Fatalf("expected card to be deleted")
+		}
+	})
+
+	t.Run("viewers can read but not change cards", func(t *testing.T) {
+		viewerID := uuid.New()
+		svc, repo := newService(viewerID, models.RoleViewer)
+		title := "renamed"
+
+		if _, err := svc.ListRevisions(viewerID, card.ID); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if _, err := svc.Update(viewerID, card.ID, UpdateCardDTO{Title: &title}); !errors.Is(err, apperrors.ErrForbidden) {
+			t.Fatalf("expected forbidden error, got %v", err)
+		}
+		if _, err := svc.Delete(viewerID, card.ID); !errors.Is(err, apperrors.ErrForbidden) {
+			t.Fatalf("expected forbidden error, got %v", err)
+		}
+		if repo.updatedCard != nil || repo.deletedCard != uuid.Nil {
+			t.Fatalf("did not expect any change")
+		}
+	})
+
+	t.Run("the author loses access after leaving the workspace", func(t *testing.T) {
+		svc, _ := newService(uuid.New(), models.RoleMember)
+
+		if _, err := svc.Delete(authorID, card.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("lists the requested workspace only for its members", func(t *testing.T) {
+		viewerID := uuid.New()
+		svc, repo := newService(viewerID, models.RoleViewer)
+		repo.list = func(query CardsQuery) ([]models.Card, error) {
+			if query.WorkspaceID != workspaceID {
+				return nil, errors.New("unexpected workspace id")
+			}
+			return []models.Card{card}, nil
+		}
+
+		if _, err := svc.List(viewerID, ListCardsQuery{WorkspaceID: workspaceID.String()}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if _, err := svc.List(uuid.New(), ListCardsQuery{WorkspaceID: workspaceID.String()}); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
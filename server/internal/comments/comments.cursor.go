@@ -0,0 +1,44 @@
+package comments
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"cards/internal/apperrors"
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var errInvalidCursor = apperrors.Validation("invalid cursor")
+
+// commentsCursor points at the last thread of a page; threads are listed
+// oldest-first by (created_at, id).
+type commentsCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        uuid.UUID `json:"i"`
+}
+
+func newCommentsCursor(comment models.Comment) commentsCursor {
+	return commentsCursor{CreatedAt: comment.CreatedAt, ID: comment.ID}
+}
+
+func (c commentsCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCommentsCursor(value string) (*commentsCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var cursor commentsCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, errInvalidCursor
+	}
+
+	return &cursor, nil
+}
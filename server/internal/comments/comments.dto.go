@@ -0,0 +1,32 @@
+package comments
+
+import (
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultCommentsPageSize = 50
+	maxCommentsPageSize     = 200
+)
+
+type CreateCommentDTO struct {
+	Body     string     `json:"body" binding:"required"`
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+type UpdateCommentDTO struct {
+	Body string `json:"body" binding:"required"`
+}
+
+type ListCommentsQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+type CommentsPage struct {
+	Comments   []models.Comment
+	NextCursor *string
+	Total      int64
+}
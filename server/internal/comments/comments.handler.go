@@ -0,0 +1,148 @@
+package comments
+
+import (
+	"cards/internal/apperrors"
+	"cards/internal/params"
+	"cards/internal/types"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentsHandler interface {
+	List(c *gin.Context)
+	Create(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type commentsHandler struct {
+	Service CommentsService
+}
+
+func NewCommentsHandler(service CommentsService) CommentsHandler {
+	return &commentsHandler{Service: service}
+}
+
+func (h *commentsHandler) List(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var query ListCommentsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apperrors.Respond(c, "Invalid query parameters", apperrors.Binding(err))
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	page, err := h.Service.List(userID, cardID, query)
+	if err != nil {
+		apperrors.Respond(c, "Failed to list comments", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewPaginatedApiResponse(
+		http.StatusOK,
+		"Comments listed successfully",
+		page.Comments,
+		types.PageMeta{NextCursor: page.NextCursor, Total: page.Total},
+	))
+}
+
+func (h *commentsHandler) Create(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto CreateCommentDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	comment, err := h.Service.Create(userID, cardID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to create comment", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewApiResponse(http.StatusCreated, "Comment created successfully", comment, nil))
+}
+
+func (h *commentsHandler) Update(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto UpdateCommentDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	commentID, err := params.UUID(c, "commentID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid comment ID", err)
+		return
+	}
+
+	comment, err := h.Service.Update(userID, cardID, commentID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to update comment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Comment updated successfully", comment, nil))
+}
+
+func (h *commentsHandler) Delete(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	cardID, err := params.UUID(c, "cardID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid card ID", err)
+		return
+	}
+
+	commentID, err := params.UUID(c, "commentID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid comment ID", err)
+		return
+	}
+
+	comment, err := h.Service.Delete(userID, cardID, commentID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to delete comment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Comment deleted successfully", comment, nil))
+}
@@ -0,0 +1,94 @@
+package comments
+
+import (
+	"cards/internal/apperrors"
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CommentsRepository interface {
+	FindByID(uuid.UUID) (models.Comment, error)
+	ListThreads(cardID uuid.UUID, after *commentsCursor, limit int) ([]models.Comment, error)
+	CountThreads(cardID uuid.UUID) (int64, error)
+	CountReplies(uuid.UUID) (int64, error)
+	Create(*models.Comment) error
+	Update(*models.Comment) error
+	Delete(uuid.UUID) error
+}
+
+type commentsRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentsRepository(db *gorm.DB) CommentsRepository {
+	return &commentsRepository{db: db}
+}
+
+func oldestFirst(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at, id")
+}
+
+func (r *commentsRepository) FindByID(id uuid.UUID) (models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.Where("id = ?", id).First(&comment).Error; err != nil {
+		return models.Comment{}, err
+	}
+	return comment, nil
+}
+
+func (r *commentsRepository) ListThreads(cardID uuid.UUID, after *commentsCursor, limit int) ([]models.Comment, error) {
+	db := r.db.Preload("Replies", oldestFirst).Where("card_id = ? AND parent_id IS NULL", cardID)
+	if after != nil {
+		db = db.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var comments []models.Comment
+	if err := oldestFirst(db).Limit(limit).Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *commentsRepository) CountThreads(cardID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Comment{}).Where("card_id = ? AND parent_id IS NULL", cardID).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *commentsRepository) CountReplies(id uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.Comment{}).Where("parent_id = ?", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *commentsRepository) Create(comment *models.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+func (r *commentsRepository) Update(comment *models.Comment) error {
+	return r.db.Omit(clause.Associations).Save(comment).Error
+}
+
+// Delete removes a comment that has no replies. The replies foreign key
+// cascades, so the guard keeps a reply posted since the caller checked from
+// being deleted along with its parent.
+func (r *commentsRepository) Delete(id uuid.UUID) error {
+	result := r.db.
+		Where("NOT EXISTS (SELECT 1 FROM comments AS replies WHERE replies.parent_id = comments.id)").
+		Delete(&models.Comment{Base: models.Base{ID: id}})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.Conflict("comment has new replies, please try again")
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package comments
+
+import (
+	"cards/internal/auth"
+	"cards/internal/cards"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func RegisterCommentsRoutes(appGroup *gin.RouterGroup, db *gorm.DB, cardsService cards.CardsService, authMiddleware auth.Middleware) {
+	repository := NewCommentsRepository(db)
+	service := NewCommentsService(repository, cardsService)
+	handler := NewCommentsHandler(service)
+
+	commentsGroup := appGroup.Group("/cards/:cardID/comments")
+	commentsGroup.Use(authMiddleware.Authenticate())
+	commentsGroup.GET("", handler.List)
+	commentsGroup.POST("", handler.Create)
+	commentsGroup.PATCH("/:commentID", handler.Update)
+	commentsGroup.DELETE("/:commentID", handler.Delete)
+}
@@ -0,0 +1,212 @@
+package comments
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"cards/internal/apperrors"
+	"cards/internal/authz"
+	"cards/internal/cards"
+	"cards/internal/models"
+)
+
+type CommentsService interface {
+	List(userID uuid.UUID, cardID uuid.UUID, query ListCommentsQuery) (*CommentsPage, error)
+	Create(userID uuid.UUID, cardID uuid.UUID, dto CreateCommentDTO) (*models.Comment, error)
+	Update(userID uuid.UUID, cardID uuid.UUID, commentID uuid.UUID, dto UpdateCommentDTO) (*models.Comment, error)
+	Delete(userID uuid.UUID, cardID uuid.UUID, commentID uuid.UUID) (*models.Comment, error)
+}
+
+type commentsService struct {
+	Repository CommentsRepository
+	Cards      cards.CardsService
+}
+
+func NewCommentsService(repository CommentsRepository, cardsService cards.CardsService) CommentsService {
+	return &commentsService{Repository: repository, Cards: cardsService}
+}
+
+func (s *commentsService) List(userID uuid.UUID, cardID uuid.UUID, query ListCommentsQuery) (*CommentsPage, error) {
+	if err := s.checkCard(userID, cardID, authz.Read); err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultCommentsPageSize
+	}
+	if limit > maxCommentsPageSize {
+		limit = maxCommentsPageSize
+	}
+
+	var after *commentsCursor
+	if query.Cursor != "" {
+		cursor, err := decodeCommentsCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = cursor
+	}
+
+	total, err := s.Repository.CountThreads(cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.Repository.ListThreads(cardID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &CommentsPage{Comments: comments, Total: total}
+	if page.Comments == nil {
+		page.Comments = []models.Comment{}
+	}
+	if len(comments) > limit {
+		page.Comments = comments[:limit]
+		next := newCommentsCursor(page.Comments[limit-1]).encode()
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
+
+func (s *commentsService) Create(userID uuid.UUID, cardID uuid.UUID, dto CreateCommentDTO) (*models.Comment, error) {
+	if err := s.checkCard(userID, cardID, authz.Write); err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(dto.Body)
+	if body == "" {
+		return nil, apperrors.Validation("comment body is required")
+	}
+
+	comment := models.Comment{Body: body, CardID: cardID, UserID: userID}
+	if dto.ParentID != nil {
+		parent, err := s.Repository.FindByID(*dto.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.CardID != cardID {
+			return nil, apperrors.Validation("parent comment belongs to another card")
+		}
+		// Replying to a reply continues the same thread.
+		root := parent
+		if parent.ParentID != nil {
+			if root, err = s.Repository.FindByID(*parent.ParentID); err != nil {
+				return nil, err
+			}
+		}
+		if root.DeletedAt != nil {
+			return nil, apperrors.Validation("parent comment has been deleted")
+		}
+		comment.ParentID = &root.ID
+	}
+
+	if err := s.Repository.Create(&comment); err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func (s *commentsService) Update(userID uuid.UUID, cardID uuid.UUID, commentID uuid.UUID, dto UpdateCommentDTO) (*models.Comment, error) {
+	comment, err := s.getAuthored(userID, cardID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(dto.Body)
+	if body == "" {
+		return nil, apperrors.Validation("comment body is required")
+	}
+
+	comment.Body = body
+	if err := s.Repository.Update(comment); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+func (s *commentsService) Delete(userID uuid.UUID, cardID uuid.UUID, commentID uuid.UUID) (*models.Comment, error) {
+	comment, err := s.getAuthored(userID, cardID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replies can come from other members, so a thread whose root has replies
+	// keeps a tombstone in its place instead of losing the whole thread.
+	if comment.ParentID == nil {
+		replies, err := s.Repository.CountReplies(comment.ID)
+		if err != nil {
+			return nil, err
+		}
+		if replies > 0 {
+			now := time.Now()
+			comment.Body = ""
+			comment.DeletedAt = &now
+			if err := s.Repository.Update(comment); err != nil {
+				return nil, err
+			}
+			return comment, nil
+		}
+	}
+
+	if err := s.Repository.Delete(comment.ID); err != nil {
+		return nil, err
+	}
+
+	if comment.ParentID != nil {
+		if err := s.removeEmptyTombstone(*comment.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	return comment, nil
+}
+
+// removeEmptyTombstone deletes a deleted thread root once its last reply is
+// gone, since nothing is left to keep it for.
+func (s *commentsService) removeEmptyTombstone(rootID uuid.UUID) error {
+	root, err := s.Repository.FindByID(rootID)
+	if err != nil || root.DeletedAt == nil {
+		return err
+	}
+
+	replies, err := s.Repository.CountReplies(root.ID)
+	if err != nil || replies > 0 {
+		return err
+	}
+
+	return s.Repository.Delete(root.ID)
+}
+
+func (s *commentsService) checkCard(userID uuid.UUID, cardID uuid.UUID, action authz.Action) error {
+	_, err := s.Cards.GetAuthorized(userID, cardID, action)
+	return err
+}
+
+// getAuthored loads a comment of the card that only its author may change.
+func (s *commentsService) getAuthored(userID uuid.UUID, cardID uuid.UUID, commentID uuid.UUID) (*models.Comment, error) {
+	if err := s.checkCard(userID, cardID, authz.Write); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.Repository.FindByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.CardID != cardID || comment.DeletedAt != nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if comment.UserID != userID {
+		return nil, apperrors.ErrForbidden
+	}
+
+	return &comment, nil
+}
@@ -0,0 +1,318 @@
+package comments
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cards/internal/apperrors"
+	"cards/internal/authz"
+	"cards/internal/cards"
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeCommentsRepository struct {
+	comments map[uuid.UUID]models.Comment
+	threads  []models.Comment
+
+	after     *commentsCursor
+	created   *models.Comment
+	updated   *models.Comment
+	deletedID uuid.UUID
+}
+
+func newFakeCommentsRepository(comments ...models.Comment) *fakeCommentsRepository {
+	r := &fakeCommentsRepository{comments: map[uuid.UUID]models.Comment{}}
+	for _, comment := range comments {
+		r.comments[comment.ID] = comment
+	}
+	return r
+}
+
+func (r *fakeCommentsRepository) FindByID(id uuid.UUID) (models.Comment, error) {
+	comment, ok := r.comments[id]
+	if !ok {
+		return models.Comment{}, gorm.ErrRecordNotFound
+	}
+	return comment, nil
+}
+
+func (r *fakeCommentsRepository) ListThreads(cardID uuid.UUID, after *commentsCursor, limit int) ([]models.Comment, error) {
+	r.after = after
+	start := 0
+	if after != nil {
+		for i, comment := range r.threads {
+			if comment.ID == after.ID {
+				start = i + 1
+			}
+		}
+	}
+	end := start + limit
+	if end > len(r.threads) {
+		end = len(r.threads)
+	}
+	return r.threads[start:end], nil
+}
+
+func (r *fakeCommentsRepository) CountThreads(cardID uuid.UUID) (int64, error) {
+	return int64(len(r.threads)), nil
+}
+
+func (r *fakeCommentsRepository) CountReplies(id uuid.UUID) (int64, error) {
+	var count int64
+	for _, comment := range r.comments {
+		if comment.ParentID != nil && *comment.ParentID == id {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeCommentsRepository) Create(comment *models.Comment) error {
+	comment.ID = uuid.New()
+	r.created = comment
+	return nil
+}
+
+func (r *fakeCommentsRepository) Update(comment *models.Comment) error {
+	r.updated = comment
+	r.comments[comment.ID] = *comment
+	return nil
+}
+
+func (r *fakeCommentsRepository) Delete(id uuid.UUID) error {
+	r.deletedID = id
+	delete(r.comments, id)
+	return nil
+}
+
+type fakeCardsService struct {
+	cards.CardsService
+	card  models.Card
+	roles map[uuid.UUID]models.Role
+}
+
+func (f *fakeCardsService) GetAuthorized(userID uuid.UUID, cardID uuid.UUID, action authz.Action) (*models.Card, error) {
+	if cardID != f.card.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	role := f.roles[userID]
+	if userID == f.card.UserID {
+		role = models.RoleOwner
+	}
+	subject := authz.NewSubject(userID, []models.Membership{{WorkspaceID: *f.card.WorkspaceID, UserID: userID, Role: role}})
+	if err := authz.Can(subject, action, authz.Card(f.card)); err != nil {
+		return nil, err
+	}
+	card := f.card
+	return &card, nil
+}
+
+func newFakeCardsService(userID uuid.UUID) *fakeCardsService {
+	workspaceID := uuid.New()
+	return &fakeCardsService{card: models.Card{Base: models.Base{ID: uuid.New()}, UserID: userID, WorkspaceID: &workspaceID}}
+}
+
+func testComment(cardID uuid.UUID, userID uuid.UUID, parentID *uuid.UUID) models.Comment {
+	return models.Comment{Base: models.Base{ID: uuid.New(), CreatedAt: time.Now()}, Body: "body", CardID: cardID, UserID: userID, ParentID: parentID}
+}
+
+func TestCommentsService_Create(t *testing.T) {
+	userID := uuid.New()
+	cardsService := newFakeCardsService(userID)
+	cardID := cardsService.card.ID
+
+	t.Run("rejects cards of other users", func(t *testing.T) {
+		svc := NewCommentsService(newFakeCommentsRepository(), cardsService)
+
+		_, err := svc.Create(uuid.New(), cardID, CreateCommentDTO{Body: "hi"})
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("lets viewers read but not comment", func(t *testing.T) {
+		viewerID := uuid.New()
+		viewerCards := newFakeCardsService(userID)
+		viewerCards.roles = map[uuid.UUID]models.Role{viewerID: models.RoleViewer}
+		svc := NewCommentsService(newFakeCommentsRepository(), viewerCards)
+
+		if _, err := svc.List(viewerID, viewerCards.card.ID, ListCommentsQuery{}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if _, err := svc.Create(viewerID, viewerCards.card.ID, CreateCommentDTO{Body: "hi"}); !errors.Is(err, apperrors.ErrForbidden) {
+			t.Fatalf("expected forbidden error, got %v", err)
+		}
+	})
+
+	t.Run("replies to a reply join the root thread", func(t *testing.T) {
+		root := testComment(cardID, userID, nil)
+		reply := testComment(cardID, userID, &root.ID)
+		repo := newFakeCommentsRepository(root, reply)
+		svc := NewCommentsService(repo, cardsService)
+
+		comment, err := svc.Create(userID, cardID, CreateCommentDTO{Body: " thanks ", ParentID: &reply.ID})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if comment.ParentID == nil || *comment.ParentID != root.ID || comment.Body != "thanks" {
+			t.Fatalf("unexpected comment: %+v", comment)
+		}
+	})
+
+	t.Run("rejects parents from another card", func(t *testing.T) {
+		parent := testComment(uuid.New(), userID, nil)
+		svc := NewCommentsService(newFakeCommentsRepository(parent), cardsService)
+
+		if _, err := svc.Create(userID, cardID, CreateCommentDTO{Body: "hi", ParentID: &parent.ID}); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+}
+
+func TestCommentsService_OnlyAuthorCanChange(t *testing.T) {
+	userID := uuid.New()
+	cardsService := newFakeCardsService(userID)
+	cardID := cardsService.card.ID
+	comment := testComment(cardID, uuid.New(), nil)
+	repo := newFakeCommentsRepository(comment)
+	svc := NewCommentsService(repo, cardsService)
+
+	if _, err := svc.Update(userID, cardID, comment.ID, UpdateCommentDTO{Body: "edited"}); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected forbidden error, got %v", err)
+	}
+	if _, err := svc.Delete(userID, cardID, comment.ID); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected forbidden error, got %v", err)
+	}
+	if repo.updated != nil || repo.deletedID != uuid.Nil {
+		t.Fatalf("did not expect repository changes")
+	}
+
+	own := testComment(cardID, userID, nil)
+	repo.comments[own.ID] = own
+	if _, err := svc.Update(userID, cardID, own.ID, UpdateCommentDTO{Body: "edited"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := svc.Delete(userID, uuid.New(), own.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected not found for another card, got %v", err)
+	}
+	if _, err := svc.Delete(userID, cardID, own.ID); err != nil || repo.deletedID != own.ID {
+		t.Fatalf("expected own comment to be deleted, got %v", err)
+	}
+}
+
+func TestCommentsService_DeleteKeepsOtherUsersReplies(t *testing.T) {
+	authorID := uuid.New()
+	replierID := uuid.New()
+	cardsService := newFakeCardsService(authorID)
+	cardsService.roles = map[uuid.UUID]models.Role{replierID: models.RoleMember}
+	cardID := cardsService.card.ID
+	root := testComment(cardID, authorID, nil)
+	reply := testComment(cardID, replierID, &root.ID)
+	repo := newFakeCommentsRepository(root, reply)
+	svc := NewCommentsService(repo, cardsService)
+
+	deleted, err := svc.Delete(authorID, cardID, root.ID)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.deletedID != uuid.Nil {
+		t.Fatalf("did not expect a root with replies to be removed")
+	}
+	if deleted.DeletedAt == nil || deleted.Body != "" {
+		t.Fatalf("expected the root to be tombstoned, got %+v", deleted)
+	}
+	if got, ok := repo.comments[reply.ID]; !ok || got.Body != reply.Body {
+		t.Fatalf("expected the other user's reply to survive, got %+v", got)
+	}
+
+	if _, err := svc.Update(authorID, cardID, root.ID, UpdateCommentDTO{Body: "back"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected a tombstone to be gone for edits, got %v", err)
+	}
+	if _, err := svc.Create(replierID, cardID, CreateCommentDTO{Body: "late", ParentID: &root.ID}); !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("expected replying to a tombstone to fail validation, got %v", err)
+	}
+
+	if _, err := svc.Create(replierID, cardID, CreateCommentDTO{Body: "late", ParentID: &reply.ID}); !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("expected replying to a reply under a tombstone to fail validation, got %v", err)
+	}
+
+	if _, err := svc.Delete(replierID, cardID, reply.ID); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, ok := repo.comments[reply.ID]; ok {
+		t.Fatalf("expected the reply to be deleted outright")
+	}
+	if _, ok := repo.comments[root.ID]; ok {
+		t.Fatalf("expected the tombstone to go with its last reply")
+	}
+}
+
+func TestCommentsService_DeleteReplyKeepsLiveRoot(t *testing.T) {
+	userID := uuid.New()
+	cardsService := newFakeCardsService(userID)
+	cardID := cardsService.card.ID
+	root := testComment(cardID, userID, nil)
+	reply := testComment(cardID, userID, &root.ID)
+	repo := newFakeCommentsRepository(root, reply)
+	svc := NewCommentsService(repo, cardsService)
+
+	if _, err := svc.Delete(userID, cardID, reply.ID); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, ok := repo.comments[root.ID]; !ok {
+		t.Fatalf("expected a root that was not deleted to stay")
+	}
+}
+
+func TestCommentsService_ListPagination(t *testing.T) {
+	userID := uuid.New()
+	cardsService := newFakeCardsService(userID)
+	cardID := cardsService.card.ID
+
+	repo := newFakeCommentsRepository()
+	for i := 0; i < 5; i++ {
+		repo.threads = append(repo.threads, testComment(cardID, userID, nil))
+	}
+	svc := NewCommentsService(repo, cardsService)
+
+	page, err := svc.List(userID, cardID, ListCommentsQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	var seen []uuid.UUID
+	for {
+		if page.Total != 5 {
+			t.Fatalf("expected total 5, got %d", page.Total)
+		}
+		for _, comment := range page.Comments {
+			seen = append(seen, comment.ID)
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		if page, err = svc.List(userID, cardID, ListCommentsQuery{Limit: 2, Cursor: *page.NextCursor}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.after == nil {
+			t.Fatalf("expected cursor to reach the repository")
+		}
+	}
+
+	if len(seen) != len(repo.threads) {
+		t.Fatalf("expected %d comments, saw %d", len(repo.threads), len(seen))
+	}
+	for i := range repo.threads {
+		if seen[i] != repo.threads[i].ID {
+			t.Fatalf("unexpected comment at %d", i)
+		}
+	}
+
+	if _, err := svc.List(userID, cardID, ListCommentsQuery{Cursor: "nope"}); err == nil {
+		t.Fatalf("expected invalid cursor error")
+	}
+}
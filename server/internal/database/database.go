@@ -1,11 +1,16 @@
 package database
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"strings"
 
 	"cards/internal/models"
 
+	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -28,10 +33,287 @@ func Connect() {
 }
 
 func AutoMigrate() error {
-	return DB.AutoMigrate(
+	err := DB.AutoMigrate(
 		&models.Card{},
 		&models.User{},
+		&models.Board{},
+		&models.Column{},
+		&models.Tag{},
+		&models.ChecklistItem{},
+		&models.Comment{},
+		&models.Attachment{},
+		&models.CardRevision{},
+		&models.Workspace{},
+		&models.Membership{},
+		&models.WorkspaceInvitation{},
+		&models.Session{},
+		&models.PasswordReset{},
+		&models.EmailVerification{},
+		&models.TOTPFactor{},
+		&models.RecoveryCode{},
+		&models.UserIdentity{},
+		&models.PersonalAccessToken{},
+		&models.LLMUsage{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := migrateCardSearch(DB, SearchLanguage()); err != nil {
+		return err
+	}
+
+	if err := migrateCardsToDefaultBoards(DB); err != nil {
+		return err
+	}
+
+	if err := migrateToPersonalWorkspaces(DB); err != nil {
+		return err
+	}
+
+	if err := dropUserScopedIndexes(DB); err != nil {
+		return err
+	}
+
+	return migrateTagsToWorkspaces(DB)
+}
+
+const defaultSearchLanguage = "portuguese"
+
+var searchLanguagePattern = regexp.MustCompile(`^[a-z_]+$`)
+
+// SearchLanguage is the Postgres text search configuration used for card
+// search, read from SEARCH_LANGUAGE. It ends up in DDL, so anything that is
+// not a plain configuration name falls back to the default.
+func SearchLanguage() string {
+	language := strings.ToLower(strings.TrimSpace(os.Getenv("SEARCH_LANGUAGE")))
+	if !searchLanguagePattern.MatchString(language) {
+		return defaultSearchLanguage
+	}
+	return language
+}
+
+// migrateCardSearch keeps cards.search_vector generated with the configured
+// language, recreating it when the language changes.
+func migrateCardSearch(db *gorm.DB, language string) error {
+	expression := fmt.Sprintf(
+		"to_tsvector('%s'::regconfig, coalesce(title, '') || ' ' || coalesce(content, ''))",
+		language,
+	)
+
+	var current []string
+	err := db.Raw(
+		"SELECT generation_expression FROM information_schema.columns WHERE table_name = 'cards' AND column_name = 'search_vector'",
+	).Scan(&current).Error
+	if err != nil {
+		return err
+	}
+
+	if len(current) > 0 && strings.Contains(current[0], "'"+language+"'") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(current) > 0 {
+			if err := tx.Exec("ALTER TABLE cards DROP COLUMN search_vector").Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("ALTER TABLE cards ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (" + expression + ") STORED").Error; err != nil {
+			return err
+		}
+		return tx.Exec("CREATE INDEX IF NOT EXISTS idx_cards_search_vector ON cards USING GIN (search_vector)").Error
+	})
+}
+
+// Cards created before boards existed only carry a status; move each one into
+// its owner's default board, in the column named after that status.
+func migrateCardsToDefaultBoards(db *gorm.DB) error {
+	var userIDs []uuid.UUID
+	if err := db.Model(&models.Card{}).Where("board_id IS NULL").Distinct().Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var board models.Board
+			err := tx.Preload("Columns", func(db *gorm.DB) *gorm.DB {
+				return db.Order("position")
+			}).Where("user_id = ? AND is_default = ?", userID, true).First(&board).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				board = models.Board{Name: models.DefaultBoardName, IsDefault: true, UserID: userID}
+				for i, name := range models.DefaultBoardColumns {
+					board.Columns = append(board.Columns, models.Column{Name: name, Position: i})
+				}
+				err = tx.Create(&board).Error
+			}
+			if err != nil {
+				return err
+			}
+
+			for _, column := range board.Columns {
+				if err := tx.Model(&models.Card{}).
+					Where("user_id = ? AND board_id IS NULL AND status = ?", userID, column.Name).
+					Updates(map[string]any{"board_id": board.ID, "column_id": column.ID}).Error; err != nil {
+					return err
+				}
+			}
+
+			if len(board.Columns) == 0 {
+				return nil
+			}
+			return tx.Model(&models.Card{}).
+				Where("user_id = ? AND board_id IS NULL", userID).
+				Updates(map[string]any{"board_id": board.ID, "column_id": board.Columns[0].ID}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Boards and cards created before workspaces existed were private to their
+// owner; move them into the owner's personal workspace.
+func migrateToPersonalWorkspaces(db *gorm.DB) error {
+	var userIDs []uuid.UUID
+	err := db.Raw(
+		"SELECT user_id FROM boards WHERE workspace_id IS NULL UNION SELECT user_id FROM cards WHERE workspace_id IS NULL",
+	).Scan(&userIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			workspace, err := personalWorkspace(tx, userID)
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Model(&models.Board{}).
+				Where("user_id = ? AND workspace_id IS NULL", userID).
+				UpdateColumn("workspace_id", workspace.ID).Error; err != nil {
+				return err
+			}
+			// Trashed cards move too, and updated_at is left alone so the
+			// migration does not delay archiving of done cards.
+			return tx.Unscoped().Model(&models.Card{}).
+				Where("user_id = ? AND workspace_id IS NULL", userID).
+				UpdateColumn("workspace_id", workspace.ID).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func personalWorkspace(tx *gorm.DB, userID uuid.UUID) (models.Workspace, error) {
+	var workspace models.Workspace
+	err := tx.Where("owner_id = ? AND is_personal = ?", userID, true).First(&workspace).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		workspace = models.Workspace{
+			Name:        models.PersonalWorkspaceName,
+			IsPersonal:  true,
+			OwnerID:     userID,
+			Memberships: []models.Membership{{UserID: userID, Role: models.RoleOwner}},
+		}
+		err = tx.Create(&workspace).Error
+	}
+	return workspace, err
+}
+
+// Tags used to be unique per user; they are now unique per workspace. Each
+// tag moves to the workspace of the cards using it, or its creator's personal
+// workspace if no card does. A tag used in several workspaces is copied into
+// each, and same-named tags meeting in one workspace are merged.
+func migrateTagsToWorkspaces(db *gorm.DB) error {
+	var tags []models.Tag
+	if err := db.Where("workspace_id IS NULL").Order("created_at").Find(&tags).Error; err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var workspaceIDs []uuid.UUID
+			err := tx.Raw(
+				"SELECT DISTINCT cards.workspace_id FROM card_tags JOIN cards ON cards.id = card_tags.card_id "+
+					"WHERE card_tags.tag_id = ? AND cards.workspace_id IS NOT NULL ORDER BY cards.workspace_id",
+				tag.ID,
+			).Scan(&workspaceIDs).Error
+			if err != nil {
+				return err
+			}
+			if len(workspaceIDs) == 0 {
+				workspace, err := personalWorkspace(tx, tag.UserID)
+				if err != nil {
+					return err
+				}
+				workspaceIDs = []uuid.UUID{workspace.ID}
+			}
+
+			kept := false
+			for _, workspaceID := range workspaceIDs {
+				var target models.Tag
+				err := tx.Where("workspace_id = ? AND name = ?", workspaceID, tag.Name).First(&target).Error
+				switch {
+				case errors.Is(err, gorm.ErrRecordNotFound) && !kept:
+					kept = true
+					if err := tx.Model(&tag).UpdateColumn("workspace_id", workspaceID).Error; err != nil {
+						return err
+					}
+					continue
+				case errors.Is(err, gorm.ErrRecordNotFound):
+					target = models.Tag{Name: tag.Name, Color: tag.Color, UserID: tag.UserID, WorkspaceID: &workspaceID}
+					err = tx.Create(&target).Error
+				}
+				if err != nil {
+					return err
+				}
+
+				if err := tx.Exec(
+					"INSERT INTO card_tags (card_id, tag_id) SELECT card_tags.card_id, ? FROM card_tags "+
+						"JOIN cards ON cards.id = card_tags.card_id WHERE card_tags.tag_id = ? AND cards.workspace_id = ? "+
+						"ON CONFLICT DO NOTHING",
+					target.ID, tag.ID, workspaceID,
+				).Error; err != nil {
+					return err
+				}
+				if err := tx.Exec(
+					"DELETE FROM card_tags WHERE tag_id = ? AND card_id IN (SELECT id FROM cards WHERE workspace_id = ?)",
+					tag.ID, workspaceID,
+				).Error; err != nil {
+					return err
+				}
+			}
+
+			if kept {
+				return nil
+			}
+			return tx.Delete(&models.Tag{Base: models.Base{ID: tag.ID}}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Due date and priority indexes moved from user_id to workspace_id along with
+// the cards, and tag names became unique per workspace instead of per user.
+// AutoMigrate creates the new indexes but never drops the old; the tag one
+// has to go before tags are copied between workspaces.
+func dropUserScopedIndexes(db *gorm.DB) error {
+	for _, index := range []string{"idx_cards_user_due", "idx_cards_user_priority", "idx_tags_user_name"} {
+		if err := db.Exec("DROP INDEX IF EXISTS " + index).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func GetDB() *gorm.DB {
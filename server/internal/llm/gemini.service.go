@@ -3,8 +3,12 @@ package llm
 import (
 	"context"
 	"os"
+	"time"
 
 	"google.golang.org/genai"
+
+	"cards/internal/apperrors"
+	"cards/internal/models"
 )
 
 type geminiService struct {
@@ -62,6 +66,23 @@ func (s *geminiService) GenerateMultipleCards(
 									Type:        genai.TypeString,
 									Description: "The content of the card",
 								},
+								"due_date": {
+									Type:        genai.TypeString,
+									Description: "Due date as YYYY-MM-DD, omitted when the user did not mention one",
+									Nullable:    genai.Ptr(true),
+								},
+								"priority": {
+									Type:        genai.TypeString,
+									Description: "The priority of the card",
+									Enum:        models.PriorityNames,
+								},
+								"checklist": {
+									Type:        genai.TypeArray,
+									Description: "Checklist items of the card, empty when the card has no subtasks",
+									Items: &genai.Schema{
+										Type: genai.TypeString,
+									},
+								},
 							},
 						},
 					},
@@ -85,12 +106,21 @@ func (s *geminiService) GenerateMultipleCards(
 					{
 						Text: "If possible, just provide the title and content of the card exactly how the user requested.",
 					},
+					{
+						Text: dueDateInstruction(time.Now()),
+					},
+					{
+						Text: "Only set a priority when the user asks for one or clearly implies urgency; otherwise use \"none\".",
+					},
+					{
+						Text: "Only add checklist items when the user lists steps or subtasks for a card; otherwise use an empty list.",
+					},
 				},
 			},
 		},
 	)
 	if err != nil {
-		return nil, err
+		return nil, apperrors.Upstream("gemini request failed", err)
 	}
 
 	if len(resp.Candidates) == 0 ||
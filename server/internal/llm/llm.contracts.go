@@ -24,6 +24,9 @@ type CardsResponse struct {
 }
 
 type Card struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Title     string   `json:"title"`
+	Content   string   `json:"content"`
+	DueDate   *string  `json:"due_date"`
+	Priority  string   `json:"priority"`
+	Checklist []string `json:"checklist"`
 }
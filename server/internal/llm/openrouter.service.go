@@ -8,6 +8,10 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"time"
+
+	"cards/internal/apperrors"
+	"cards/internal/models"
 )
 
 type openrouterService struct {
@@ -35,6 +39,10 @@ func (s *openrouterService) GenerateMultipleCards(
 	messages []Message,
 ) (*CardsResponse, error) {
 
+	if s.apiKey == "" {
+		return nil, apperrors.Unavailable("card generation is not configured", nil)
+	}
+
 	payload, err := s.buildPayload(messages)
 	if err != nil {
 		return nil, err
@@ -60,7 +68,7 @@ func (s *openrouterService) GenerateMultipleCards(
 
 func (s *openrouterService) buildPayload(messages []Message) (map[string]interface{}, error) {
 	if len(messages) == 0 {
-		return nil, fmt.Errorf("no messages provided")
+		return nil, apperrors.Validation("no messages provided")
 	}
 
 	return map[string]interface{}{
@@ -71,6 +79,9 @@ func (s *openrouterService) buildPayload(messages []Message) (map[string]interfa
 			system("Do not include any unnecessary information or explanations."),
 			system("Analyze if user wants to create multiple cards with same prompt, checking if prompt has different subjects."),
 			system("If possible, just provide the title and content of the card exactly how the user requested."),
+			system(dueDateInstruction(time.Now())),
+			system("Only set a priority when the user asks for one or clearly implies urgency; otherwise use \"none\"."),
+			system("Only add checklist items when the user lists steps or subtasks for a card; otherwise use an empty list."),
 			{
 				"role":    string(RoleUser),
 				"content": messages[0].Content,
@@ -80,6 +91,13 @@ func (s *openrouterService) buildPayload(messages []Message) (map[string]interfa
 	}, nil
 }
 
+func dueDateInstruction(now time.Time) string {
+	return fmt.Sprintf(
+		"Today is %s. Only set a due date when the user mentions a deadline, resolving relative dates against today; otherwise use null.",
+		now.Format("2006-01-02"),
+	)
+}
+
 func system(content string) map[string]string {
 	return map[string]string{
 		"role":    string(RoleSystem),
@@ -107,8 +125,23 @@ func cardsResponseSchema() map[string]interface{} {
 								"content": map[string]interface{}{
 									"type": "string",
 								},
+								"due_date": map[string]interface{}{
+									"type":        []string{"string", "null"},
+									"description": "Due date as YYYY-MM-DD, or null when the user did not mention one",
+								},
+								"priority": map[string]interface{}{
+									"type": "string",
+									"enum": models.PriorityNames,
+								},
+								"checklist": map[string]interface{}{
+									"type":        "array",
+									"description": "Checklist items of the card, empty when the card has no subtasks",
+									"items": map[string]interface{}{
+										"type": "string",
+									},
+								},
 							},
-							"required":             []string{"title", "content"},
+							"required":             []string{"title", "content", "due_date", "priority", "checklist"},
 							"additionalProperties": false,
 						},
 					},
@@ -147,12 +180,15 @@ func (s *openrouterService) doRequest(
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, apperrors.Unavailable("openrouter is unreachable", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("openrouter error: %s", resp.Status)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return nil, apperrors.Unavailable("openrouter error: "+resp.Status, nil)
+	case resp.StatusCode >= 400:
+		return nil, apperrors.Upstream("openrouter error: "+resp.Status, nil)
 	}
 
 	return io.ReadAll(resp.Body)
@@ -161,11 +197,11 @@ func (s *openrouterService) doRequest(
 func parseOpenRouterResponse(data []byte) (string, error) {
 	var resp OpenRouterResponse
 	if err := json.Unmarshal(data, &resp); err != nil {
-		return "", err
+		return "", apperrors.Upstream("invalid response from OpenRouter", err)
 	}
 
 	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("empty choices from OpenRouter")
+		return "", apperrors.Upstream("empty choices from OpenRouter", nil)
 	}
 
 	return resp.Choices[0].Message.Content, nil
@@ -174,7 +210,7 @@ func parseOpenRouterResponse(data []byte) (string, error) {
 func parseCardsResponse(content string) (*CardsResponse, error) {
 	var cards CardsResponse
 	if err := json.Unmarshal([]byte(content), &cards); err != nil {
-		return nil, err
+		return nil, apperrors.Upstream("invalid cards from OpenRouter", err)
 	}
 	return &cards, nil
 }
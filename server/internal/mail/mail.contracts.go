@@ -0,0 +1,37 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+// Message is a plain text email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// NewMailerFromEnv picks the mailer from MAIL_DRIVER, defaulting to logging
+// messages so development needs no mail server.
+func NewMailerFromEnv() (Mailer, error) {
+	switch os.Getenv("MAIL_DRIVER") {
+	case "", "log":
+		return NewLogMailer(), nil
+	case "smtp":
+		return NewSMTPMailer(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		})
+	default:
+		return nil, errors.New("unknown MAIL_DRIVER")
+	}
+}
@@ -0,0 +1,19 @@
+package mail
+
+import (
+	"context"
+	"log"
+)
+
+type logMailer struct{}
+
+// NewLogMailer returns a mailer that only writes messages to the log. It is
+// meant for development: messages may carry secrets such as reset links.
+func NewLogMailer() Mailer {
+	return logMailer{}
+}
+
+func (logMailer) Send(ctx context.Context, message Message) error {
+	log.Printf("mail to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
@@ -0,0 +1,121 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	config SMTPConfig
+	sender string
+	dialer net.Dialer
+}
+
+// NewSMTPMailer sends mail through an SMTP relay. It upgrades to TLS when the
+// server offers STARTTLS and only authenticates when a username is set, so
+// local catch-all servers such as MailHog work without credentials.
+func NewSMTPMailer(config SMTPConfig) (Mailer, error) {
+	if config.Host == "" || config.From == "" {
+		return nil, errors.New("smtp host and sender address are required")
+	}
+	sender, err := netmail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+	if config.Port == "" {
+		config.Port = "25"
+	}
+	return &smtpMailer{config: config, sender: sender.Address, dialer: net.Dialer{Timeout: 10 * time.Second}}, nil
+}
+
+func (m *smtpMailer) Send(ctx context.Context, message Message) error {
+	data, err := m.format(message, time.Now())
+	if err != nil {
+		return err
+	}
+
+	conn, err := m.dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.config.Host, m.config.Port))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.config.Host}); err != nil {
+			return err
+		}
+	}
+	if m.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.sender); err != nil {
+		return err
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// format renders the message as RFC 5322 text. Recipients and subjects with
+// line breaks are refused so they cannot inject headers.
+func (m *smtpMailer) format(message Message, now time.Time) ([]byte, error) {
+	if _, err := netmail.ParseAddress(message.To); err != nil {
+		return nil, fmt.Errorf("invalid recipient address: %w", err)
+	}
+	if strings.ContainsAny(message.To+message.Subject, "\r\n") {
+		return nil, errors.New("mail headers cannot contain line breaks")
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + m.config.From + "\r\n")
+	b.WriteString("To: " + message.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", message.Subject) + "\r\n")
+	b.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	body := strings.ReplaceAll(message.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,176 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpTestServer is a catch-all SMTP server in the spirit of MailHog: it
+// accepts every message without TLS or authentication and records it.
+type smtpTestServer struct {
+	listener net.Listener
+	received chan smtpTestMessage
+}
+
+type smtpTestMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPTestServer(t *testing.T) *smtpTestServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &smtpTestServer{listener: listener, received: make(chan smtpTestMessage, 1)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *smtpTestServer) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost ESMTP")
+
+	var message smtpTestMessage
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			text.PrintfLine("250-localhost")
+			text.PrintfLine("250 8BITMIME")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message.from = smtpTestPath(line)
+			text.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			message.to = append(message.to, smtpTestPath(line))
+			text.PrintfLine("250 OK")
+		case command == "DATA":
+			text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			message.data = string(data)
+			text.PrintfLine("250 OK")
+			s.received <- message
+		case command == "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// smtpTestPath returns the address between the angle brackets of a MAIL or
+// RCPT command, ignoring any parameters after it.
+func smtpTestPath(line string) string {
+	_, path, _ := strings.Cut(line, "<")
+	path, _, _ = strings.Cut(path, ">")
+	return path
+}
+
+func (s *smtpTestServer) config() SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return SMTPConfig{Host: host, Port: port, From: "Cards <no-reply@cards.test>"}
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	server := newSMTPTestServer(t)
+	mailer, err := NewSMTPMailer(server.config())
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = mailer.Send(ctx, Message{To: "ana@example.com", Subject: "Redefinição de senha", Body: "line one\nline two"})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	message := <-server.received
+	if message.from != "no-reply@cards.test" || len(message.to) != 1 || message.to[0] != "ana@example.com" {
+		t.Fatalf("unexpected envelope: %+v", message)
+	}
+
+	headers, err := textproto.NewReader(bufio.NewReader(strings.NewReader(message.data))).ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("failed to parse headers: %v", err)
+	}
+	if headers.Get("To") != "ana@example.com" || headers.Get("Subject") != "=?utf-8?q?Redefini=C3=A7=C3=A3o_de_senha?=" {
+		t.Fatalf("unexpected headers: %v", headers)
+	}
+	if !strings.HasSuffix(message.data, "\nline one\nline two\n") {
+		t.Fatalf("unexpected body: %q", message.data)
+	}
+}
+
+func TestSMTPMailer_RejectsHeaderInjection(t *testing.T) {
+	server := newSMTPTestServer(t)
+	mailer, err := NewSMTPMailer(server.config())
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	for _, message := range []Message{
+		{To: "ana@example.com\r\nBcc: eve@example.com", Subject: "hi"},
+		{To: "ana@example.com", Subject: "hi\r\nBcc: eve@example.com"},
+		{To: "not an address", Subject: "hi"},
+	} {
+		if err := mailer.Send(context.Background(), message); err == nil {
+			t.Fatalf("expected %+v to be refused", message)
+		}
+	}
+}
+
+// Runs against a real SMTP server, e.g. the mailhog service from
+// docker-compose.yml, whose inbox is then at http://localhost:8025:
+//
+//	SMTP_TEST_HOST=localhost SMTP_TEST_PORT=1025 go test ./internal/mail
+func TestSMTPMailer_Integration(t *testing.T) {
+	host := os.Getenv("SMTP_TEST_HOST")
+	if host == "" {
+		t.Skip("SMTP_TEST_HOST not set")
+	}
+
+	mailer, err := NewSMTPMailer(SMTPConfig{
+		Host:     host,
+		Port:     os.Getenv("SMTP_TEST_PORT"),
+		Username: os.Getenv("SMTP_TEST_USERNAME"),
+		Password: os.Getenv("SMTP_TEST_PASSWORD"),
+		From:     "no-reply@cards.test",
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := mailer.Send(ctx, Message{To: "integration@cards.test", Subject: "Integration test", Body: "It works."}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+}
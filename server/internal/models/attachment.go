@@ -0,0 +1,15 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type Attachment struct {
+	Base
+	FileName    string    `gorm:"not null" json:"file_name"`
+	ContentType string    `gorm:"not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	StorageKey  string    `gorm:"not null;uniqueIndex" json:"-"`
+	CardID      uuid.UUID `gorm:"type:uuid;not null;index" json:"card_id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+}
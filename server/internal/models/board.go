@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+const DefaultBoardName = "My board"
+
+var DefaultBoardColumns = []string{"undone", "doing", "done"}
+
+type Board struct {
+	Base
+	Name      string    `gorm:"not null" json:"name"`
+	IsDefault bool      `gorm:"not null;default:false" json:"is_default"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Columns   []Column  `gorm:"foreignKey:BoardID;references:ID;constraint:OnDelete:CASCADE" json:"columns"`
+
+	// WorkspaceID is only nil on boards created before workspaces existed
+	// that have not been migrated yet.
+	WorkspaceID *uuid.UUID `gorm:"type:uuid;index" json:"workspace_id"`
+}
+
+type Column struct {
+	Base
+	Name     string    `gorm:"not null" json:"name"`
+	Position int       `gorm:"not null;default:0" json:"position"`
+	BoardID  uuid.UUID `gorm:"type:uuid;not null;index" json:"board_id"`
+}
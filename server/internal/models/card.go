@@ -1,14 +1,43 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Card struct {
 	Base
-	Title   string    `gorm:"not null" json:"title"`
-	Content string    `gorm:"not null" json:"content"`
-	Status  string    `gorm:"not null" json:"status"`
-	UserID  uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
-	User    *User     `gorm:"foreignKey:UserID;references:ID" json:"user"`
+	Title    string     `gorm:"not null" json:"title"`
+	Content  string     `gorm:"not null" json:"content"`
+	Status   string     `gorm:"not null" json:"status"`
+	UserID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User     *User      `gorm:"foreignKey:UserID;references:ID" json:"-"`
+	BoardID  *uuid.UUID `gorm:"type:uuid;index" json:"board_id"`
+	ColumnID *uuid.UUID `gorm:"type:uuid;index;index:idx_cards_column_rank,priority:1" json:"column_id"`
+	Rank     string     `gorm:"type:text collate \"C\";not null;default:'';index:idx_cards_column_rank,priority:2" json:"rank"`
+	DueAt    *time.Time `gorm:"index:idx_cards_workspace_due,priority:2" json:"due_at"`
+	Priority Priority   `gorm:"type:smallint;not null;default:0;index:idx_cards_workspace_priority,priority:2" json:"priority"`
+	Tags     []Tag      `gorm:"many2many:card_tags;constraint:OnDelete:CASCADE" json:"tags"`
+
+	// WorkspaceID follows the card's board. It is only nil on cards created
+	// before workspaces existed that have not been migrated yet.
+	WorkspaceID *uuid.UUID `gorm:"type:uuid;index:idx_cards_workspace_due,priority:1;index:idx_cards_workspace_priority,priority:1" json:"workspace_id"`
+
+	// Archived cards are hidden from listings independently of their status.
+	ArchivedAt *time.Time `gorm:"index" json:"archived_at,omitempty"`
+
+	// Deleted cards stay in the trash until restored or purged.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	Checklist         []ChecklistItem   `gorm:"foreignKey:CardID;references:ID;constraint:OnDelete:CASCADE" json:"checklist"`
+	ChecklistProgress ChecklistProgress `gorm:"-" json:"checklist_progress"`
+}
+
+// AfterFind runs after associations are preloaded, so the progress reflects
+// the checklist whenever it was loaded alongside the card.
+func (c *Card) AfterFind(tx *gorm.DB) error {
+	c.ChecklistProgress = NewChecklistProgress(c.Checklist)
+	return nil
 }
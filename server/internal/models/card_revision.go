@@ -0,0 +1,61 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CardRevision is an append-only record of who changed which fields of a
+// card. RestoredFromID is set when the change came from restoring an older
+// revision.
+type CardRevision struct {
+	Base
+	CardID         uuid.UUID       `gorm:"type:uuid;not null;index" json:"card_id"`
+	UserID         uuid.UUID       `gorm:"type:uuid;not null" json:"user_id"`
+	Changes        RevisionChanges `gorm:"type:jsonb;not null" json:"changes"`
+	RestoredFromID *uuid.UUID      `gorm:"type:uuid" json:"restored_from_id"`
+}
+
+func (r *CardRevision) BeforeUpdate(tx *gorm.DB) error {
+	return errors.New("card revisions are immutable")
+}
+
+type FieldChange struct {
+	From json.RawMessage `json:"from"`
+	To   json.RawMessage `json:"to"`
+}
+
+// RevisionChanges maps a card field name to its value before and after the
+// change, both encoded as they appear in the card's JSON.
+type RevisionChanges map[string]FieldChange
+
+func (c RevisionChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (c *RevisionChanges) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*c = RevisionChanges{}
+		return nil
+	default:
+		return errors.New("unsupported revision changes value")
+	}
+	return json.Unmarshal(data, c)
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type ChecklistItem struct {
+	Base
+	Text     string    `gorm:"not null" json:"text"`
+	Done     bool      `gorm:"not null;default:false" json:"done"`
+	Position int       `gorm:"not null;default:0" json:"position"`
+	CardID   uuid.UUID `gorm:"type:uuid;not null;index" json:"card_id"`
+}
+
+type ChecklistProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+func NewChecklistProgress(items []ChecklistItem) ChecklistProgress {
+	progress := ChecklistProgress{Total: len(items)}
+	for _, item := range items {
+		if item.Done {
+			progress.Done++
+		}
+	}
+	return progress
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comment threads are one level deep: replies always point at the top-level
+// comment that started the thread. Deleting a comment that others have replied
+// to leaves a tombstone (blank body, DeletedAt set) so the replies survive.
+type Comment struct {
+	Base
+	Body      string     `gorm:"not null" json:"body"`
+	CardID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"card_id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	ParentID  *uuid.UUID `gorm:"type:uuid;index" json:"parent_id"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Replies   []Comment  `gorm:"foreignKey:ParentID;references:ID;constraint:OnDelete:CASCADE" json:"replies,omitempty"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailVerification proves the user can read mail sent to their address:
+// whoever holds the emailed token can mark it verified, once and before it
+// expires. Only a hash of the token is stored.
+type EmailVerification struct {
+	Base
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// Usable reports whether the verification can still be redeemed.
+func (v EmailVerification) Usable(now time.Time) bool {
+	return v.UsedAt == nil && now.Before(v.ExpiresAt)
+}
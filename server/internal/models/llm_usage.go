@@ -0,0 +1,11 @@
+package models
+
+import "github.com/google/uuid"
+
+// LLMUsage records one card generation request, for per-user usage reports.
+type LLMUsage struct {
+	Base
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	CardsGenerated int       `gorm:"not null;default:0" json:"cards_generated"`
+	Succeeded      bool      `gorm:"not null" json:"succeeded"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalAccessToken lets scripts act as a user without their password,
+// limited to its scopes. Only a hash of the token is stored.
+type PersonalAccessToken struct {
+	Base
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string     `gorm:"not null" json:"name"`
+	TokenHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"not null" json:"-"` // space-separated
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the token can still be used.
+func (t PersonalAccessToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+func (t PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// HasScope reports whether the token was granted scope.
+func (t PersonalAccessToken) HasScope(scope string) bool {
+	for _, granted := range t.ScopeList() {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
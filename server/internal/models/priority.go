@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Priority is stored as a small integer so cards can be sorted and indexed by
+// it, but is exposed as a name in JSON.
+type Priority int16
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+var PriorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+func ParsePriority(name string) (Priority, error) {
+	for i, candidate := range PriorityNames {
+		if candidate == name {
+			return Priority(i), nil
+		}
+	}
+	return PriorityNone, fmt.Errorf("invalid priority %q", name)
+}
+
+func (p Priority) String() string {
+	if p < 0 || int(p) >= len(PriorityNames) {
+		return PriorityNames[PriorityNone]
+	}
+	return PriorityNames[p]
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+
+	parsed, err := ParsePriority(name)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
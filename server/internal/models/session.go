@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a sign-in on one device. Its refresh token rotates on every use
+// and only a hash of the current one is stored, so presenting an older one
+// means it leaked and the whole session is revoked.
+type Session struct {
+	Base
+	UserID           uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	RefreshTokenHash string     `gorm:"not null" json:"-"`
+	ExpiresAt        time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the session can still authenticate requests.
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
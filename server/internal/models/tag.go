@@ -0,0 +1,17 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type Tag struct {
+	Base
+	Name  string `gorm:"not null;uniqueIndex:idx_tags_workspace_name" json:"name"`
+	Color string `gorm:"not null;default:'#64748b'" json:"color"`
+	// UserID is the member who created the tag; any member with write
+	// access to the workspace can use, rename or delete it.
+	UserID uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	// WorkspaceID is only nil on tags created before workspaces existed
+	// that have not been migrated yet.
+	WorkspaceID *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_tags_workspace_name" json:"workspace_id"`
+}
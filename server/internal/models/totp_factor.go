@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TOTPFactor is a user's authenticator app. It only counts as a second factor
+// once ConfirmedAt is set, after the user has entered a first code from it.
+type TOTPFactor struct {
+	Base
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Secret      string     `gorm:"not null" json:"-"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	// LastUsedStep is the time step of the last accepted code, so a code
+	// cannot be replayed within its validity window.
+	LastUsedStep int64 `gorm:"not null;default:0" json:"-"`
+}
+
+// Confirmed reports whether the factor is required at login.
+func (f TOTPFactor) Confirmed() bool {
+	return f.ConfirmedAt != nil
+}
+
+// RecoveryCode stands in for a TOTP code once, for users who lost their
+// authenticator. Only a hash of the code is stored.
+type RecoveryCode struct {
+	Base
+	UserID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash string     `gorm:"not null;index" json:"-"`
+	UsedAt   *time.Time `json:"used_at,omitempty"`
+}
@@ -1,19 +1,57 @@
 package models
 
 import (
+	"time"
+
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+// UserRole is a user's role across the whole app, unlike the Role a member
+// holds in a workspace.
+type UserRole string
+
+const (
+	UserRoleUser  UserRole = "user"
+	UserRoleAdmin UserRole = "admin"
+)
+
+func (r UserRole) Valid() bool {
+	return r == UserRoleUser || r == UserRoleAdmin
+}
+
 type User struct {
 	Base
 	Name     string `gorm:"not null" json:"name"`
 	Email    string `gorm:"unique;not null" json:"email"`
-	Password string `gorm:"not null" json:"password"`
-	Cards    []Card `gorm:"foreignKey:UserID;references:ID" json:"cards"`
+	Password string `gorm:"not null" json:"-"`
+	Cards    []Card `gorm:"foreignKey:UserID;references:ID" json:"-"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+
+	Role UserRole `gorm:"type:varchar(16);not null;default:user;index" json:"role"`
+
+	// Disabled users cannot sign in or use their tokens.
+	DisabledAt *time.Time `gorm:"index" json:"disabled_at,omitempty"`
+
+	// PasswordResetRequired is set by an admin; the user cannot sign in with
+	// their password until they reset it.
+	PasswordResetRequired bool `gorm:"not null;default:false" json:"password_reset_required"`
+}
+
+// EmailVerified reports whether the user has confirmed their email address.
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+func (u *User) Disabled() bool {
+	return u.DisabledAt != nil
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
+	if u.Role == "" {
+		u.Role = UserRoleUser
+	}
 	if u.Password != "" {
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
 		if err != nil {
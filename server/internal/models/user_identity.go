@@ -0,0 +1,14 @@
+package models
+
+import "github.com/google/uuid"
+
+// UserIdentity links an account at an external sign-in provider (Google,
+// GitHub, any OpenID Connect issuer) to a user. Subject is the provider's
+// stable ID for the account; the email may change there.
+type UserIdentity struct {
+	Base
+	UserID   uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Provider string    `gorm:"not null;uniqueIndex:idx_user_identity_subject" json:"provider"`
+	Subject  string    `gorm:"not null;uniqueIndex:idx_user_identity_subject" json:"-"`
+	Email    string    `json:"email"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const PersonalWorkspaceName = "Personal"
+
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+	RoleViewer Role = "viewer"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleMember: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+func (r Role) Valid() bool {
+	return roleRanks[r] > 0
+}
+
+// Allows reports whether the role grants at least the access of required.
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[required]
+}
+
+// Outranks reports whether the role is strictly above other.
+func (r Role) Outranks(other Role) bool {
+	return roleRanks[r] > roleRanks[other]
+}
+
+// Workspace groups the boards and cards shared by its members. Every user
+// also has a personal workspace that cannot be shared.
+type Workspace struct {
+	Base
+	Name        string       `gorm:"not null" json:"name"`
+	IsPersonal  bool         `gorm:"not null;default:false" json:"is_personal"`
+	OwnerID     uuid.UUID    `gorm:"type:uuid;not null;index;uniqueIndex:idx_workspaces_personal_owner,where:is_personal" json:"owner_id"`
+	Memberships []Membership `gorm:"foreignKey:WorkspaceID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+type Membership struct {
+	Base
+	WorkspaceID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_memberships_workspace_user,priority:1" json:"workspace_id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_memberships_workspace_user,priority:2" json:"user_id"`
+	Role        Role      `gorm:"type:varchar(16);not null" json:"role"`
+}
+
+// WorkspaceInvitation lets whoever holds the token join the workspace, as long
+// as they are signed in with the invited email. Only a hash of the token is
+// stored.
+type WorkspaceInvitation struct {
+	Base
+	WorkspaceID uuid.UUID  `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	Email       string     `gorm:"not null;index" json:"email"`
+	Role        Role       `gorm:"type:varchar(16);not null" json:"role"`
+	TokenHash   string     `gorm:"not null;uniqueIndex" json:"-"`
+	InvitedByID uuid.UUID  `gorm:"type:uuid;not null" json:"invited_by_id"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+}
@@ -0,0 +1,32 @@
+package params
+
+import (
+	"cards/internal/apperrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UserID returns the ID of the user the auth middleware authenticated. A
+// missing or malformed ID means the request is not authenticated.
+func UserID(c *gin.Context) (uuid.UUID, error) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		return uuid.Nil, apperrors.Unauthorized("invalid user ID")
+	}
+	return userID, nil
+}
+
+// UUID returns the named path parameter as a UUID. A missing or malformed
+// value is a validation error naming the parameter.
+func UUID(c *gin.Context, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		return uuid.Nil, &apperrors.Error{
+			Kind:    apperrors.ErrValidation,
+			Message: name + " must be a valid UUID",
+			Fields:  []apperrors.FieldError{{Field: name, Code: "uuid", Message: "must be a valid UUID"}},
+		}
+	}
+	return id, nil
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlobStoreFromEnv picks the store from STORAGE_DRIVER, defaulting to the
+// local filesystem so development needs no extra services.
+func NewBlobStoreFromEnv() (BlobStore, error) {
+	switch os.Getenv("STORAGE_DRIVER") {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocalBlobStore(dir)
+	case "s3":
+		pathStyle := true
+		if value := os.Getenv("S3_USE_PATH_STYLE"); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, err
+			}
+			pathStyle = parsed
+		}
+		return NewS3BlobStore(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			UsePathStyle:    pathStyle,
+		})
+	default:
+		return nil, errors.New("unknown STORAGE_DRIVER")
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalBlobStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if err := store.Put(ctx, "cards/1/file", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	body, err := store.Get(ctx, "cards/1/file")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "hello" {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	if err := store.Delete(ctx, "cards/1/file"); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := store.Get(ctx, "cards/1/file"); !errors.Is(err, ErrBlobNotFound) {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+	if err := store.Delete(ctx, "cards/1/file"); err != nil {
+		t.Fatalf("expected deleting a missing blob to succeed, got %v", err)
+	}
+}
+
+func TestLocalBlobStore_RejectsKeysOutsideRoot(t *testing.T) {
+	store, err := NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	for _, key := range []string{"", "../escape", "cards/../../escape", "/etc/passwd"} {
+		if err := store.Put(context.Background(), key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+			t.Fatalf("expected key %q to be rejected", key)
+		}
+	}
+}
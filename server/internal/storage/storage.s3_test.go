@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The "get-vanilla" case of the AWS Signature Version 4 test suite.
+func TestSignV4_AWSTestSuite(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	signV4(
+		req,
+		"AKIDEXAMPLE",
+		"wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		"us-east-1",
+		"service",
+		emptyPayloadHash,
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC),
+	)
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != expected {
+		t.Fatalf("unexpected authorization header:\n got %s\nwant %s", got, expected)
+	}
+}
+
+func TestS3BlobStore_Requests(t *testing.T) {
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			if r.Header.Get("Content-Type") != "image/png" || r.ContentLength != 4 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	store, err := NewS3BlobStore(S3Config{Endpoint: server.URL, Bucket: "attachments", AccessKeyID: "key", SecretAccessKey: "secret", UsePathStyle: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "cards/a b", bytes.NewReader([]byte("data")), 4, "image/png"); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, ok := objects["/attachments/cards/a b"]; !ok {
+		t.Fatalf("expected a path-style object, got %v", objects)
+	}
+
+	body, err := store.Get(ctx, "cards/a b")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "data" {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	if err := store.Delete(ctx, "cards/a b"); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := store.Get(ctx, "cards/a b"); !errors.Is(err, ErrBlobNotFound) {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+}
+
+// Runs against a real S3-compatible server, e.g. the minio service from
+// docker-compose.yml:
+//
+//	S3_TEST_ENDPOINT=http://localhost:9000 S3_TEST_BUCKET=attachments \
+//	S3_TEST_ACCESS_KEY_ID=minioadmin S3_TEST_SECRET_ACCESS_KEY=minioadmin go test ./internal/storage
+func TestS3BlobStore_Integration(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT not set")
+	}
+
+	store, err := NewS3BlobStore(S3Config{
+		Endpoint:        endpoint,
+		Region:          os.Getenv("S3_TEST_REGION"),
+		Bucket:          os.Getenv("S3_TEST_BUCKET"),
+		AccessKeyID:     os.Getenv("S3_TEST_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("S3_TEST_SECRET_ACCESS_KEY"),
+		UsePathStyle:    true,
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	ctx := context.Background()
+	key := "storage-test/" + uuid.NewString()
+	if err := store.Put(ctx, key, strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "hello" {
+		t.Fatalf("unexpected content %q", data)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package tags
+
+type ListTagsQuery struct {
+	WorkspaceID string `form:"workspace_id" binding:"omitempty,uuid"`
+}
+
+type CreateTagDTO struct {
+	Name        string `json:"name" binding:"required"`
+	Color       string `json:"color" binding:"omitempty,hexcolor"`
+	WorkspaceID string `json:"workspace_id" binding:"omitempty,uuid"`
+}
+
+type UpdateTagDTO struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color" binding:"omitempty,hexcolor"`
+}
@@ -0,0 +1,119 @@
+package tags
+
+import (
+	"cards/internal/apperrors"
+	"cards/internal/params"
+	"cards/internal/types"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagsHandler interface {
+	List(c *gin.Context)
+	Create(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type tagsHandler struct {
+	Service TagsService
+}
+
+func NewTagsHandler(service TagsService) TagsHandler {
+	return &tagsHandler{Service: service}
+}
+
+func (h *tagsHandler) List(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var query ListTagsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apperrors.Respond(c, "Invalid query parameters", apperrors.Binding(err))
+		return
+	}
+
+	tags, err := h.Service.List(userID, query)
+	if err != nil {
+		apperrors.Respond(c, "Failed to list tags", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Tags listed successfully", tags, nil))
+}
+
+func (h *tagsHandler) Create(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto CreateTagDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	tag, err := h.Service.Create(userID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to create tag", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewApiResponse(http.StatusCreated, "Tag created successfully", tag, nil))
+}
+
+func (h *tagsHandler) Update(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto UpdateTagDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	tagID, err := params.UUID(c, "tagID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid tag ID", err)
+		return
+	}
+
+	tag, err := h.Service.Update(userID, tagID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to update tag", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Tag updated successfully", tag, nil))
+}
+
+func (h *tagsHandler) Delete(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	tagID, err := params.UUID(c, "tagID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid tag ID", err)
+		return
+	}
+
+	tag, err := h.Service.Delete(userID, tagID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to delete tag", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Tag deleted successfully", tag, nil))
+}
@@ -0,0 +1,71 @@
+package tags
+
+import (
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TagsRepository interface {
+	FindByID(uuid.UUID) (models.Tag, error)
+	FindByNames(workspaceID uuid.UUID, names []string) ([]models.Tag, error)
+	ListByWorkspaceID(uuid.UUID) ([]models.Tag, error)
+	Create(*models.Tag) error
+	CreateMultiple([]models.Tag) error
+	Update(*models.Tag) error
+	Delete(uuid.UUID) error
+}
+
+type tagsRepository struct {
+	db *gorm.DB
+}
+
+func NewTagsRepository(db *gorm.DB) TagsRepository {
+	return &tagsRepository{db: db}
+}
+
+func (r *tagsRepository) FindByID(id uuid.UUID) (models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.Where("id = ?", id).First(&tag).Error; err != nil {
+		return models.Tag{}, err
+	}
+	return tag, nil
+}
+
+func (r *tagsRepository) FindByNames(workspaceID uuid.UUID, names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	if err := r.db.Where("workspace_id = ? AND name IN ?", workspaceID, names).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *tagsRepository) ListByWorkspaceID(workspaceID uuid.UUID) ([]models.Tag, error) {
+	var tags []models.Tag
+	if err := r.db.Where("workspace_id = ?", workspaceID).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *tagsRepository) Create(tag *models.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *tagsRepository) CreateMultiple(tags []models.Tag) error {
+	return r.db.Create(&tags).Error
+}
+
+func (r *tagsRepository) Update(tag *models.Tag) error {
+	return r.db.Save(tag).Error
+}
+
+func (r *tagsRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM card_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Tag{Base: models.Base{ID: id}}).Error
+	})
+}
@@ -0,0 +1,22 @@
+package tags
+
+import (
+	"cards/internal/auth"
+	"cards/internal/workspaces"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func RegisterTagsRoutes(appGroup *gin.RouterGroup, db *gorm.DB, authMiddleware auth.Middleware) {
+	repository := NewTagsRepository(db)
+	service := NewTagsService(repository, workspaces.NewWorkspacesService(workspaces.NewWorkspacesRepository(db), nil))
+	handler := NewTagsHandler(service)
+
+	tagsGroup := appGroup.Group("/tags")
+	tagsGroup.Use(authMiddleware.Authenticate())
+	tagsGroup.GET("/list", handler.List)
+	tagsGroup.POST("/create", handler.Create)
+	tagsGroup.PATCH("/update/:tagID", handler.Update)
+	tagsGroup.DELETE("/delete/:tagID", handler.Delete)
+}
@@ -0,0 +1,201 @@
+package tags
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+
+	"cards/internal/apperrors"
+	"cards/internal/authz"
+	"cards/internal/models"
+	"cards/internal/workspaces"
+)
+
+const defaultTagColor = "#64748b"
+
+type TagsService interface {
+	List(userID uuid.UUID, query ListTagsQuery) ([]models.Tag, error)
+	Create(userID uuid.UUID, dto CreateTagDTO) (*models.Tag, error)
+	Update(userID uuid.UUID, tagID uuid.UUID, dto UpdateTagDTO) (*models.Tag, error)
+	Delete(userID uuid.UUID, tagID uuid.UUID) (*models.Tag, error)
+	FindByNames(workspaceID uuid.UUID, names []string) ([]models.Tag, error)
+	Resolve(userID uuid.UUID, workspaceID uuid.UUID, names []string) ([]models.Tag, error)
+}
+
+type tagsService struct {
+	Repository TagsRepository
+	Workspaces workspaces.WorkspacesService
+}
+
+func NewTagsService(repository TagsRepository, workspacesService workspaces.WorkspacesService) TagsService {
+	return &tagsService{Repository: repository, Workspaces: workspacesService}
+}
+
+func (s *tagsService) List(userID uuid.UUID, query ListTagsQuery) ([]models.Tag, error) {
+	workspaceID, err := s.Workspaces.Resolve(userID, query.WorkspaceID, authz.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repository.ListByWorkspaceID(workspaceID)
+}
+
+func (s *tagsService) Create(userID uuid.UUID, dto CreateTagDTO) (*models.Tag, error) {
+	name := strings.TrimSpace(dto.Name)
+	if name == "" {
+		return nil, apperrors.Validation("tag name is required")
+	}
+
+	workspaceID, err := s.Workspaces.Resolve(userID, dto.WorkspaceID, authz.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.Repository.FindByNames(workspaceID, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, apperrors.Conflict("tag already exists")
+	}
+
+	tag := models.Tag{Name: name, Color: dto.Color, UserID: userID, WorkspaceID: &workspaceID}
+	if tag.Color == "" {
+		tag.Color = defaultTagColor
+	}
+	if err := s.Repository.Create(&tag); err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+func (s *tagsService) Update(userID uuid.UUID, tagID uuid.UUID, dto UpdateTagDTO) (*models.Tag, error) {
+	tag, err := s.authorize(userID, tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	if dto.Name != nil {
+		name := strings.TrimSpace(*dto.Name)
+		if name == "" {
+			return nil, apperrors.Validation("tag name is required")
+		}
+		if name != tag.Name {
+			existing, err := s.Repository.FindByNames(*tag.WorkspaceID, []string{name})
+			if err != nil {
+				return nil, err
+			}
+			if len(existing) > 0 {
+				return nil, apperrors.Conflict("tag already exists")
+			}
+			tag.Name = name
+		}
+	}
+	if dto.Color != nil {
+		tag.Color = *dto.Color
+	}
+
+	if err := s.Repository.Update(tag); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (s *tagsService) Delete(userID uuid.UUID, tagID uuid.UUID) (*models.Tag, error) {
+	tag, err := s.authorize(userID, tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Repository.Delete(tagID); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (s *tagsService) FindByNames(workspaceID uuid.UUID, names []string) ([]models.Tag, error) {
+	names = NormalizeNames(names)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	return s.Repository.FindByNames(workspaceID, names)
+}
+
+// Resolve returns the workspace's tags with the given names, creating any that
+// do not exist yet on behalf of the user. Tags come back in the order the
+// names were given. Callers have already checked the user's access to the
+// workspace.
+func (s *tagsService) Resolve(userID uuid.UUID, workspaceID uuid.UUID, names []string) ([]models.Tag, error) {
+	names = NormalizeNames(names)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	existing, err := s.Repository.FindByNames(workspaceID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]models.Tag, len(existing))
+	for _, tag := range existing {
+		byName[tag.Name] = tag
+	}
+
+	var missing []models.Tag
+	for _, name := range names {
+		if _, ok := byName[name]; !ok {
+			missing = append(missing, models.Tag{Name: name, Color: defaultTagColor, UserID: userID, WorkspaceID: &workspaceID})
+		}
+	}
+	if len(missing) > 0 {
+		if err := s.Repository.CreateMultiple(missing); err != nil {
+			return nil, err
+		}
+		for _, tag := range missing {
+			byName[tag.Name] = tag
+		}
+	}
+
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, byName[name])
+	}
+	return tags, nil
+}
+
+// authorize returns the tag when the user may change it, which any member
+// with write access to the tag's workspace may do.
+func (s *tagsService) authorize(userID uuid.UUID, tagID uuid.UUID) (*models.Tag, error) {
+	tag, err := s.Repository.FindByID(tagID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.WorkspaceID == nil {
+		return nil, apperrors.NotFound("tag not found")
+	}
+
+	if _, err := s.Workspaces.Authorize(userID, *tag.WorkspaceID, authz.Write); err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// NormalizeNames trims tag names and drops blanks and duplicates.
+func NormalizeNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	var normalized []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
@@ -0,0 +1,243 @@
+package tags
+
+import (
+	"errors"
+	"testing"
+
+	"cards/internal/apperrors"
+	"cards/internal/authz"
+	"cards/internal/models"
+	"cards/internal/workspaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeTagsRepository struct {
+	tags []models.Tag
+
+	created []models.Tag
+	updated *models.Tag
+}
+
+func (r *fakeTagsRepository) FindByID(id uuid.UUID) (models.Tag, error) {
+	for _, tag := range r.tags {
+		if tag.ID == id {
+			return tag, nil
+		}
+	}
+	return models.Tag{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTagsRepository) FindByNames(workspaceID uuid.UUID, names []string) ([]models.Tag, error) {
+	var found []models.Tag
+	for _, tag := range r.tags {
+		for _, name := range names {
+			if *tag.WorkspaceID == workspaceID && tag.Name == name {
+				found = append(found, tag)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeTagsRepository) ListByWorkspaceID(workspaceID uuid.UUID) ([]models.Tag, error) {
+	var found []models.Tag
+	for _, tag := range r.tags {
+		if *tag.WorkspaceID == workspaceID {
+			found = append(found, tag)
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeTagsRepository) Create(tag *models.Tag) error {
+	tag.ID = uuid.New()
+	r.created = append(r.created, *tag)
+	return nil
+}
+
+func (r *fakeTagsRepository) CreateMultiple(tags []models.Tag) error {
+	for i := range tags {
+		tags[i].ID = uuid.New()
+	}
+	r.created = append(r.created, tags...)
+	return nil
+}
+
+func (r *fakeTagsRepository) Update(tag *models.Tag) error {
+	r.updated = tag
+	return nil
+}
+
+func (r *fakeTagsRepository) Delete(id uuid.UUID) error {
+	return nil
+}
+
+// fakeWorkspacesService gives every user a personal workspace of their own;
+// shared memberships are added with join.
+type fakeWorkspacesService struct {
+	workspaces.WorkspacesService
+	memberships []models.Membership
+}
+
+func (f *fakeWorkspacesService) join(workspaceID uuid.UUID, userID uuid.UUID, role models.Role) {
+	f.memberships = append(f.memberships, models.Membership{WorkspaceID: workspaceID, UserID: userID, Role: role})
+}
+
+// personalID derives a stable personal workspace ID from the user ID.
+func personalID(userID uuid.UUID) uuid.UUID {
+	return uuid.NewSHA1(userID, []byte("personal"))
+}
+
+func (f *fakeWorkspacesService) Authorize(userID uuid.UUID, workspaceID uuid.UUID, action authz.Action) (authz.Subject, error) {
+	memberships := []models.Membership{{WorkspaceID: personalID(userID), UserID: userID, Role: models.RoleOwner}}
+	for _, membership := range f.memberships {
+		if membership.UserID == userID {
+			memberships = append(memberships, membership)
+		}
+	}
+	subject := authz.NewSubject(userID, memberships)
+	if err := authz.Can(subject, action, authz.Workspace(workspaceID)); err != nil {
+		return authz.Subject{}, err
+	}
+	return subject, nil
+}
+
+func (f *fakeWorkspacesService) Resolve(userID uuid.UUID, workspaceID string, action authz.Action) (uuid.UUID, error) {
+	if workspaceID == "" {
+		return personalID(userID), nil
+	}
+	id := uuid.MustParse(workspaceID)
+	if _, err := f.Authorize(userID, id, action); err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+func TestTagsService_Create(t *testing.T) {
+	userID := uuid.New()
+	workspaceID := personalID(userID)
+
+	t.Run("rejects duplicate names", func(t *testing.T) {
+		repo := &fakeTagsRepository{tags: []models.Tag{{Name: "bug", UserID: uuid.New(), WorkspaceID: &workspaceID}}}
+		svc := NewTagsService(repo, &fakeWorkspacesService{})
+
+		if _, err := svc.Create(userID, CreateTagDTO{Name: " bug "}); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("creates with default color", func(t *testing.T) {
+		otherWorkspaceID := uuid.New()
+		repo := &fakeTagsRepository{tags: []models.Tag{{Name: "bug", UserID: uuid.New(), WorkspaceID: &otherWorkspaceID}}}
+		svc := NewTagsService(repo, &fakeWorkspacesService{})
+
+		tag, err := svc.Create(userID, CreateTagDTO{Name: "bug"})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if tag.Color != defaultTagColor || tag.UserID != userID || *tag.WorkspaceID != workspaceID {
+			t.Fatalf("unexpected tag: %+v", tag)
+		}
+	})
+
+	t.Run("rejects viewers of the workspace", func(t *testing.T) {
+		sharedID := uuid.New()
+		workspacesSvc := &fakeWorkspacesService{}
+		workspacesSvc.join(sharedID, userID, models.RoleViewer)
+		svc := NewTagsService(&fakeTagsRepository{}, workspacesSvc)
+
+		_, err := svc.Create(userID, CreateTagDTO{Name: "bug", WorkspaceID: sharedID.String()})
+		if !errors.Is(err, apperrors.ErrForbidden) {
+			t.Fatalf("expected forbidden error, got %v", err)
+		}
+	})
+}
+
+func TestTagsService_Update(t *testing.T) {
+	userID, memberID := uuid.New(), uuid.New()
+	workspaceID := uuid.New()
+	workspacesSvc := &fakeWorkspacesService{}
+	workspacesSvc.join(workspaceID, userID, models.RoleOwner)
+	workspacesSvc.join(workspaceID, memberID, models.RoleMember)
+
+	tag := models.Tag{Base: models.Base{ID: uuid.New()}, Name: "bug", UserID: userID, WorkspaceID: &workspaceID}
+	other := models.Tag{Base: models.Base{ID: uuid.New()}, Name: "ui", UserID: userID, WorkspaceID: &workspaceID}
+
+	t.Run("rejects users outside the workspace", func(t *testing.T) {
+		svc := NewTagsService(&fakeTagsRepository{tags: []models.Tag{tag}}, workspacesSvc)
+
+		_, err := svc.Update(uuid.New(), tag.ID, UpdateTagDTO{})
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("rejects renaming onto an existing tag", func(t *testing.T) {
+		svc := NewTagsService(&fakeTagsRepository{tags: []models.Tag{tag, other}}, workspacesSvc)
+
+		name := "ui"
+		if _, err := svc.Update(userID, tag.ID, UpdateTagDTO{Name: &name}); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("renames", func(t *testing.T) {
+		repo := &fakeTagsRepository{tags: []models.Tag{tag, other}}
+		svc := NewTagsService(repo, workspacesSvc)
+
+		name := "defect"
+		if _, err := svc.Update(userID, tag.ID, UpdateTagDTO{Name: &name}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.updated == nil || repo.updated.Name != "defect" {
+			t.Fatalf("unexpected updated tag: %+v", repo.updated)
+		}
+	})
+
+	t.Run("lets other members rename", func(t *testing.T) {
+		repo := &fakeTagsRepository{tags: []models.Tag{tag}}
+		svc := NewTagsService(repo, workspacesSvc)
+
+		name := "defect"
+		if _, err := svc.Update(memberID, tag.ID, UpdateTagDTO{Name: &name}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if repo.updated == nil || repo.updated.Name != "defect" {
+			t.Fatalf("unexpected updated tag: %+v", repo.updated)
+		}
+	})
+
+	t.Run("returns not found for unknown tags", func(t *testing.T) {
+		svc := NewTagsService(&fakeTagsRepository{}, workspacesSvc)
+
+		if _, err := svc.Update(userID, uuid.New(), UpdateTagDTO{}); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestTagsService_Resolve(t *testing.T) {
+	userID := uuid.New()
+	workspaceID, otherWorkspaceID := uuid.New(), uuid.New()
+	repo := &fakeTagsRepository{tags: []models.Tag{
+		{Base: models.Base{ID: uuid.New()}, Name: "bug", UserID: uuid.New(), WorkspaceID: &workspaceID},
+		{Base: models.Base{ID: uuid.New()}, Name: "new", UserID: userID, WorkspaceID: &otherWorkspaceID},
+	}}
+	svc := NewTagsService(repo, &fakeWorkspacesService{})
+
+	got, err := svc.Resolve(userID, workspaceID, []string{"new", " bug", "", "new"})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "new" || got[1].Name != "bug" {
+		t.Fatalf("unexpected tags: %+v", got)
+	}
+	if got[0].ID == uuid.Nil {
+		t.Fatalf("expected created tag to have an ID")
+	}
+	if len(repo.created) != 1 || repo.created[0].Name != "new" || *repo.created[0].WorkspaceID != workspaceID {
+		t.Fatalf("expected only %q to be created in the workspace, got %+v", "new", repo.created)
+	}
+}
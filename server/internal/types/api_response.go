@@ -1,12 +1,22 @@
 package types
 
 type ApiResponse struct {
-	Status  int    `json:"status" example:"200"`
-	Message string `json:"message,omitempty" example:"Operation successful"`
-	Data    any    `json:"data,omitempty"`
-	Error   any    `json:"error,omitempty"`
+	Status  int       `json:"status" example:"200"`
+	Message string    `json:"message,omitempty" example:"Operation successful"`
+	Data    any       `json:"data,omitempty"`
+	Meta    *PageMeta `json:"meta,omitempty"`
+	Error   any       `json:"error,omitempty"`
+}
+
+type PageMeta struct {
+	NextCursor *string `json:"next_cursor"`
+	Total      int64   `json:"total"`
 }
 
 func NewApiResponse(status int, message string, data any, err any) ApiResponse {
 	return ApiResponse{Status: status, Message: message, Data: data, Error: err}
 }
+
+func NewPaginatedApiResponse(status int, message string, data any, meta PageMeta) ApiResponse {
+	return ApiResponse{Status: status, Message: message, Data: data, Meta: &meta}
+}
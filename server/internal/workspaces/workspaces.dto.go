@@ -0,0 +1,45 @@
+package workspaces
+
+import (
+	"time"
+
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	invitationTTL         = 7 * 24 * time.Hour
+	defaultInvitationURL  = "http://localhost:5173/accept-invitation"
+	invitationMailTimeout = 30 * time.Second
+)
+
+type CreateWorkspaceDTO struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type UpdateWorkspaceDTO struct {
+	Name *string `json:"name" binding:"omitempty,min=1"`
+}
+
+type WorkspaceResponseDTO struct {
+	models.Workspace
+	Role models.Role `json:"role"`
+}
+
+type MemberDTO struct {
+	UserID   uuid.UUID   `json:"user_id"`
+	Name     string      `json:"name"`
+	Email    string      `json:"email"`
+	Role     models.Role `json:"role"`
+	JoinedAt time.Time   `json:"joined_at"`
+}
+
+type UpdateMemberDTO struct {
+	Role models.Role `json:"role" binding:"required,oneof=admin member viewer"`
+}
+
+type CreateInvitationDTO struct {
+	Email string      `json:"email" binding:"required,email"`
+	Role  models.Role `json:"role" binding:"required,oneof=admin member viewer"`
+}
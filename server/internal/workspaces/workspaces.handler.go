@@ -0,0 +1,304 @@
+package workspaces
+
+import (
+	"cards/internal/apperrors"
+	"cards/internal/params"
+	"cards/internal/types"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkspacesHandler interface {
+	List(c *gin.Context)
+	GetByID(c *gin.Context)
+	Create(c *gin.Context)
+	Update(c *gin.Context)
+	ListMembers(c *gin.Context)
+	UpdateMember(c *gin.Context)
+	RemoveMember(c *gin.Context)
+	ListInvitations(c *gin.Context)
+	Invite(c *gin.Context)
+	RevokeInvitation(c *gin.Context)
+	AcceptInvitation(c *gin.Context)
+}
+
+type workspacesHandler struct {
+	Service WorkspacesService
+}
+
+func NewWorkspacesHandler(service WorkspacesService) WorkspacesHandler {
+	return &workspacesHandler{Service: service}
+}
+
+func (h *workspacesHandler) List(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	workspaces, err := h.Service.List(userID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to list workspaces", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Workspaces listed successfully", workspaces, nil))
+}
+
+func (h *workspacesHandler) GetByID(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	workspaceID, err := params.UUID(c, "workspaceID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid workspace ID", err)
+		return
+	}
+
+	workspace, err := h.Service.GetByID(userID, workspaceID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to get workspace", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Workspace retrieved successfully", workspace, nil))
+}
+
+func (h *workspacesHandler) Create(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto CreateWorkspaceDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	workspace, err := h.Service.Create(userID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to create workspace", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewApiResponse(http.StatusCreated, "Workspace created successfully", workspace, nil))
+}
+
+func (h *workspacesHandler) Update(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto UpdateWorkspaceDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	workspaceID, err := params.UUID(c, "workspaceID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid workspace ID", err)
+		return
+	}
+
+	workspace, err := h.Service.Update(userID, workspaceID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to update workspace", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Workspace updated successfully", workspace, nil))
+}
+
+func (h *workspacesHandler) ListMembers(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	workspaceID, err := params.UUID(c, "workspaceID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid workspace ID", err)
+		return
+	}
+
+	members, err := h.Service.ListMembers(userID, workspaceID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to list members", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Members listed successfully", members, nil))
+}
+
+func (h *workspacesHandler) UpdateMember(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto UpdateMemberDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	workspaceID, err := params.UUID(c, "workspaceID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid workspace ID", err)
+		return
+	}
+
+	memberID, err := params.UUID(c, "memberID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid member ID", err)
+		return
+	}
+
+	membership, err := h.Service.UpdateMember(userID, workspaceID, memberID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to update member", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Member updated successfully", membership, nil))
+}
+
+func (h *workspacesHandler) RemoveMember(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	workspaceID, err := params.UUID(c, "workspaceID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid workspace ID", err)
+		return
+	}
+
+	memberID, err := params.UUID(c, "memberID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid member ID", err)
+		return
+	}
+
+	membership, err := h.Service.RemoveMember(userID, workspaceID, memberID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to remove member", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Member removed successfully", membership, nil))
+}
+
+func (h *workspacesHandler) ListInvitations(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	workspaceID, err := params.UUID(c, "workspaceID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid workspace ID", err)
+		return
+	}
+
+	invitations, err := h.Service.ListInvitations(userID, workspaceID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to list invitations", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Invitations listed successfully", invitations, nil))
+}
+
+func (h *workspacesHandler) Invite(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	var dto CreateInvitationDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apperrors.Respond(c, "Invalid request payload", apperrors.Binding(err))
+		return
+	}
+
+	workspaceID, err := params.UUID(c, "workspaceID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid workspace ID", err)
+		return
+	}
+
+	invitation, err := h.Service.Invite(userID, workspaceID, dto)
+	if err != nil {
+		apperrors.Respond(c, "Failed to create invitation", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewApiResponse(http.StatusCreated, "Invitation created successfully", invitation, nil))
+}
+
+func (h *workspacesHandler) RevokeInvitation(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	workspaceID, err := params.UUID(c, "workspaceID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid workspace ID", err)
+		return
+	}
+
+	invitationID, err := params.UUID(c, "invitationID")
+	if err != nil {
+		apperrors.Respond(c, "Invalid invitation ID", err)
+		return
+	}
+
+	invitation, err := h.Service.RevokeInvitation(userID, workspaceID, invitationID)
+	if err != nil {
+		apperrors.Respond(c, "Failed to revoke invitation", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Invitation revoked successfully", invitation, nil))
+}
+
+func (h *workspacesHandler) AcceptInvitation(c *gin.Context) {
+	userID, err := params.UserID(c)
+	if err != nil {
+		apperrors.Respond(c, "Unauthorized access", err)
+		return
+	}
+
+	token := c.Param("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, types.NewApiResponse(http.StatusBadRequest, "Invitation token is required", nil, "Invitation token is empty"))
+		return
+	}
+
+	membership, err := h.Service.AcceptInvitation(userID, token)
+	if err != nil {
+		apperrors.Respond(c, "Failed to accept invitation", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewApiResponse(http.StatusOK, "Invitation accepted successfully", membership, nil))
+}
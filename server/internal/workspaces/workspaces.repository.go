@@ -0,0 +1,177 @@
+package workspaces
+
+import (
+	"time"
+
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WorkspacesRepository interface {
+	FindByID(uuid.UUID) (models.Workspace, error)
+	FindPersonalByUserID(uuid.UUID) (models.Workspace, error)
+	ListByUserID(uuid.UUID) ([]WorkspaceResponseDTO, error)
+	Create(*models.Workspace) error
+	Update(*models.Workspace) error
+	FindMembership(workspaceID uuid.UUID, userID uuid.UUID) (models.Membership, error)
+	ListMemberships(userID uuid.UUID) ([]models.Membership, error)
+	ListMembers(workspaceID uuid.UUID) ([]MemberDTO, error)
+	UpdateMembership(*models.Membership) error
+	DeleteMembership(uuid.UUID) error
+	FindUser(userID uuid.UUID) (models.User, error)
+	CreateInvitation(*models.WorkspaceInvitation) error
+	FindInvitationByID(uuid.UUID) (models.WorkspaceInvitation, error)
+	FindInvitationByTokenHash(string) (models.WorkspaceInvitation, error)
+	ListPendingInvitations(workspaceID uuid.UUID) ([]models.WorkspaceInvitation, error)
+	DeleteInvitation(uuid.UUID) error
+	AcceptInvitation(invitation *models.WorkspaceInvitation, membership *models.Membership) error
+}
+
+type workspacesRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspacesRepository(db *gorm.DB) WorkspacesRepository {
+	return &workspacesRepository{db: db}
+}
+
+func (r *workspacesRepository) FindByID(id uuid.UUID) (models.Workspace, error) {
+	var workspace models.Workspace
+	if err := r.db.Where("id = ?", id).First(&workspace).Error; err != nil {
+		return models.Workspace{}, err
+	}
+	return workspace, nil
+}
+
+func (r *workspacesRepository) FindPersonalByUserID(userID uuid.UUID) (models.Workspace, error) {
+	var workspace models.Workspace
+	if err := r.db.Where("owner_id = ? AND is_personal = ?", userID, true).First(&workspace).Error; err != nil {
+		return models.Workspace{}, err
+	}
+	return workspace, nil
+}
+
+func (r *workspacesRepository) ListByUserID(userID uuid.UUID) ([]WorkspaceResponseDTO, error) {
+	var rows []struct {
+		models.Workspace
+		Role models.Role
+	}
+	err := r.db.Model(&models.Workspace{}).
+		Select("workspaces.*, memberships.role").
+		Joins("JOIN memberships ON memberships.workspace_id = workspaces.id").
+		Where("memberships.user_id = ?", userID).
+		Order("workspaces.is_personal DESC, workspaces.created_at, workspaces.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces := make([]WorkspaceResponseDTO, len(rows))
+	for i, row := range rows {
+		workspaces[i] = WorkspaceResponseDTO{Workspace: row.Workspace, Role: row.Role}
+	}
+	return workspaces, nil
+}
+
+// Create also creates the memberships set on the workspace, so a workspace
+// never exists without its owner.
+func (r *workspacesRepository) Create(workspace *models.Workspace) error {
+	return r.db.Create(workspace).Error
+}
+
+func (r *workspacesRepository) Update(workspace *models.Workspace) error {
+	return r.db.Omit("Memberships").Save(workspace).Error
+}
+
+func (r *workspacesRepository) FindMembership(workspaceID uuid.UUID, userID uuid.UUID) (models.Membership, error) {
+	var membership models.Membership
+	if err := r.db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&membership).Error; err != nil {
+		return models.Membership{}, err
+	}
+	return membership, nil
+}
+
+func (r *workspacesRepository) ListMemberships(userID uuid.UUID) ([]models.Membership, error) {
+	var memberships []models.Membership
+	if err := r.db.Where("user_id = ?", userID).Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+func (r *workspacesRepository) ListMembers(workspaceID uuid.UUID) ([]MemberDTO, error) {
+	var members []MemberDTO
+	err := r.db.Model(&models.Membership{}).
+		Select("memberships.user_id, users.name, users.email, memberships.role, memberships.created_at AS joined_at").
+		Joins("JOIN users ON users.id = memberships.user_id").
+		Where("memberships.workspace_id = ?", workspaceID).
+		Order("memberships.created_at, memberships.id").
+		Scan(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *workspacesRepository) UpdateMembership(membership *models.Membership) error {
+	return r.db.Save(membership).Error
+}
+
+func (r *workspacesRepository) DeleteMembership(id uuid.UUID) error {
+	return r.db.Delete(&models.Membership{Base: models.Base{ID: id}}).Error
+}
+
+func (r *workspacesRepository) FindUser(userID uuid.UUID) (models.User, error) {
+	var user models.User
+	if err := r.db.Select("id", "email", "email_verified_at").Where("id = ?", userID).First(&user).Error; err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+func (r *workspacesRepository) CreateInvitation(invitation *models.WorkspaceInvitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *workspacesRepository) FindInvitationByID(id uuid.UUID) (models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+	if err := r.db.Where("id = ?", id).First(&invitation).Error; err != nil {
+		return models.WorkspaceInvitation{}, err
+	}
+	return invitation, nil
+}
+
+func (r *workspacesRepository) FindInvitationByTokenHash(tokenHash string) (models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&invitation).Error; err != nil {
+		return models.WorkspaceInvitation{}, err
+	}
+	return invitation, nil
+}
+
+func (r *workspacesRepository) ListPendingInvitations(workspaceID uuid.UUID) ([]models.WorkspaceInvitation, error) {
+	var invitations []models.WorkspaceInvitation
+	err := r.db.
+		Where("workspace_id = ? AND accepted_at IS NULL AND expires_at > ?", workspaceID, time.Now()).
+		Order("created_at").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *workspacesRepository) DeleteInvitation(id uuid.UUID) error {
+	return r.db.Delete(&models.WorkspaceInvitation{Base: models.Base{ID: id}}).Error
+}
+
+func (r *workspacesRepository) AcceptInvitation(invitation *models.WorkspaceInvitation, membership *models.Membership) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(membership).Error; err != nil {
+			return err
+		}
+		return tx.Model(invitation).UpdateColumn("accepted_at", invitation.AcceptedAt).Error
+	})
+}
@@ -0,0 +1,29 @@
+package workspaces
+
+import (
+	"cards/internal/auth"
+	"cards/internal/mail"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func RegisterWorkspacesRoutes(appGroup *gin.RouterGroup, db *gorm.DB, mailer mail.Mailer, authMiddleware auth.Middleware) {
+	repository := NewWorkspacesRepository(db)
+	service := NewWorkspacesService(repository, mailer)
+	handler := NewWorkspacesHandler(service)
+
+	workspacesGroup := appGroup.Group("/workspaces")
+	workspacesGroup.Use(authMiddleware.Authenticate())
+	workspacesGroup.GET("/list", handler.List)
+	workspacesGroup.GET("/by_id/:workspaceID", handler.GetByID)
+	workspacesGroup.POST("/create", handler.Create)
+	workspacesGroup.PATCH("/update/:workspaceID", handler.Update)
+	workspacesGroup.GET("/:workspaceID/members", handler.ListMembers)
+	workspacesGroup.PATCH("/:workspaceID/members/:memberID", handler.UpdateMember)
+	workspacesGroup.DELETE("/:workspaceID/members/:memberID", handler.RemoveMember)
+	workspacesGroup.GET("/:workspaceID/invitations", handler.ListInvitations)
+	workspacesGroup.POST("/:workspaceID/invitations", authMiddleware.RequireVerifiedEmail(), handler.Invite)
+	workspacesGroup.DELETE("/:workspaceID/invitations/:invitationID", handler.RevokeInvitation)
+	workspacesGroup.POST("/invitations/:token/accept", authMiddleware.RequireVerifiedEmail(), handler.AcceptInvitation)
+}
@@ -0,0 +1,403 @@
+package workspaces
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"cards/internal/apperrors"
+	"cards/internal/authz"
+	"cards/internal/mail"
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WorkspacesService interface {
+	List(userID uuid.UUID) ([]WorkspaceResponseDTO, error)
+	GetByID(userID uuid.UUID, workspaceID uuid.UUID) (*WorkspaceResponseDTO, error)
+	EnsurePersonal(userID uuid.UUID) (*models.Workspace, error)
+	Create(userID uuid.UUID, dto CreateWorkspaceDTO) (*WorkspaceResponseDTO, error)
+	Update(userID uuid.UUID, workspaceID uuid.UUID, dto UpdateWorkspaceDTO) (*WorkspaceResponseDTO, error)
+	Subject(userID uuid.UUID) (authz.Subject, error)
+	Authorize(userID uuid.UUID, workspaceID uuid.UUID, action authz.Action) (authz.Subject, error)
+	Resolve(userID uuid.UUID, workspaceID string, action authz.Action) (uuid.UUID, error)
+	ListMembers(userID uuid.UUID, workspaceID uuid.UUID) ([]MemberDTO, error)
+	UpdateMember(userID uuid.UUID, workspaceID uuid.UUID, memberID uuid.UUID, dto UpdateMemberDTO) (*models.Membership, error)
+	RemoveMember(userID uuid.UUID, workspaceID uuid.UUID, memberID uuid.UUID) (*models.Membership, error)
+	Invite(userID uuid.UUID, workspaceID uuid.UUID, dto CreateInvitationDTO) (*models.WorkspaceInvitation, error)
+	ListInvitations(userID uuid.UUID, workspaceID uuid.UUID) ([]models.WorkspaceInvitation, error)
+	RevokeInvitation(userID uuid.UUID, workspaceID uuid.UUID, invitationID uuid.UUID) (*models.WorkspaceInvitation, error)
+	AcceptInvitation(userID uuid.UUID, token string) (*models.Membership, error)
+}
+
+type workspacesService struct {
+	Repository    WorkspacesRepository
+	Mailer        mail.Mailer
+	InvitationURL string
+}
+
+// NewWorkspacesService builds the workspaces service. The mailer only sends
+// invitations, so callers that just check workspace access may pass nil.
+func NewWorkspacesService(repository WorkspacesRepository, mailer mail.Mailer) WorkspacesService {
+	invitationURL := os.Getenv("INVITATION_URL")
+	if invitationURL == "" {
+		invitationURL = defaultInvitationURL
+	}
+	return &workspacesService{Repository: repository, Mailer: mailer, InvitationURL: invitationURL}
+}
+
+func (s *workspacesService) List(userID uuid.UUID) ([]WorkspaceResponseDTO, error) {
+	if _, err := s.EnsurePersonal(userID); err != nil {
+		return nil, err
+	}
+
+	return s.Repository.ListByUserID(userID)
+}
+
+func (s *workspacesService) GetByID(userID uuid.UUID, workspaceID uuid.UUID) (*WorkspaceResponseDTO, error) {
+	subject, err := s.Authorize(userID, workspaceID, authz.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.Repository.FindByID(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WorkspaceResponseDTO{Workspace: workspace, Role: subject.RoleIn(workspaceID)}, nil
+}
+
+// EnsurePersonal returns the user's personal workspace, creating it on first
+// use. Cards and boards that are not shared live there.
+func (s *workspacesService) EnsurePersonal(userID uuid.UUID) (*models.Workspace, error) {
+	workspace, err := s.Repository.FindPersonalByUserID(userID)
+	if err == nil {
+		return &workspace, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	workspace = newWorkspace(userID, models.PersonalWorkspaceName)
+	workspace.IsPersonal = true
+	if err := s.Repository.Create(&workspace); err != nil {
+		return nil, err
+	}
+
+	return &workspace, nil
+}
+
+func (s *workspacesService) Create(userID uuid.UUID, dto CreateWorkspaceDTO) (*WorkspaceResponseDTO, error) {
+	workspace := newWorkspace(userID, dto.Name)
+	if err := s.Repository.Create(&workspace); err != nil {
+		return nil, err
+	}
+
+	return &WorkspaceResponseDTO{Workspace: workspace, Role: models.RoleOwner}, nil
+}
+
+func (s *workspacesService) Update(userID uuid.UUID, workspaceID uuid.UUID, dto UpdateWorkspaceDTO) (*WorkspaceResponseDTO, error) {
+	subject, err := s.Authorize(userID, workspaceID, authz.Manage)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.Repository.FindByID(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if dto.Name != nil {
+		workspace.Name = *dto.Name
+	}
+
+	if err := s.Repository.Update(&workspace); err != nil {
+		return nil, err
+	}
+
+	return &WorkspaceResponseDTO{Workspace: workspace, Role: subject.RoleIn(workspaceID)}, nil
+}
+
+// Subject loads the user's memberships for authorization checks.
+func (s *workspacesService) Subject(userID uuid.UUID) (authz.Subject, error) {
+	memberships, err := s.Repository.ListMemberships(userID)
+	if err != nil {
+		return authz.Subject{}, err
+	}
+	return authz.NewSubject(userID, memberships), nil
+}
+
+// Authorize checks that the user may perform the action on the workspace and
+// returns the subject it checked, for callers that need the user's role.
+func (s *workspacesService) Authorize(userID uuid.UUID, workspaceID uuid.UUID, action authz.Action) (authz.Subject, error) {
+	subject, err := s.Subject(userID)
+	if err != nil {
+		return authz.Subject{}, err
+	}
+
+	if err := authz.Can(subject, action, authz.Workspace(workspaceID)); err != nil {
+		return authz.Subject{}, err
+	}
+
+	return subject, nil
+}
+
+// Resolve picks the workspace a request is scoped to: the given one, when the
+// user may perform the action in it, or else the user's personal workspace.
+func (s *workspacesService) Resolve(userID uuid.UUID, workspaceID string, action authz.Action) (uuid.UUID, error) {
+	if workspaceID == "" {
+		workspace, err := s.EnsurePersonal(userID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return workspace.ID, nil
+	}
+
+	id, err := uuid.Parse(workspaceID)
+	if err != nil {
+		return uuid.Nil, apperrors.Validation("invalid workspace ID")
+	}
+	if _, err := s.Authorize(userID, id, action); err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+func (s *workspacesService) ListMembers(userID uuid.UUID, workspaceID uuid.UUID) ([]MemberDTO, error) {
+	if _, err := s.Authorize(userID, workspaceID, authz.Read); err != nil {
+		return nil, err
+	}
+
+	return s.Repository.ListMembers(workspaceID)
+}
+
+// UpdateMember changes a member's role. Admins may only manage members below
+// them and grant roles below their own; the owner can also appoint admins.
+func (s *workspacesService) UpdateMember(userID uuid.UUID, workspaceID uuid.UUID, memberID uuid.UUID, dto UpdateMemberDTO) (*models.Membership, error) {
+	actor, err := s.Authorize(userID, workspaceID, authz.Manage)
+	if err != nil {
+		return nil, err
+	}
+
+	membership, err := s.Repository.FindMembership(workspaceID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	role := actor.RoleIn(workspaceID)
+	if !role.Outranks(membership.Role) || !role.Outranks(dto.Role) {
+		return nil, apperrors.ErrForbidden
+	}
+
+	membership.Role = dto.Role
+	if err := s.Repository.UpdateMembership(&membership); err != nil {
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+// RemoveMember removes someone from the workspace. Members may always leave
+// on their own, except the owner, who cannot leave their workspace.
+func (s *workspacesService) RemoveMember(userID uuid.UUID, workspaceID uuid.UUID, memberID uuid.UUID) (*models.Membership, error) {
+	var actor *authz.Subject
+	if userID != memberID {
+		subject, err := s.Authorize(userID, workspaceID, authz.Manage)
+		if err != nil {
+			return nil, err
+		}
+		actor = &subject
+	}
+
+	membership, err := s.Repository.FindMembership(workspaceID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	if membership.Role == models.RoleOwner {
+		return nil, apperrors.Forbidden("the workspace owner cannot be removed")
+	}
+	if actor != nil && !actor.RoleIn(workspaceID).Outranks(membership.Role) {
+		return nil, apperrors.ErrForbidden
+	}
+
+	if err := s.Repository.DeleteMembership(membership.ID); err != nil {
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+// Invite emails a single-use invitation link to the given address. The token
+// only ever leaves the server in that email.
+func (s *workspacesService) Invite(userID uuid.UUID, workspaceID uuid.UUID, dto CreateInvitationDTO) (*models.WorkspaceInvitation, error) {
+	if s.Mailer == nil {
+		return nil, apperrors.Unavailable("invitations cannot be sent", errors.New("no mailer configured"))
+	}
+
+	actor, err := s.Authorize(userID, workspaceID, authz.Manage)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.Repository.FindByID(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.IsPersonal {
+		return nil, apperrors.Validation("personal workspaces cannot be shared")
+	}
+	if !actor.RoleIn(workspaceID).Outranks(dto.Role) {
+		return nil, apperrors.ErrForbidden
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := models.WorkspaceInvitation{
+		WorkspaceID: workspaceID,
+		Email:       normalizeEmail(dto.Email),
+		Role:        dto.Role,
+		TokenHash:   hashInvitationToken(token),
+		InvitedByID: userID,
+		ExpiresAt:   time.Now().Add(invitationTTL),
+	}
+	if err := s.Repository.CreateInvitation(&invitation); err != nil {
+		return nil, err
+	}
+
+	s.sendInBackground(mail.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You have been invited to %s", workspace.Name),
+		Body: fmt.Sprintf(
+			"Hi,\n\nYou have been invited to join the %s workspace as %s. Sign in with this email address and open the link below to accept. It expires in %d days and works once.\n\n%s?token=%s\n\nIf you were not expecting this, you can ignore this email.\n",
+			workspace.Name, invitation.Role, int(invitationTTL.Hours()/24), s.InvitationURL, url.QueryEscape(token),
+		),
+	})
+
+	return &invitation, nil
+}
+
+func (s *workspacesService) ListInvitations(userID uuid.UUID, workspaceID uuid.UUID) ([]models.WorkspaceInvitation, error) {
+	if _, err := s.Authorize(userID, workspaceID, authz.Manage); err != nil {
+		return nil, err
+	}
+
+	return s.Repository.ListPendingInvitations(workspaceID)
+}
+
+func (s *workspacesService) RevokeInvitation(userID uuid.UUID, workspaceID uuid.UUID, invitationID uuid.UUID) (*models.WorkspaceInvitation, error) {
+	if _, err := s.Authorize(userID, workspaceID, authz.Manage); err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.Repository.FindInvitationByID(invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.WorkspaceID != workspaceID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if err := s.Repository.DeleteInvitation(invitation.ID); err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// AcceptInvitation adds the user to the workspace they were invited to. The
+// token alone is not enough: the user must be signed in with the invited
+// email, and must have verified it.
+func (s *workspacesService) AcceptInvitation(userID uuid.UUID, token string) (*models.Membership, error) {
+	invitation, err := s.Repository.FindInvitationByTokenHash(hashInvitationToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NotFound("invalid invitation")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if invitation.AcceptedAt != nil || now.After(invitation.ExpiresAt) {
+		return nil, apperrors.NotFound("invalid invitation")
+	}
+
+	user, err := s.Repository.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if normalizeEmail(user.Email) != invitation.Email {
+		return nil, apperrors.Forbidden("invitation was sent to a different email")
+	}
+	// Checked even when REQUIRE_VERIFIED_EMAIL is off: otherwise anyone
+	// holding the token could register the invited address and join.
+	if !user.EmailVerified() {
+		return nil, apperrors.Forbidden("verify your email address to accept this invitation")
+	}
+
+	if _, err := s.Repository.FindMembership(invitation.WorkspaceID, userID); err == nil {
+		return nil, apperrors.Conflict("already a member of this workspace")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	membership := models.Membership{WorkspaceID: invitation.WorkspaceID, UserID: userID, Role: invitation.Role}
+	invitation.AcceptedAt = &now
+	if err := s.Repository.AcceptInvitation(&invitation, &membership); err != nil {
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+// sendInBackground sends message without holding up the request; failures are
+// only logged.
+func (s *workspacesService) sendInBackground(message mail.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), invitationMailTimeout)
+		defer cancel()
+		if err := s.Mailer.Send(ctx, message); err != nil {
+			log.Printf("email %q to %s failed: %v", message.Subject, message.To, err)
+		}
+	}()
+}
+
+func newWorkspace(ownerID uuid.UUID, name string) models.Workspace {
+	return models.Workspace{
+		Name:        name,
+		OwnerID:     ownerID,
+		Memberships: []models.Membership{{UserID: ownerID, Role: models.RoleOwner}},
+	}
+}
+
+func newInvitationToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,445 @@
+package workspaces
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"cards/internal/apperrors"
+	"cards/internal/authz"
+	"cards/internal/mail"
+	"cards/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeWorkspacesRepository struct {
+	workspaces  map[uuid.UUID]models.Workspace
+	memberships map[uuid.UUID]models.Membership
+	invitations map[uuid.UUID]models.WorkspaceInvitation
+	emails      map[uuid.UUID]string
+	unverified  map[uuid.UUID]bool
+
+	deletedMembershipID uuid.UUID
+}
+
+func newFakeWorkspacesRepository() *fakeWorkspacesRepository {
+	return &fakeWorkspacesRepository{
+		workspaces:  map[uuid.UUID]models.Workspace{},
+		memberships: map[uuid.UUID]models.Membership{},
+		invitations: map[uuid.UUID]models.WorkspaceInvitation{},
+		emails:      map[uuid.UUID]string{},
+		unverified:  map[uuid.UUID]bool{},
+	}
+}
+
+func (r *fakeWorkspacesRepository) FindByID(id uuid.UUID) (models.Workspace, error) {
+	workspace, ok := r.workspaces[id]
+	if !ok {
+		return models.Workspace{}, gorm.ErrRecordNotFound
+	}
+	return workspace, nil
+}
+
+func (r *fakeWorkspacesRepository) FindPersonalByUserID(userID uuid.UUID) (models.Workspace, error) {
+	for _, workspace := range r.workspaces {
+		if workspace.IsPersonal && workspace.OwnerID == userID {
+			return workspace, nil
+		}
+	}
+	return models.Workspace{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeWorkspacesRepository) ListByUserID(userID uuid.UUID) ([]WorkspaceResponseDTO, error) {
+	var workspaces []WorkspaceResponseDTO
+	for _, membership := range r.memberships {
+		if membership.UserID == userID {
+			workspaces = append(workspaces, WorkspaceResponseDTO{Workspace: r.workspaces[membership.WorkspaceID], Role: membership.Role})
+		}
+	}
+	return workspaces, nil
+}
+
+func (r *fakeWorkspacesRepository) Create(workspace *models.Workspace) error {
+	workspace.ID = uuid.New()
+	for i := range workspace.Memberships {
+		workspace.Memberships[i].ID = uuid.New()
+		workspace.Memberships[i].WorkspaceID = workspace.ID
+		r.memberships[workspace.Memberships[i].ID] = workspace.Memberships[i]
+	}
+	r.workspaces[workspace.ID] = *workspace
+	return nil
+}
+
+func (r *fakeWorkspacesRepository) Update(workspace *models.Workspace) error {
+	r.workspaces[workspace.ID] = *workspace
+	return nil
+}
+
+func (r *fakeWorkspacesRepository) FindMembership(workspaceID uuid.UUID, userID uuid.UUID) (models.Membership, error) {
+	for _, membership := range r.memberships {
+		if membership.WorkspaceID == workspaceID && membership.UserID == userID {
+			return membership, nil
+		}
+	}
+	return models.Membership{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeWorkspacesRepository) ListMemberships(userID uuid.UUID) ([]models.Membership, error) {
+	var memberships []models.Membership
+	for _, membership := range r.memberships {
+		if membership.UserID == userID {
+			memberships = append(memberships, membership)
+		}
+	}
+	return memberships, nil
+}
+
+func (r *fakeWorkspacesRepository) ListMembers(workspaceID uuid.UUID) ([]MemberDTO, error) {
+	var members []MemberDTO
+	for _, membership := range r.memberships {
+		if membership.WorkspaceID == workspaceID {
+			members = append(members, MemberDTO{UserID: membership.UserID, Email: r.emails[membership.UserID], Role: membership.Role})
+		}
+	}
+	return members, nil
+}
+
+func (r *fakeWorkspacesRepository) UpdateMembership(membership *models.Membership) error {
+	r.memberships[membership.ID] = *membership
+	return nil
+}
+
+func (r *fakeWorkspacesRepository) DeleteMembership(id uuid.UUID) error {
+	r.deletedMembershipID = id
+	delete(r.memberships, id)
+	return nil
+}
+
+func (r *fakeWorkspacesRepository) FindUser(userID uuid.UUID) (models.User, error) {
+	email, ok := r.emails[userID]
+	if !ok {
+		return models.User{}, gorm.ErrRecordNotFound
+	}
+	user := models.User{Email: email}
+	if !r.unverified[userID] {
+		verifiedAt := time.Now()
+		user.EmailVerifiedAt = &verifiedAt
+	}
+	return user, nil
+}
+
+func (r *fakeWorkspacesRepository) CreateInvitation(invitation *models.WorkspaceInvitation) error {
+	invitation.ID = uuid.New()
+	r.invitations[invitation.ID] = *invitation
+	return nil
+}
+
+func (r *fakeWorkspacesRepository) FindInvitationByID(id uuid.UUID) (models.WorkspaceInvitation, error) {
+	invitation, ok := r.invitations[id]
+	if !ok {
+		return models.WorkspaceInvitation{}, gorm.ErrRecordNotFound
+	}
+	return invitation, nil
+}
+
+func (r *fakeWorkspacesRepository) FindInvitationByTokenHash(tokenHash string) (models.WorkspaceInvitation, error) {
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash {
+			return invitation, nil
+		}
+	}
+	return models.WorkspaceInvitation{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeWorkspacesRepository) ListPendingInvitations(workspaceID uuid.UUID) ([]models.WorkspaceInvitation, error) {
+	var invitations []models.WorkspaceInvitation
+	for _, invitation := range r.invitations {
+		if invitation.WorkspaceID == workspaceID && invitation.AcceptedAt == nil {
+			invitations = append(invitations, invitation)
+		}
+	}
+	return invitations, nil
+}
+
+func (r *fakeWorkspacesRepository) DeleteInvitation(id uuid.UUID) error {
+	delete(r.invitations, id)
+	return nil
+}
+
+func (r *fakeWorkspacesRepository) AcceptInvitation(invitation *models.WorkspaceInvitation, membership *models.Membership) error {
+	membership.ID = uuid.New()
+	r.memberships[membership.ID] = *membership
+	r.invitations[invitation.ID] = *invitation
+	return nil
+}
+
+// fakeMailer hands sent messages to the test through sent, if set.
+type fakeMailer struct {
+	sent chan mail.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, message mail.Message) error {
+	if m.sent != nil {
+		m.sent <- message
+	}
+	return nil
+}
+
+func newFakeMailer() *fakeMailer {
+	return &fakeMailer{sent: make(chan mail.Message, 10)}
+}
+
+// mailedToken waits for the invitation email to the given address and
+// returns the token from its link.
+func mailedToken(t *testing.T, mailer *fakeMailer, to string) string {
+	t.Helper()
+	select {
+	case message := <-mailer.sent:
+		if message.To != to {
+			t.Fatalf("expected email to %s, got %s", to, message.To)
+		}
+		_, link, _ := strings.Cut(message.Body, "?token=")
+		link, _, _ = strings.Cut(link, "\n")
+		token, err := url.QueryUnescape(link)
+		if err != nil || token == "" {
+			t.Fatalf("expected a link with a token in %q", message.Body)
+		}
+		return token
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected an email to %s", to)
+		return ""
+	}
+}
+
+// addMember adds userID to the workspace with the given role.
+func (r *fakeWorkspacesRepository) addMember(workspaceID uuid.UUID, userID uuid.UUID, role models.Role) models.Membership {
+	membership := models.Membership{Base: models.Base{ID: uuid.New()}, WorkspaceID: workspaceID, UserID: userID, Role: role}
+	r.memberships[membership.ID] = membership
+	return membership
+}
+
+func newTeamWorkspace(t *testing.T, repo *fakeWorkspacesRepository, ownerID uuid.UUID) uuid.UUID {
+	t.Helper()
+	workspace, err := NewWorkspacesService(repo, &fakeMailer{}).Create(ownerID, CreateWorkspaceDTO{Name: "Team"})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	return workspace.ID
+}
+
+func TestWorkspacesService_EnsurePersonal(t *testing.T) {
+	repo := newFakeWorkspacesRepository()
+	svc := NewWorkspacesService(repo, &fakeMailer{})
+	userID := uuid.New()
+
+	first, err := svc.EnsurePersonal(userID)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !first.IsPersonal || first.OwnerID != userID {
+		t.Fatalf("unexpected personal workspace: %+v", first)
+	}
+
+	second, err := svc.EnsurePersonal(userID)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if second.ID != first.ID || len(repo.workspaces) != 1 {
+		t.Fatalf("expected the personal workspace to be created once")
+	}
+
+	resolved, err := svc.Resolve(userID, "", authz.Write)
+	if err != nil || resolved != first.ID {
+		t.Fatalf("expected an empty workspace ID to resolve to the personal workspace, got %v %v", resolved, err)
+	}
+}
+
+func TestWorkspacesService_Authorize(t *testing.T) {
+	repo := newFakeWorkspacesRepository()
+	svc := NewWorkspacesService(repo, &fakeMailer{})
+	ownerID, viewerID := uuid.New(), uuid.New()
+	workspaceID := newTeamWorkspace(t, repo, ownerID)
+	repo.addMember(workspaceID, viewerID, models.RoleViewer)
+
+	if _, err := svc.Authorize(ownerID, workspaceID, authz.Manage); err != nil {
+		t.Fatalf("expected owner to be allowed admin actions, got %v", err)
+	}
+	if _, err := svc.Authorize(viewerID, workspaceID, authz.Read); err != nil {
+		t.Fatalf("expected viewer to be allowed to read, got %v", err)
+	}
+	if _, err := svc.Authorize(viewerID, workspaceID, authz.Write); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected forbidden error for viewer, got %v", err)
+	}
+	if _, err := svc.Authorize(uuid.New(), workspaceID, authz.Read); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Fatalf("expected not found error for non-member, got %v", err)
+	}
+	if _, err := svc.Resolve(viewerID, workspaceID.String(), authz.Write); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected resolve to check the role, got %v", err)
+	}
+}
+
+func TestWorkspacesService_UpdateMember(t *testing.T) {
+	repo := newFakeWorkspacesRepository()
+	svc := NewWorkspacesService(repo, &fakeMailer{})
+	ownerID, adminID, otherAdminID, memberID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	workspaceID := newTeamWorkspace(t, repo, ownerID)
+	repo.addMember(workspaceID, adminID, models.RoleAdmin)
+	repo.addMember(workspaceID, otherAdminID, models.RoleAdmin)
+	repo.addMember(workspaceID, memberID, models.RoleMember)
+
+	got, err := svc.UpdateMember(adminID, workspaceID, memberID, UpdateMemberDTO{Role: models.RoleViewer})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if got.Role != models.RoleViewer {
+		t.Fatalf("expected role viewer, got %q", got.Role)
+	}
+
+	if _, err := svc.UpdateMember(adminID, workspaceID, memberID, UpdateMemberDTO{Role: models.RoleAdmin}); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected admins not to appoint admins, got %v", err)
+	}
+	if _, err := svc.UpdateMember(adminID, workspaceID, otherAdminID, UpdateMemberDTO{Role: models.RoleMember}); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected admins not to demote other admins, got %v", err)
+	}
+	if _, err := svc.UpdateMember(memberID, workspaceID, adminID, UpdateMemberDTO{Role: models.RoleViewer}); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected members not to manage roles, got %v", err)
+	}
+
+	if _, err := svc.UpdateMember(ownerID, workspaceID, memberID, UpdateMemberDTO{Role: models.RoleAdmin}); err != nil {
+		t.Fatalf("expected owner to appoint admins, got %v", err)
+	}
+}
+
+func TestWorkspacesService_RemoveMember(t *testing.T) {
+	repo := newFakeWorkspacesRepository()
+	svc := NewWorkspacesService(repo, &fakeMailer{})
+	ownerID, adminID, memberID := uuid.New(), uuid.New(), uuid.New()
+	workspaceID := newTeamWorkspace(t, repo, ownerID)
+	admin := repo.addMember(workspaceID, adminID, models.RoleAdmin)
+	member := repo.addMember(workspaceID, memberID, models.RoleMember)
+
+	if _, err := svc.RemoveMember(memberID, workspaceID, adminID); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected members not to remove others, got %v", err)
+	}
+	if _, err := svc.RemoveMember(adminID, workspaceID, ownerID); err == nil {
+		t.Fatalf("expected the owner not to be removable")
+	}
+	if _, err := svc.RemoveMember(ownerID, workspaceID, ownerID); err == nil {
+		t.Fatalf("expected the owner not to be able to leave")
+	}
+
+	if _, err := svc.RemoveMember(adminID, workspaceID, memberID); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.deletedMembershipID != member.ID {
+		t.Fatalf("expected member to be removed")
+	}
+
+	if _, err := svc.RemoveMember(adminID, workspaceID, adminID); err != nil {
+		t.Fatalf("expected admin to leave, got %v", err)
+	}
+	if repo.deletedMembershipID != admin.ID {
+		t.Fatalf("expected admin to be removed")
+	}
+}
+
+func TestWorkspacesService_Invite(t *testing.T) {
+	repo := newFakeWorkspacesRepository()
+	mailer := newFakeMailer()
+	svc := NewWorkspacesService(repo, mailer)
+	ownerID := uuid.New()
+	workspaceID := newTeamWorkspace(t, repo, ownerID)
+
+	personal, err := svc.EnsurePersonal(ownerID)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := svc.Invite(ownerID, personal.ID, CreateInvitationDTO{Email: "a@example.com", Role: models.RoleMember}); err == nil {
+		t.Fatalf("expected personal workspaces not to be shareable")
+	}
+
+	got, err := svc.Invite(ownerID, workspaceID, CreateInvitationDTO{Email: " Someone@Example.com ", Role: models.RoleMember})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if got.Email != "someone@example.com" {
+		t.Fatalf("unexpected invitation: %+v", got)
+	}
+	token := mailedToken(t, mailer, "someone@example.com")
+	stored := repo.invitations[got.ID]
+	if stored.TokenHash == token || stored.TokenHash != hashInvitationToken(token) {
+		t.Fatalf("expected only the token hash to be stored")
+	}
+
+	body, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if strings.Contains(string(body), token) || strings.Contains(string(body), stored.TokenHash) {
+		t.Fatalf("expected the response not to carry the token, got %s", body)
+	}
+
+	if _, err := NewWorkspacesService(repo, nil).Invite(ownerID, workspaceID, CreateInvitationDTO{Email: "a@example.com", Role: models.RoleMember}); err == nil {
+		t.Fatalf("expected invitations to need a mailer")
+	}
+}
+
+func TestWorkspacesService_AcceptInvitation(t *testing.T) {
+	repo := newFakeWorkspacesRepository()
+	mailer := newFakeMailer()
+	svc := NewWorkspacesService(repo, mailer)
+	ownerID, inviteeID, strangerID := uuid.New(), uuid.New(), uuid.New()
+	repo.emails[inviteeID] = "invitee@example.com"
+	repo.emails[strangerID] = "stranger@example.com"
+	workspaceID := newTeamWorkspace(t, repo, ownerID)
+
+	if _, err := svc.Invite(ownerID, workspaceID, CreateInvitationDTO{Email: "Invitee@example.com", Role: models.RoleViewer}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	token := mailedToken(t, mailer, "invitee@example.com")
+
+	if _, err := svc.AcceptInvitation(inviteeID, "not-a-token"); err == nil {
+		t.Fatalf("expected unknown tokens to be rejected")
+	}
+	if _, err := svc.AcceptInvitation(strangerID, token); err == nil {
+		t.Fatalf("expected invitations to be bound to the invited email")
+	}
+
+	repo.unverified[inviteeID] = true
+	if _, err := svc.AcceptInvitation(inviteeID, token); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected an unverified email to be refused, got %v", err)
+	}
+	repo.unverified[inviteeID] = false
+
+	membership, err := svc.AcceptInvitation(inviteeID, token)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if membership.WorkspaceID != workspaceID || membership.Role != models.RoleViewer {
+		t.Fatalf("unexpected membership: %+v", membership)
+	}
+	if _, err := svc.AcceptInvitation(inviteeID, token); err == nil {
+		t.Fatalf("expected invitations to be single use")
+	}
+
+	expired, err := svc.Invite(ownerID, workspaceID, CreateInvitationDTO{Email: "stranger@example.com", Role: models.RoleMember})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	expiredToken := mailedToken(t, mailer, "stranger@example.com")
+	stored := repo.invitations[expired.ID]
+	stored.ExpiresAt = time.Now().Add(-time.Minute)
+	repo.invitations[expired.ID] = stored
+	if _, err := svc.AcceptInvitation(strangerID, expiredToken); err == nil {
+		t.Fatalf("expected expired invitations to be rejected")
+	}
+	if _, err := repo.FindMembership(workspaceID, strangerID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected no membership to be created, got %v", err)
+	}
+}
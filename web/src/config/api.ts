@@ -13,3 +13,55 @@ api.interceptors.request.use(function (config) {
   }
   return config;
 });
+
+type RefreshResponse = {
+  data?: {
+    token: string;
+    refresh_token: string;
+  };
+};
+
+let refreshing: Promise<string | null> | null = null;
+
+// Access tokens are short-lived. On a 401, trade the refresh token for a new
+// pair once, even if several requests fail together, and retry.
+async function refreshTokens(): Promise<string | null> {
+  const refreshToken = localStorage.getItem("refresh_token");
+  if (!refreshToken) {
+    return null;
+  }
+
+  try {
+    const { data } = await axios.post<RefreshResponse>(
+      `${apiUrl}/auth/refresh`,
+      { refresh_token: refreshToken },
+    );
+    localStorage.setItem("token", data.data?.token || "");
+    localStorage.setItem("refresh_token", data.data?.refresh_token || "");
+    return data.data?.token || null;
+  } catch {
+    localStorage.removeItem("token");
+    localStorage.removeItem("refresh_token");
+    return null;
+  }
+}
+
+api.interceptors.response.use(undefined, async function (error) {
+  const config = error.config;
+  if (error.response?.status !== 401 || !config || config._retried) {
+    return Promise.reject(error);
+  }
+
+  if (!refreshing) {
+    refreshing = refreshTokens().finally(() => {
+      refreshing = null;
+    });
+  }
+  const token = await refreshing;
+  if (!token) {
+    return Promise.reject(error);
+  }
+
+  config._retried = true;
+  return api(config);
+});
@@ -1,6 +1,7 @@
- import { useState } from 'react';
+ import { useEffect, useState } from 'react';
  import { useNavigate, Link } from 'react-router-dom';
  import { useAuthStore } from '@/stores/authStore';
+ import authService from '@/services/authService';
  import { AuthLayout } from '@/components/layout/AuthLayout';
  import { Button } from '@/components/ui/button';
  import { Input } from '@/components/ui/input';
@@ -11,9 +12,18 @@
  const Login = () => {
    const navigate = useNavigate();
    const login = useAuthStore((state) => state.login);
+   const mfaToken = useAuthStore((state) => state.mfaToken);
+   const completeMfaLogin = useAuthStore((state) => state.completeMfaLogin);
+   const cancelMfaLogin = useAuthStore((state) => state.cancelMfaLogin);
    const [email, setEmail] = useState('');
    const [password, setPassword] = useState('');
+   const [code, setCode] = useState('');
    const [isLoading, setIsLoading] = useState(false);
+   const [providers, setProviders] = useState<string[]>([]);
+ 
+   useEffect(() => {
+     authService.oauthProviders().then(setProviders);
+   }, []);
  
    const handleSubmit = async (e: React.FormEvent) => {
      e.preventDefault();
@@ -24,7 +34,7 @@
        if (success) {
          toast.success('Login realizado com sucesso!');
          navigate('/dashboard');
-       } else {
+       } else if (!useAuthStore.getState().mfaToken) {
          toast.error('Email ou senha inválidos');
        }
      } catch (error) {
@@ -34,6 +44,76 @@
      }
    };
  
+   const handleMfaSubmit = async (e: React.FormEvent) => {
+     e.preventDefault();
+     setIsLoading(true);
+ 
+     try {
+       const success = await completeMfaLogin(code);
+       if (success) {
+         toast.success('Login realizado com sucesso!');
+         navigate('/dashboard');
+       } else {
+         toast.error('Código inválido');
+       }
+     } catch (error) {
+       toast.error('Erro ao fazer login');
+     } finally {
+       setIsLoading(false);
+     }
+   };
+ 
+   if (mfaToken) {
+     return (
+       <AuthLayout
+         title="Verificação em duas etapas"
+         subtitle="Digite o código do seu app autenticador ou um código de recuperação"
+       >
+         <form onSubmit={handleMfaSubmit} className="space-y-4">
+           <div className="space-y-2">
+             <Label htmlFor="code">Código</Label>
+             <Input
+               id="code"
+               placeholder="123456"
+               value={code}
+               onChange={(e) => setCode(e.target.value)}
+               required
+               autoFocus
+               autoComplete="one-time-code"
+             />
+           </div>
+ 
+           <Button
+             type="submit"
+             className="w-full gradient-primary"
+             disabled={isLoading}
+           >
+             {isLoading ? (
+               <>
+                 <Loader2 className="mr-2 h-4 w-4 animate-spin" />
+                 Verificando...
+               </>
+             ) : (
+               'Verificar'
+             )}
+           </Button>
+ 
+           <Button
+             type="button"
+             variant="ghost"
+             className="w-full"
+             onClick={() => {
+               setCode('');
+               cancelMfaLogin();
+             }}
+           >
+             Voltar
+           </Button>
+         </form>
+       </AuthLayout>
+     );
+   }
+ 
    return (
      <AuthLayout
        title="Bem-vindo de volta"
@@ -89,6 +169,25 @@
            )}
          </Button>
  
+         {providers.length > 0 && (
+           <div className="space-y-2">
+             <p className="text-center text-sm text-muted-foreground">ou</p>
+             {providers.map((provider) => (
+               <Button
+                 key={provider}
+                 type="button"
+                 variant="outline"
+                 className="w-full capitalize"
+                 onClick={() => {
+                   window.location.href = authService.oauthStartUrl(provider);
+                 }}
+               >
+                 Entrar com {provider}
+               </Button>
+             ))}
+           </div>
+         )}
+ 
          <p className="text-center text-sm text-muted-foreground">
            Não tem uma conta?{' '}
            <Link to="/register" className="text-primary font-medium hover:underline">
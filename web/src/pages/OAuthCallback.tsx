@@ -0,0 +1,59 @@
+import { useEffect, useRef } from "react";
+import { useNavigate } from "react-router-dom";
+import { useAuthStore } from "@/stores/authStore";
+import { AuthLayout } from "@/components/layout/AuthLayout";
+import { Loader2 } from "lucide-react";
+import { toast } from "sonner";
+
+// OAuthCallback is where the API sends the browser after a provider sign-in,
+// with the result in the URL fragment.
+const OAuthCallback = () => {
+  const navigate = useNavigate();
+  const finishOAuthLogin = useAuthStore((state) => state.finishOAuthLogin);
+  const setMfaToken = useAuthStore((state) => state.setMfaToken);
+  const handled = useRef(false);
+
+  useEffect(() => {
+    if (handled.current) return;
+    handled.current = true;
+
+    const result = new URLSearchParams(window.location.hash.slice(1));
+    window.history.replaceState(null, "", window.location.pathname);
+
+    const error = result.get("error");
+    const mfaToken = result.get("mfa_token");
+    const token = result.get("token");
+    const refreshToken = result.get("refresh_token");
+
+    if (mfaToken) {
+      setMfaToken(mfaToken);
+      navigate("/login", { replace: true });
+      return;
+    }
+    if (error || !token || !refreshToken) {
+      toast.error(error || "Erro ao fazer login");
+      navigate("/login", { replace: true });
+      return;
+    }
+
+    finishOAuthLogin(token, refreshToken).then((success) => {
+      if (success) {
+        toast.success("Login realizado com sucesso!");
+        navigate("/dashboard", { replace: true });
+      } else {
+        toast.error("Erro ao fazer login");
+        navigate("/login", { replace: true });
+      }
+    });
+  }, [finishOAuthLogin, navigate, setMfaToken]);
+
+  return (
+    <AuthLayout title="Entrando" subtitle="Aguarde um instante">
+      <div className="flex justify-center">
+        <Loader2 className="h-8 w-8 animate-spin text-muted-foreground" />
+      </div>
+    </AuthLayout>
+  );
+};
+
+export default OAuthCallback;
@@ -88,6 +88,7 @@
              value={password}
              onChange={(e) => setPassword(e.target.value)}
              required
+             minLength={8}
              autoComplete="new-password"
            />
          </div>
@@ -101,6 +102,7 @@
              value={confirmPassword}
              onChange={(e) => setConfirmPassword(e.target.value)}
              required
+             minLength={8}
              autoComplete="new-password"
            />
          </div>
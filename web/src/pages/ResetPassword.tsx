@@ -0,0 +1,105 @@
+import { useState } from "react";
+import { Link, useNavigate, useSearchParams } from "react-router-dom";
+import { useAuthStore } from "@/stores/authStore";
+import { AuthLayout } from "@/components/layout/AuthLayout";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Loader2, ArrowLeft } from "lucide-react";
+import { toast } from "sonner";
+
+const ResetPassword = () => {
+  const resetPassword = useAuthStore((state) => state.resetPassword);
+  const [searchParams] = useSearchParams();
+  const navigate = useNavigate();
+  const token = searchParams.get("token") || "";
+  const [password, setPassword] = useState("");
+  const [confirmPassword, setConfirmPassword] = useState("");
+  const [isLoading, setIsLoading] = useState(false);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+
+    if (password !== confirmPassword) {
+      toast.error("As senhas não coincidem");
+      return;
+    }
+
+    setIsLoading(true);
+
+    try {
+      const success = await resetPassword(token, password);
+      if (success) {
+        toast.success("Senha redefinida com sucesso!");
+        navigate("/login");
+      } else {
+        toast.error("Link inválido ou expirado");
+      }
+    } catch (error) {
+      toast.error("Erro ao redefinir senha");
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  return (
+    <AuthLayout
+      title="Redefinir senha"
+      subtitle="Escolha uma nova senha para sua conta"
+    >
+      <form onSubmit={handleSubmit} className="space-y-4">
+        <div className="space-y-2">
+          <Label htmlFor="password">Nova senha</Label>
+          <Input
+            id="password"
+            type="password"
+            placeholder="••••••••"
+            value={password}
+            onChange={(e) => setPassword(e.target.value)}
+            required
+            minLength={8}
+            autoComplete="new-password"
+          />
+        </div>
+
+        <div className="space-y-2">
+          <Label htmlFor="confirmPassword">Confirmar senha</Label>
+          <Input
+            id="confirmPassword"
+            type="password"
+            placeholder="••••••••"
+            value={confirmPassword}
+            onChange={(e) => setConfirmPassword(e.target.value)}
+            required
+            minLength={8}
+            autoComplete="new-password"
+          />
+        </div>
+
+        <Button
+          type="submit"
+          className="w-full gradient-primary"
+          disabled={isLoading || !token}
+        >
+          {isLoading ? (
+            <>
+              <Loader2 className="mr-2 h-4 w-4 animate-spin" />
+              Salvando...
+            </>
+          ) : (
+            "Redefinir senha"
+          )}
+        </Button>
+
+        <Link to="/login" className="block">
+          <Button variant="ghost" className="w-full">
+            <ArrowLeft className="mr-2 h-4 w-4" />
+            Voltar para o login
+          </Button>
+        </Link>
+      </form>
+    </AuthLayout>
+  );
+};
+
+export default ResetPassword;
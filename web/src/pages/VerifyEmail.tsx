@@ -0,0 +1,65 @@
+import { useEffect, useRef, useState } from "react";
+import { Link, useSearchParams } from "react-router-dom";
+import authService from "@/services/authService";
+import { AuthLayout } from "@/components/layout/AuthLayout";
+import { Button } from "@/components/ui/button";
+import { Loader2, CheckCircle, XCircle } from "lucide-react";
+
+type Status = "verifying" | "verified" | "failed";
+
+const VerifyEmail = () => {
+  const [searchParams] = useSearchParams();
+  const token = searchParams.get("token") || "";
+  const [status, setStatus] = useState<Status>(token ? "verifying" : "failed");
+  const requested = useRef(false);
+
+  useEffect(() => {
+    // Tokens work once; StrictMode must not redeem it twice.
+    if (!token || requested.current) return;
+    requested.current = true;
+
+    authService
+      .verifyEmail(token)
+      .then((success) => setStatus(success ? "verified" : "failed"));
+  }, [token]);
+
+  if (status === "verifying") {
+    return (
+      <AuthLayout title="Verificando email" subtitle="Aguarde um instante">
+        <div className="flex justify-center">
+          <Loader2 className="h-8 w-8 animate-spin text-muted-foreground" />
+        </div>
+      </AuthLayout>
+    );
+  }
+
+  const verified = status === "verified";
+
+  return (
+    <AuthLayout
+      title={verified ? "Email verificado!" : "Link inválido"}
+      subtitle={
+        verified
+          ? "Sua conta está pronta para uso"
+          : "O link expirou ou já foi utilizado"
+      }
+    >
+      <div className="text-center space-y-6">
+        <div className="w-16 h-16 rounded-full bg-muted flex items-center justify-center mx-auto">
+          {verified ? (
+            <CheckCircle className="h-8 w-8 text-status-done" />
+          ) : (
+            <XCircle className="h-8 w-8 text-destructive" />
+          )}
+        </div>
+        <Link to="/dashboard">
+          <Button variant="outline" className="w-full">
+            Ir para o painel
+          </Button>
+        </Link>
+      </div>
+    </AuthLayout>
+  );
+};
+
+export default VerifyEmail;
@@ -2,22 +2,37 @@ import { ApiResponse, User } from "@/types";
 import { mockUser } from "@/lib/mockData";
 import { api } from "@/config/api";
 
+const apiUrl = import.meta.env.VITE_API_URL;
+
 export type LoginRequest = {
   email: string;
   password: string;
 };
 
 export type ApiLoginResponse = {
-  token: string;
-  user: User;
+  token?: string;
+  refresh_token?: string;
+  user?: User;
+  mfa_required?: boolean;
+  mfa_token?: string;
 };
 
 export type LoginResponse = {
   success: boolean;
   user?: User;
   error?: string;
+  // Set instead of success when the account has two-factor authentication;
+  // the login is completed with loginMfa.
+  mfaToken?: string;
 };
 
+function startSession(data?: ApiLoginResponse): LoginResponse {
+  localStorage.setItem("token", data?.token || "");
+  localStorage.setItem("refresh_token", data?.refresh_token || "");
+
+  return { success: true, user: data?.user || mockUser };
+}
+
 async function login({
   email,
   password,
@@ -31,15 +46,31 @@ async function login({
       },
     );
 
-    localStorage.setItem("token", data.data?.token || "");
+    if (data.data?.mfa_required) {
+      return { success: false, mfaToken: data.data.mfa_token };
+    }
 
-    return { success: true, user: data.data?.user || mockUser };
+    return startSession(data.data);
   } catch (error) {
     console.error("authService login error: ", error);
     return { success: false, error: "Erro ao registrar usuário" };
   }
 }
 
+async function loginMfa(mfaToken: string, code: string): Promise<LoginResponse> {
+  try {
+    const { data } = await api.post<ApiResponse<ApiLoginResponse>>(
+      "/auth/login/mfa",
+      { mfa_token: mfaToken, code },
+    );
+
+    return startSession(data.data);
+  } catch (error) {
+    console.error("authService loginMfa error: ", error);
+    return { success: false, error: "Código inválido" };
+  }
+}
+
 export type RegisterRequest = {
   name: string;
   email: string;
@@ -72,11 +103,102 @@ async function register({
 }
 
 async function logout(): Promise<void> {
+  try {
+    await api.post("/auth/logout");
+  } catch (error) {
+    console.error("authService logout error: ", error);
+  }
   localStorage.removeItem("token");
+  localStorage.removeItem("refresh_token");
+}
+
+async function forgotPassword(email: string): Promise<boolean> {
+  try {
+    await api.post("/auth/forgot_password", { email });
+    return true;
+  } catch (error) {
+    console.error("authService forgotPassword error: ", error);
+    return false;
+  }
+}
+
+async function resetPassword(token: string, password: string): Promise<boolean> {
+  try {
+    await api.post("/auth/reset_password", { token, password });
+    return true;
+  } catch (error) {
+    console.error("authService resetPassword error: ", error);
+    return false;
+  }
+}
+
+async function verifyEmail(token: string): Promise<boolean> {
+  try {
+    await api.post("/auth/verify_email", { token });
+    return true;
+  } catch (error) {
+    console.error("authService verifyEmail error: ", error);
+    return false;
+  }
+}
+
+async function resendVerification(): Promise<boolean> {
+  try {
+    await api.post("/auth/resend_verification");
+    return true;
+  } catch (error) {
+    console.error("authService resendVerification error: ", error);
+    return false;
+  }
+}
+
+async function oauthProviders(): Promise<string[]> {
+  try {
+    const { data } = await api.get<ApiResponse<{ providers: string[] }>>(
+      "/auth/oauth/providers",
+    );
+    return data.data?.providers || [];
+  } catch (error) {
+    console.error("authService oauthProviders error: ", error);
+    return [];
+  }
+}
+
+// The browser goes to the API itself, which sends it on to the provider.
+function oauthStartUrl(provider: string): string {
+  return `${apiUrl}/auth/oauth/${provider}/start`;
+}
+
+// finishOAuthLogin stores the tokens the API handed over after a provider
+// sign-in and loads the user they belong to.
+async function finishOAuthLogin(
+  token: string,
+  refreshToken: string,
+): Promise<LoginResponse> {
+  localStorage.setItem("token", token);
+  localStorage.setItem("refresh_token", refreshToken);
+
+  try {
+    const { data } = await api.get<ApiResponse<User>>("/auth/me");
+    return { success: true, user: data.data || mockUser };
+  } catch (error) {
+    console.error("authService finishOAuthLogin error: ", error);
+    localStorage.removeItem("token");
+    localStorage.removeItem("refresh_token");
+    return { success: false, error: "Erro ao fazer login" };
+  }
 }
 
 export default {
   login,
+  loginMfa,
   register,
   logout,
+  forgotPassword,
+  resetPassword,
+  verifyEmail,
+  resendVerification,
+  oauthProviders,
+  oauthStartUrl,
+  finishOAuthLogin,
 };
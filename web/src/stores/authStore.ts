@@ -6,15 +6,24 @@ interface AuthState {
   user: User | null;
   isAuthenticated: boolean;
   isLoading: boolean;
+  // Challenge from a login that still needs a two-factor code.
+  mfaToken: string | null;
   login: (email: string, password: string) => Promise<boolean>;
+  completeMfaLogin: (code: string) => Promise<boolean>;
+  cancelMfaLogin: () => void;
+  finishOAuthLogin: (token: string, refreshToken: string) => Promise<boolean>;
+  setMfaToken: (mfaToken: string) => void;
   register: (name: string, email: string, password: string) => Promise<boolean>;
   logout: () => void;
+  forgotPassword: (email: string) => Promise<boolean>;
+  resetPassword: (token: string, password: string) => Promise<boolean>;
 }
 
 export const useAuthStore = create<AuthState>((set, get) => ({
   user: null,
   isAuthenticated: false,
   isLoading: false,
+  mfaToken: null,
 
   login: async (email: string, password: string): Promise<boolean> => {
     set({ isLoading: true });
@@ -26,10 +35,49 @@ export const useAuthStore = create<AuthState>((set, get) => ({
       return true;
     }
 
+    set({ isLoading: false, mfaToken: response.mfaToken || null });
+    return false;
+  },
+
+  completeMfaLogin: async (code: string): Promise<boolean> => {
+    const mfaToken = get().mfaToken;
+    if (!mfaToken) return false;
+
+    set({ isLoading: true });
+
+    const response = await authService.loginMfa(mfaToken, code);
+
+    if (response.success && response.user) {
+      set({
+        user: response.user,
+        isAuthenticated: true,
+        isLoading: false,
+        mfaToken: null,
+      });
+      return true;
+    }
+
     set({ isLoading: false });
     return false;
   },
 
+  cancelMfaLogin: () => set({ mfaToken: null }),
+
+  finishOAuthLogin: async (
+    token: string,
+    refreshToken: string,
+  ): Promise<boolean> => {
+    const response = await authService.finishOAuthLogin(token, refreshToken);
+
+    if (response.success && response.user) {
+      set({ user: response.user, isAuthenticated: true });
+      return true;
+    }
+    return false;
+  },
+
+  setMfaToken: (mfaToken: string) => set({ mfaToken }),
+
   register: async (
     name: string,
     email: string,
@@ -53,6 +101,12 @@ export const useAuthStore = create<AuthState>((set, get) => ({
     authService.logout();
     set({ user: null, isAuthenticated: false });
   },
+
+  forgotPassword: (email: string): Promise<boolean> =>
+    authService.forgotPassword(email),
+
+  resetPassword: (token: string, password: string): Promise<boolean> =>
+    authService.resetPassword(token, password),
 }));
 
 // Alias para manter compatibilidade
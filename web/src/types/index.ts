@@ -16,6 +16,8 @@ export type User = {
   id: string;
   name: string;
   email: string;
+  email_verified?: boolean;
+  role?: "user" | "admin";
   created_at: Date;
 };
 